@@ -4,6 +4,7 @@ import (
 	"context"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"mosprom/api/config"
@@ -14,8 +15,11 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 
 	"mosprom/api/internal/db"
+	"mosprom/api/internal/events"
 	"mosprom/api/internal/handler"
+	"mosprom/api/internal/jobs"
 	"mosprom/api/internal/middleware"
+	"mosprom/api/internal/model"
 	"mosprom/api/internal/service"
 	"mosprom/api/internal/websockets"
 )
@@ -43,9 +47,62 @@ func main() {
 	db.Init(cfg)
 
 	// Initialize services
-	postService := service.NewPostService()
+	reminderService := service.NewReminderService(cfg.ReminderOffsets)
+	postService := service.NewPostService(reminderService)
 	postHandler := handler.NewPostHandler(postService)
 
+	// Fire post reminders in the background; safe to run on several instances
+	go func() {
+		if err := reminderService.Run(ctx, cfg.ReminderPollInterval); err != nil {
+			log.Printf("Reminder scheduler stopped: %v", err)
+		}
+	}()
+
+	// Background job queue; workers on every instance share the jobs table
+	userService := service.NewUserService()
+	jobRunner := jobs.NewRunner(cfg.JobPollInterval, cfg.JobRetention)
+	webhookService := service.NewWebhookService()
+	jobs.Handle(jobRunner, service.RecomputeRatingsJob, func(ctx context.Context, _ struct{}) error {
+		return userService.RecomputeAllRatings()
+	})
+	jobRunner.Register(service.DeliverWebhookJob, webhookService.DeliverJob)
+	jobs.Handle(jobRunner, service.RunCampaignsJob, service.NewCampaignService().RunDue)
+	jobs.Handle(jobRunner, service.ExtendSeriesJob, postService.ExtendSeries)
+	if err := jobRunner.Schedule("nightly_rating_recompute", cfg.RatingRecomputeCron, service.RecomputeRatingsJob, struct{}{}); err != nil {
+		log.Fatal("failed to schedule rating recompute: ", err)
+	}
+	if err := jobRunner.Schedule("campaign_tick", service.CampaignTickSpec, service.RunCampaignsJob, struct{}{}); err != nil {
+		log.Fatal("failed to schedule campaign tick: ", err)
+	}
+	if err := jobRunner.Schedule("series_extend", service.ExtendSeriesSpec, service.ExtendSeriesJob, struct{}{}); err != nil {
+		log.Fatal("failed to schedule series extension: ", err)
+	}
+	go func() {
+		if err := jobRunner.Run(ctx); err != nil {
+			log.Printf("Job runner stopped: %v", err)
+		}
+	}()
+
+	// Domain events: the outbox is written together with each change and dispatched here
+	eventBus := events.NewBus()
+	notificationService := service.NewNotificationService()
+	eventBus.Subscribe(notificationService.OnUserJoinedPost, model.EventUserJoinedPost)
+	eventBus.Subscribe(notificationService.OnClubMembershipChanged,
+		model.EventClubMemberKicked, model.EventClubMemberBanned, model.EventClubJoinRejected)
+	eventBus.Subscribe(notificationService.OnInvitationCreated, model.EventInvitationCreated)
+	eventBus.Subscribe(notificationService.OnWaitlistPromoted, model.EventWaitlistPromoted)
+	eventBus.Subscribe(notificationService.OnRegistrationCreated, model.EventRegistrationCreated)
+	eventBus.Subscribe(notificationService.OnRegistrationDecided, model.EventRegistrationApproved, model.EventRegistrationRejected)
+	eventBus.Subscribe(notificationService.OnOrganizationReviewed, model.EventOrganizationVerified, model.EventOrganizationRejected)
+	eventBus.Subscribe(userService.OnUserCheckedIn, model.EventUserCheckedIn)
+	eventBus.SubscribeAll(webhookService.OnEvent)
+	eventBus.Subscribe(websockets.OnMemberBanned, model.EventClubMemberBanned)
+	go func() {
+		if err := eventBus.Run(ctx, cfg.OutboxPollInterval); err != nil {
+			log.Printf("Event dispatcher stopped: %v", err)
+		}
+	}()
+
 	r := gin.Default()
 
 	// serve uploaded files
@@ -60,6 +117,7 @@ func main() {
 		c.Header("Access-Control-Allow-Origin", origin)
 		c.Header("Access-Control-Allow-Methods", "GET,POST,PUT,PATCH,DELETE,OPTIONS")
 		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, Accept")
+		c.Header("Access-Control-Expose-Headers", "X-Total-Count, X-Next-Cursor")
 		c.Header("Access-Control-Allow-Credentials", "true")
 		if c.Request.Method == http.MethodOptions {
 			c.AbortWithStatus(204)
@@ -112,19 +170,34 @@ func main() {
 	r.GET("/clubs/:name/chat", handler.GetClubChatID)
 	// Subscribers of a club (avoid conflict with /clubs/:name)
 	r.GET("/clubs/id/:id/subscribers", handler.GetClubSubscribers)
+	// Club page constructor: published layout and block schemas
+	r.GET("/clubs/id/:id/page", handler.GetClubPage)
+	r.GET("/clubs/id/:id/calendar.ics", handler.GetClubCalendar)
+	r.GET("/clubs/page/schema", handler.GetPageBlockSchemas)
+
+	// Organizations
+	r.GET("/organizations", handler.ListOrganizations)
+	r.GET("/organizations/:id", handler.GetOrganization)
 
 	// Directions
 	r.GET("/directions", handler.ListDirections)
 	r.GET("/directions/:id/technologies", handler.GetTechnologiesByDirection)
 
 	// Posts
-	r.GET("/posts", postHandler.GetAllPosts)
-	r.GET("/posts/:id", postHandler.GetPostByID)
+	r.GET("/posts", middleware.OptionalJWTAuth(), postHandler.GetAllPosts)
+	r.GET("/posts/:id", middleware.OptionalJWTAuth(), postHandler.GetPostByID)
 	r.GET("/posts/:id/recommended_users", postHandler.RecommendedUsersForPost)
-	r.GET("/posts/club", postHandler.GetPostsByClubID)
-	r.POST("/posts/join", postHandler.Join)
+	r.GET("/posts/club", middleware.OptionalJWTAuth(), postHandler.GetPostsByClubID)
+	r.POST("/posts/join", middleware.JWTAuth(), postHandler.Join)
 	r.POST("/posts", postHandler.CreatePost)
 	r.GET("/posts/:id/participants", postHandler.GetPostParticipants)
+	r.GET("/posts/:id/event.ics", handler.GetPostCalendar)
+	r.GET("/posts/:id/occurrences", middleware.OptionalJWTAuth(), postHandler.GetPostOccurrences)
+	// Personal calendar feed, authorized by the secret token in the URL
+	r.GET("/me/calendar.ics", handler.GetMyCalendarFeed)
+
+	// Platform-wide analytics
+	r.GET("/analytics/skills-gap", handler.GetSkillsGap)
 
 	// Secured post routes
 	postAuth := r.Group("/posts")
@@ -133,21 +206,88 @@ func main() {
 		postAuth.PUT("/:id", postHandler.UpdatePost)
 		postAuth.DELETE("/:id", postHandler.DeletePost)
 
-		// join remains public above to avoid auth requirement
+		// join remains public above to avoid auth requirement; leaving acts on the current user
+		postAuth.DELETE("/:id/join", postHandler.Leave)
+		// Recurring posts: cancel the whole series
+		postAuth.DELETE("/:id/series", postHandler.CancelPostSeries)
+		postAuth.GET("/:id/waitlist", handler.GetPostWaitlist)
+		// Organizer-approved registrations
+		postAuth.PUT("/:id/registration-questions", handler.SetRegistrationQuestions)
+		postAuth.GET("/:id/registrations", handler.ListPostRegistrations)
+		postAuth.POST("/:id/registrations/:registration_id/approve", handler.ApprovePostRegistration)
+		postAuth.POST("/:id/registrations/:registration_id/reject", handler.RejectPostRegistration)
+		// Check-in and verified attendance
+		postAuth.GET("/:id/check-in/code", handler.GetCheckInCode)
+		postAuth.GET("/:id/check-in/qr.png", handler.GetCheckInQR)
+		postAuth.POST("/:id/check-in", postHandler.CheckIn)
+		postAuth.POST("/:id/check-in/:user_id", handler.ManualCheckIn)
+		postAuth.GET("/:id/attendance", handler.GetPostAttendance)
 		// Post technologies (secured: both GET and POST require JWT)
 		postAuth.GET("/:id/technologies", postHandler.GetTechnologies)
 		postAuth.POST("/:id/technologies", postHandler.SetTechnologies)
 		// Post likes
 		postAuth.POST("/:id/like", postHandler.LikePost)
 		postAuth.POST("/:id/unlike", postHandler.UnlikePost)
+		// Co-host clubs
+		postAuth.GET("/:id/co-hosts", handler.ListPostCoHosts)
+		postAuth.POST("/:id/co-hosts", handler.InvitePostCoHost)
+		postAuth.DELETE("/:id/co-hosts/:club_id", handler.RemovePostCoHost)
 	}
 
 	clubAuth := r.Group("/clubs")
 	clubAuth.Use(middleware.JWTAuth())
 	{
 		clubAuth.POST("", handler.CreateClub)
+		clubAuth.PATCH(":id", handler.UpdateClub)
+		clubAuth.DELETE(":id", handler.DeleteClub)
 		clubAuth.POST(":id/logo", handler.SetClubLogo)
+		clubAuth.POST(":id/archive", handler.ArchiveClub)
+		clubAuth.POST(":id/unarchive", handler.UnarchiveClub)
 		clubAuth.POST("id/:id/subscribe", handler.SubscribeToClub)
+		clubAuth.DELETE("id/:id/subscribe", handler.UnsubscribeFromClub)
+		// Membership moderation
+		clubAuth.POST("id/:id/members/:user_id/kick", handler.KickClubMember)
+		clubAuth.GET("id/:id/bans", handler.ListClubBans)
+		clubAuth.POST("id/:id/bans", handler.BanClubMember)
+		clubAuth.DELETE("id/:id/bans/:user_id", handler.UnbanClubMember)
+		clubAuth.GET("id/:id/join-requests", handler.ListClubJoinRequests)
+		clubAuth.POST("id/:id/join-requests/:request_id/approve", handler.ApproveClubJoinRequest)
+		clubAuth.POST("id/:id/join-requests/:request_id/reject", handler.RejectClubJoinRequest)
+		// Invite links
+		clubAuth.GET("id/:id/invites", handler.ListClubInvites)
+		clubAuth.POST("id/:id/invites", handler.CreateClubInvite)
+		clubAuth.DELETE("id/:id/invites/:invite_id", handler.RevokeClubInvite)
+		clubAuth.POST("invites/:token/redeem", handler.RedeemClubInvite)
+		// Personal invitations to the club or its posts
+		clubAuth.GET("id/:id/invitations", handler.ListClubInvitations)
+		clubAuth.POST("id/:id/invitations", handler.InviteUsersToClub)
+		clubAuth.GET("id/:id/analytics", handler.GetClubAnalytics)
+		// Owning organization
+		clubAuth.PUT("id/:id/organization", handler.AttachClubToOrganization)
+		clubAuth.DELETE("id/:id/organization", handler.DetachClubFromOrganization)
+		// Posts the club is invited to co-host
+		clubAuth.GET("id/:id/co-hosting", handler.ListClubCoHostings)
+		clubAuth.POST("id/:id/co-hosting/:post_id/accept", handler.AcceptCoHosting)
+		clubAuth.POST("id/:id/co-hosting/:post_id/decline", handler.DeclineCoHosting)
+		// Page layout drafts and publishing
+		clubAuth.GET("id/:id/page/versions", handler.ListClubPageVersions)
+		clubAuth.POST("id/:id/page/versions", handler.SaveClubPageDraft)
+		clubAuth.GET("id/:id/page/versions/:version", handler.GetClubPageVersion)
+		clubAuth.POST("id/:id/page/versions/:version/publish", handler.PublishClubPageVersion)
+		// Automated invitation campaigns
+		clubAuth.GET("id/:id/campaigns", handler.ListCampaigns)
+		clubAuth.POST("id/:id/campaigns", handler.CreateCampaign)
+		clubAuth.GET("id/:id/campaigns/:campaign_id", handler.GetCampaign)
+		clubAuth.POST("id/:id/campaigns/:campaign_id/pause", handler.PauseCampaign)
+		clubAuth.POST("id/:id/campaigns/:campaign_id/resume", handler.ResumeCampaign)
+		// Bulk import of posts from calendar exports and spreadsheets
+		clubAuth.POST("id/:id/posts/import", postHandler.ImportClubPosts)
+		// Outbound webhooks for club integrations
+		clubAuth.GET("id/:id/webhooks", handler.ListClubWebhooks)
+		clubAuth.POST("id/:id/webhooks", handler.CreateClubWebhook)
+		clubAuth.DELETE("id/:id/webhooks/:webhook_id", handler.DeleteClubWebhook)
+		clubAuth.GET("id/:id/webhooks/:webhook_id/deliveries", handler.ListWebhookDeliveries)
+		clubAuth.POST("id/:id/webhooks/:webhook_id/deliveries/:delivery_id/replay", handler.ReplayWebhookDelivery)
 	}
 
 	// Secured profile routes
@@ -160,20 +300,56 @@ func main() {
 		auth.POST("/me/photo", handler.SetMyPhoto)
 		// Current user's subscribed clubs
 		auth.GET("/me/clubs", handler.GetUserClubs)
+		auth.GET("/me/clubs/recommended", handler.GetRecommendedClubs)
 		// Current user's joined posts
 		auth.GET("/me/posts", postHandler.JoinedByMe)
 		auth.GET("/me/posts/recommended", postHandler.RecommendedPostsForMe)
+		auth.GET("/me/registrations", postHandler.MyRegistrations)
+		// Calendar feed URL of joined posts
+		auth.GET("/me/calendar", handler.GetMyCalendar)
+		auth.POST("/me/calendar/reset", handler.ResetMyCalendar)
+		// Personalized activity feed
+		auth.GET("/me/feed", postHandler.Feed)
 		// Current user's achievements
 		auth.GET("/me/achievements", handler.GetMyAchievements)
+		// Current user's notifications
+		auth.GET("/me/notifications", handler.GetMyNotifications)
+		auth.POST("/me/notifications/:id/read", handler.MarkNotificationRead)
+		// Invitations from club admins
+		auth.GET("/me/invitations", handler.GetMyInvitations)
+		auth.POST("/me/invitations/:id/accept", handler.AcceptInvitation)
+		auth.POST("/me/invitations/:id/decline", handler.DeclineInvitation)
+		// Organizations owning clubs
+		auth.GET("/me/organizations", handler.GetMyOrganizations)
+		auth.POST("/organizations", handler.CreateOrganization)
+		auth.PUT("/organizations/:id", handler.UpdateOrganization)
+		auth.GET("/organizations/:id/staff", handler.ListOrganizationStaff)
+		auth.POST("/organizations/:id/staff", handler.SetOrganizationStaff)
+		auth.DELETE("/organizations/:id/staff/:user_id", handler.RemoveOrganizationStaff)
+	}
+
+	// Admin: background jobs and moderation
+	admin := r.Group("/admin")
+	admin.Use(middleware.JWTAuth(), middleware.RequireRole(model.RoleAdmin))
+	{
+		admin.GET("/jobs", handler.ListJobs)
+		admin.GET("/jobs/recurring", handler.ListRecurringJobs)
+		admin.GET("/jobs/:id", handler.GetJob)
+		admin.POST("/jobs/:id/requeue", handler.RequeueJob)
+		// Organization verification
+		admin.GET("/organizations", handler.AdminListOrganizations)
+		admin.POST("/organizations/:id/verify", handler.VerifyOrganization)
+		admin.POST("/organizations/:id/reject", handler.RejectOrganization)
+		admin.POST("/organizations/:id/release", handler.ReleaseOrganization)
 	}
 
 	// Get clubs of a specific user by id
 	r.GET("/users/:id/clubs", handler.GetSubscriberClubs)
 	// Posts joined by a user
-	r.GET("/users/:id/posts", postHandler.JoinedByUser)
+	r.GET("/users/:id/posts", middleware.OptionalJWTAuth(), postHandler.JoinedByUser)
 
-	r.GET("/ws", func(c *gin.Context) {
-		websockets.ServeWs(c.Writer, c.Request)
+	r.GET("/ws", middleware.WebsocketAuth(), func(c *gin.Context) {
+		websockets.ServeWs(c.Writer, c.Request, strconv.FormatUint(uint64(c.GetUint("user_id")), 10))
 	})
 
 	log.Println("start at :8080")
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -15,6 +17,17 @@ type Config struct {
 	DBPassword string
 	DBName     string
 	SSLMode    string
+
+	// ReminderOffsets — за сколько до начала поста напоминать участникам
+	ReminderOffsets      []time.Duration
+	ReminderPollInterval time.Duration
+
+	JobPollInterval    time.Duration
+	OutboxPollInterval time.Duration
+	// JobRetention — сколько хранить успешно выполненные задачи
+	JobRetention time.Duration
+	// RatingRecomputeCron — cron-расписание ночного пересчёта рейтингов
+	RatingRecomputeCron string
 }
 
 func LoadConfig() *Config {
@@ -28,6 +41,14 @@ func LoadConfig() *Config {
 		DBUser:     os.Getenv("DB_USER"),
 		DBPassword: os.Getenv("DB_PASSWORD"),
 		DBName:     os.Getenv("DB_NAME"),
+
+		ReminderOffsets:      getDurations("REMINDER_OFFSETS", []time.Duration{24 * time.Hour, time.Hour}),
+		ReminderPollInterval: getDuration("REMINDER_POLL_INTERVAL", time.Minute),
+
+		JobPollInterval:     getDuration("JOB_POLL_INTERVAL", 5*time.Second),
+		JobRetention:        getDuration("JOB_RETENTION", 7*24*time.Hour),
+		OutboxPollInterval:  getDuration("OUTBOX_POLL_INTERVAL", time.Second),
+		RatingRecomputeCron: getString("RATING_RECOMPUTE_CRON", "0 3 * * *"),
 	}
 
 	return cfg
@@ -39,3 +60,46 @@ func (c *Config) GetDSN() string {
 		c.DBHost, c.DBUser, c.DBPassword, c.DBName, c.DBPort,
 	)
 }
+
+func getString(key, def string) string {
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		return v
+	}
+	return def
+}
+
+// getDuration parses an env var like "30s" or "5m", falling back to def when unset or invalid
+func getDuration(key string, def time.Duration) time.Duration {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Printf("invalid %s=%q, using %s", key, v, def)
+		return def
+	}
+	return d
+}
+
+// getDurations parses a comma-separated env var like "24h,1h", falling back to def when unset or invalid
+func getDurations(key string, def []time.Duration) []time.Duration {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return def
+	}
+	var out []time.Duration
+	for _, part := range strings.Split(v, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		d, err := time.ParseDuration(part)
+		if err != nil || d <= 0 {
+			log.Printf("invalid %s=%q, using defaults", key, v)
+			return def
+		}
+		out = append(out, d)
+	}
+	return out
+}
@@ -20,6 +20,13 @@ func Init(cfg *config.Config) {
 
 	log.Println("Database connected")
 
+	if err := Migrate(); err != nil {
+		log.Fatal("failed to migrate database: ", err)
+	}
+}
+
+// Migrate brings the schema of DB up to date
+func Migrate() error {
 	// MIGRATION: порядок важен из-за внешних ключей
 	// 1) Club (родитель)
 	// 2) Direction (имеет FK на Club)
@@ -30,10 +37,35 @@ func Init(cfg *config.Config) {
 		&model.Direction{},
 		&model.Technology{},
 		&model.User{},
+		&model.PostSeries{},
 		&model.Post{},
 		&model.Like{},
+		&model.Notification{},
+		&model.PostReminder{},
+		&model.Job{},
+		&model.RecurringJob{},
+		&model.OutboxEvent{},
+		&model.Webhook{},
+		&model.WebhookDelivery{},
+		&model.ClubJoinRequest{},
+		&model.ClubBan{},
+		&model.ClubInvite{},
+		&model.Invitation{},
+		&model.Campaign{},
+		&model.PostView{},
+		&model.ClubPageVersion{},
+		&model.Organization{},
+		&model.OrganizationStaff{},
+		&model.PostCoHost{},
+		&model.PostWaitlistEntry{},
+		&model.PostRegistrationQuestion{},
+		&model.PostRegistration{},
+		&model.PostAttendance{},
+		&model.CalendarToken{},
+		&model.CancelledPost{},
+		&model.PostSeriesMember{},
 	); err != nil {
-		log.Fatal("failed to migrate database: ", err)
+		return err
 	}
 
 	// Backfill: if the old one-to-many column technologies.direction_id exists, populate the new join table
@@ -49,9 +81,76 @@ func Init(cfg *config.Config) {
 		`).Error
 	}
 
+	// Per-club member role, used to grant club admin rights
+	_ = DB.Exec("ALTER TABLE club_subscribers ADD COLUMN IF NOT EXISTS role varchar(20) NOT NULL DEFAULT 'member'").Error
+
+	// Join timestamps for analytics; rows that existed before get the migration time
+	_ = DB.Exec("ALTER TABLE club_subscribers ADD COLUMN IF NOT EXISTS created_at timestamptz NOT NULL DEFAULT now()").Error
+	_ = DB.Exec("ALTER TABLE post_participants ADD COLUMN IF NOT EXISTS created_at timestamptz NOT NULL DEFAULT now()").Error
+	_ = DB.Exec("CREATE INDEX IF NOT EXISTS ix_club_subscribers_club_created ON club_subscribers (club_id, created_at)").Error
+	// When a user added a technology, for skills supply trends
+	_ = DB.Exec("ALTER TABLE user_technologies ADD COLUMN IF NOT EXISTS created_at timestamptz NOT NULL DEFAULT now()").Error
+
+	// At most one pending join request per user and club
+	_ = DB.Exec("CREATE UNIQUE INDEX IF NOT EXISTS ux_club_join_requests_pending ON club_join_requests (club_id, user_id) WHERE status = 'pending'").Error
+
+	// At most one pending registration per user and post
+	_ = DB.Exec("CREATE UNIQUE INDEX IF NOT EXISTS ux_post_registrations_pending ON post_registrations (post_id, user_id) WHERE status = 'pending'").Error
+
+	// A user is invited at most once to the same post, and at most once to the same club
+	_ = DB.Exec("CREATE UNIQUE INDEX IF NOT EXISTS ux_invitations_post ON invitations (post_id, user_id) WHERE post_id IS NOT NULL").Error
+	_ = DB.Exec("CREATE UNIQUE INDEX IF NOT EXISTS ux_invitations_club ON invitations (club_id, user_id) WHERE post_id IS NULL").Error
+
+	// Only one published page layout per club
+	_ = DB.Exec("CREATE UNIQUE INDEX IF NOT EXISTS ux_club_page_published ON club_page_versions (club_id) WHERE status = 'published'").Error
+
+	// An INN is held by one organization at a time; a rejected or released organization frees it
+	_ = DB.Exec("DROP INDEX IF EXISTS idx_organizations_inn").Error
+	_ = DB.Exec("CREATE UNIQUE INDEX IF NOT EXISTS ux_organizations_inn_active ON organizations (inn) WHERE status <> 'rejected'").Error
+
+	// One counted view per viewer, post and day
+	_ = DB.Exec("CREATE UNIQUE INDEX IF NOT EXISTS ux_post_views_daily ON post_views (post_id, viewer, view_date) WHERE viewer <> ''").Error
+
 	// Ensure unique index on post participants join table
 	_ = DB.Exec("CREATE UNIQUE INDEX IF NOT EXISTS ux_post_participants ON post_participants (post_id, user_id)").Error
 
+	// Partial index for the outbox dispatcher: only undelivered events are scanned
+	_ = DB.Exec("CREATE INDEX IF NOT EXISTS ix_outbox_events_pending ON outbox_events (next_attempt_at, id) WHERE dispatched_at IS NULL AND failed_at IS NULL").Error
+
+	// Data migrations that must run exactly once are recorded here by name
+	_ = DB.Exec("CREATE TABLE IF NOT EXISTS schema_migrations (name varchar(100) PRIMARY KEY, applied_at timestamptz NOT NULL DEFAULT now())").Error
+
+	// events_count counts attended posts, no longer joined ones
+	if err := runOnce("events_count_from_attendance",
+		"UPDATE users SET events_count = (SELECT COUNT(*) FROM post_attendances a WHERE a.user_id = users.id)"); err != nil {
+		return err
+	}
+
+	// Receiver response bodies are no longer kept: they could relay internal services to club managers
+	_ = DB.Exec("ALTER TABLE webhook_deliveries DROP COLUMN IF EXISTS response_body").Error
+
+	// One original delivery per webhook and event; replays are extra rows
+	_ = DB.Exec("CREATE UNIQUE INDEX IF NOT EXISTS ux_webhook_deliveries_event ON webhook_deliveries (webhook_id, event_id) WHERE replay_of_id IS NULL").Error
+
 	// Ensure unique index on post technologies join table
 	_ = DB.Exec("CREATE UNIQUE INDEX IF NOT EXISTS ux_post_technologies ON post_technologies (post_id, technology_id)").Error
+
+	// Re-importing the same calendar or spreadsheet must not duplicate posts
+	_ = DB.Exec("CREATE UNIQUE INDEX IF NOT EXISTS ux_posts_import_key ON posts (club_id, import_key) WHERE import_key IS NOT NULL").Error
+
+	// Keyset pagination of post listings
+	_ = DB.Exec("CREATE INDEX IF NOT EXISTS ix_posts_created ON posts (created_at DESC, id DESC)").Error
+	_ = DB.Exec("CREATE INDEX IF NOT EXISTS ix_posts_start ON posts (start_date, id)").Error
+	return nil
+}
+
+// runOnce executes a data migration unless schema_migrations already records it under name
+func runOnce(name, sql string) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Exec("INSERT INTO schema_migrations (name) VALUES (?) ON CONFLICT DO NOTHING", name)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		return tx.Exec(sql).Error
+	})
 }
@@ -0,0 +1,58 @@
+// Package dbtest connects tests to the Postgres database named by TEST_DATABASE_DSN.
+// Tests using it are skipped when the variable is not set.
+package dbtest
+
+import (
+	"mosprom/api/internal/db"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var (
+	once    sync.Once
+	conn    *gorm.DB
+	initErr error
+)
+
+// Open points db.DB at the test database with a migrated schema and every table emptied.
+// The database is wiped, so never point TEST_DATABASE_DSN at one holding real data.
+func Open(tb testing.TB) *gorm.DB {
+	tb.Helper()
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		tb.Skip("TEST_DATABASE_DSN is not set")
+	}
+	once.Do(func() {
+		conn, initErr = gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+		if initErr != nil {
+			return
+		}
+		db.DB = conn
+		initErr = db.Migrate()
+	})
+	if initErr != nil {
+		tb.Fatalf("test database: %v", initErr)
+	}
+	db.DB = conn
+	if err := truncate(conn); err != nil {
+		tb.Fatalf("truncate test database: %v", err)
+	}
+	return conn
+}
+
+func truncate(conn *gorm.DB) error {
+	var tables []string
+	if err := conn.Raw("SELECT quote_ident(tablename) FROM pg_tables WHERE schemaname = current_schema()").Scan(&tables).Error; err != nil {
+		return err
+	}
+	if len(tables) == 0 {
+		return nil
+	}
+	return conn.Exec("TRUNCATE " + strings.Join(tables, ", ") + " RESTART IDENTITY CASCADE").Error
+}
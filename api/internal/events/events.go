@@ -0,0 +1,145 @@
+// Package events delivers domain events from the transactional outbox to in-process subscribers.
+// Delivery is at least once: subscribers must tolerate seeing the same event again.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mosprom/api/internal/jobs"
+	"mosprom/api/internal/model"
+	"mosprom/api/internal/repository"
+	"reflect"
+	"runtime"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// dispatchBatchSize limits how many events a single poll locks
+	dispatchBatchSize = 100
+
+	// maxAttempts after which an event is parked with failed_at set
+	maxAttempts = 10
+)
+
+// Event is a dispatched outbox event with its decoded payload
+type Event struct {
+	ID         uint
+	Type       model.EventType
+	OccurredAt time.Time
+	model.EventPayload
+}
+
+// Subscriber handles one event. Returning an error redelivers the event later to this subscriber only;
+// gorm.ErrRecordNotFound means the event refers to a deleted record and is not retried.
+type Subscriber func(ctx context.Context, e Event) error
+
+// subscription is a subscriber with the name its deliveries are recorded under
+type subscription struct {
+	name string
+	fn   Subscriber
+}
+
+// Bus routes outbox events to subscribers by type
+type Bus struct {
+	subs map[model.EventType][]subscription
+	all  []subscription
+}
+
+func NewBus() *Bus {
+	return &Bus{subs: make(map[model.EventType][]subscription)}
+}
+
+// Subscribe registers fn for the given event types
+func (b *Bus) Subscribe(fn Subscriber, types ...model.EventType) {
+	for _, t := range types {
+		b.subs[t] = append(b.subs[t], subscription{name: subscriberName(fn), fn: fn})
+	}
+}
+
+// SubscribeAll registers fn for every event type
+func (b *Bus) SubscribeAll(fn Subscriber) {
+	b.all = append(b.all, subscription{name: subscriberName(fn), fn: fn})
+}
+
+// subscriberName identifies fn across restarts by its function name, e.g.
+// "mosprom/api/internal/service.(*NotificationService).OnUserJoinedPost-fm"
+func subscriberName(fn Subscriber) string {
+	return runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+}
+
+// Run dispatches pending outbox events every interval until ctx is cancelled
+func (b *Bus) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for ctx.Err() == nil {
+			n, err := repository.DispatchOutboxEvents(time.Now(), dispatchBatchSize, maxAttempts,
+				func(e model.OutboxEvent) ([]string, error) { return b.deliver(ctx, e) },
+				func(attempts int) time.Time { return time.Now().Add(jobs.Backoff(attempts)) })
+			if err != nil {
+				log.Printf("events: dispatch: %v", err)
+				break
+			}
+			if n < dispatchBatchSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliver passes the event to the subscribers that have not handled it yet and returns the names of
+// all that have, including those of earlier attempts
+func (b *Bus) deliver(ctx context.Context, oe model.OutboxEvent) ([]string, error) {
+	delivered := append([]string(nil), oe.DeliveredTo...)
+	e := Event{ID: oe.ID, Type: oe.Type, OccurredAt: oe.CreatedAt}
+	if len(oe.Payload) > 0 {
+		if err := json.Unmarshal([]byte(oe.Payload), &e.EventPayload); err != nil {
+			return delivered, fmt.Errorf("decode event %d: %w", oe.ID, err)
+		}
+	}
+	subs := make([]subscription, 0, len(b.subs[oe.Type])+len(b.all))
+	subs = append(subs, b.subs[oe.Type]...)
+	subs = append(subs, b.all...)
+	var errs []error
+	for _, sub := range subs {
+		if slices.Contains(delivered, sub.name) {
+			continue
+		}
+		err := call(ctx, sub.fn, e)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("events: %s #%d: %s skipped: %v", e.Type, e.ID, sub.name, err)
+			err = nil
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sub.name, err))
+			continue
+		}
+		delivered = append(delivered, sub.name)
+	}
+	if err := errors.Join(errs...); err != nil {
+		log.Printf("events: %s #%d: %v", e.Type, e.ID, err)
+		return delivered, err
+	}
+	return delivered, nil
+}
+
+// call runs a subscriber, turning a panic into an ordinary failure
+func call(ctx context.Context, fn Subscriber, e Event) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return fn(ctx, e)
+}
@@ -0,0 +1,86 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mosprom/api/internal/model"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestDeliverRetriesOnlyFailedSubscribers(t *testing.T) {
+	calls := map[string]int{}
+	failing := true
+	b := NewBus()
+	b.Subscribe(func(context.Context, Event) error {
+		calls["notify"]++
+		return nil
+	}, model.EventPostCreated)
+	b.Subscribe(func(context.Context, Event) error {
+		calls["flaky"]++
+		if failing {
+			return errors.New("receiver down")
+		}
+		return nil
+	}, model.EventPostCreated)
+	b.Subscribe(func(context.Context, Event) error {
+		calls["gone"]++
+		return fmt.Errorf("load post: %w", gorm.ErrRecordNotFound)
+	}, model.EventPostCreated)
+	b.SubscribeAll(func(context.Context, Event) error {
+		calls["all"]++
+		panic("boom")
+	})
+	b.Subscribe(func(context.Context, Event) error {
+		calls["other"]++
+		return nil
+	}, model.EventPostDeleted)
+
+	oe := model.OutboxEvent{ID: 1, Type: model.EventPostCreated, Payload: model.JSON(`{"post_id":7}`)}
+	delivered, err := b.deliver(context.Background(), oe)
+	if err == nil {
+		t.Fatal("deliver succeeded with a failing and a panicking subscriber")
+	}
+	if len(delivered) != 2 {
+		t.Fatalf("delivered to %q, want the succeeding and the not-found subscriber", delivered)
+	}
+
+	// the retry reaches the failed subscribers only
+	failing = false
+	oe.DeliveredTo = delivered
+	delivered, err = b.deliver(context.Background(), oe)
+	if err == nil {
+		t.Fatal("deliver succeeded with a panicking subscriber")
+	}
+	if len(delivered) != 3 {
+		t.Fatalf("delivered to %q after the retry, want 3 subscribers", delivered)
+	}
+	want := map[string]int{"notify": 1, "flaky": 2, "gone": 1, "all": 2}
+	for name, n := range want {
+		if calls[name] != n {
+			t.Errorf("%s called %d times, want %d", name, calls[name], n)
+		}
+	}
+	if calls["other"] != 0 {
+		t.Errorf("subscriber of another type called %d times", calls["other"])
+	}
+}
+
+func TestDeliverDecodesPayload(t *testing.T) {
+	var got Event
+	b := NewBus()
+	b.Subscribe(func(_ context.Context, e Event) error {
+		got = e
+		return nil
+	}, model.EventUserJoinedPost)
+	oe := model.OutboxEvent{ID: 3, Type: model.EventUserJoinedPost, Payload: model.JSON(`{"post_id":7,"user_id":9}`)}
+	delivered, err := b.deliver(context.Background(), oe)
+	if err != nil || len(delivered) != 1 {
+		t.Fatalf("deliver = %q, %v", delivered, err)
+	}
+	if got.ID != 3 || got.PostID != 7 || got.UserID != 9 {
+		t.Errorf("subscriber got %+v", got)
+	}
+}
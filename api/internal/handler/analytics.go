@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"mosprom/api/internal/service"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetClubAnalytics godoc
+// @Summary Club engagement analytics
+// @Description Subscriber growth, posts per type, views/joins/likes per post with view-to-join conversion, most active members and the technology profile of members. Dates are RFC3339 or YYYY-MM-DD; a date-only "to" includes that day.
+// @Tags clubs
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param from query string false "Range start (default: 90 days before to)"
+// @Param to query string false "Range end (default: now)"
+// @Param interval query string false "Growth bucket: day, week (default) or month"
+// @Success 200 {object} service.ClubAnalytics
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /clubs/id/{id}/analytics [get]
+func GetClubAnalytics(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	q := service.ClubAnalyticsQuery{Interval: c.Query("interval")}
+	var err error
+	if q.From, err = timeQuery(c, "from", false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+		return
+	}
+	if q.To, err = timeQuery(c, "to", true); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+		return
+	}
+	res, err := clubService.Analytics(uid, role, clubID, q)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// timeQuery parses an optional RFC3339 or YYYY-MM-DD query parameter. With endOfDay a bare date
+// means the start of the next day, so it can be used as an exclusive range end.
+func timeQuery(c *gin.Context, name string, endOfDay bool) (*time.Time, error) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.DateOnly, v)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
@@ -13,12 +13,11 @@ import (
 
 // RegisterRequest — тело запроса для регистрации пользователя
 type RegisterRequest struct {
-	TelegramName string   `json:"telegram_name" binding:"required"`
-	Name         string   `json:"name"`
-	Password     string   `json:"password" binding:"required"`
-	Description  string   `json:"description"`
-	University   string   `json:"university"`
-	Achievements []string `json:"achievements"`
+	TelegramName string `json:"telegram_name" binding:"required"`
+	Name         string `json:"name"`
+	Password     string `json:"password" binding:"required"`
+	Description  string `json:"description"`
+	University   string `json:"university"`
 }
 
 type RegisterResponse struct {
@@ -53,7 +52,6 @@ func Register(c *gin.Context) {
 		Password:     req.Password,
 		Description:  req.Description,
 		University:   req.University,
-		Achievements: req.Achievements,
 	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -69,6 +67,7 @@ func Register(c *gin.Context) {
 		"user_id": user.ID,
 		"name":    user.Name,
 		"tg":      user.TelegramName,
+		"role":    user.Role,
 		"exp":     time.Now().Add(24 * time.Hour).Unix(),
 	}
 
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"mosprom/api/internal/ical"
+	"mosprom/api/internal/service"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var calendarService = service.NewCalendarService(os.Getenv("PUBLIC_WEB_URL"))
+
+// requestBaseURL is the scheme and host the client used to reach the API
+func requestBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if p := c.GetHeader("X-Forwarded-Proto"); p != "" {
+		scheme = p
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+func writeCalendar(c *gin.Context, cal ical.Calendar, filename string) {
+	c.Header("Content-Type", "text/calendar; charset=utf-8")
+	c.Header("Content-Disposition", `inline; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+	_ = cal.Encode(c.Writer, time.Now())
+}
+
+// GetPostCalendar godoc
+// @Summary Post as an iCalendar event
+// @Description The event keeps its UID across updates; a deleted post is returned with STATUS:CANCELLED
+// @Tags calendar
+// @Produce text/calendar
+// @Param id path int true "Post ID"
+// @Success 200 {file} binary
+// @Failure 404 {object} map[string]string
+// @Router /posts/{id}/event.ics [get]
+func GetPostCalendar(c *gin.Context) {
+	postID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	cal, err := calendarService.PostCalendar(postID, requestBaseURL(c))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	writeCalendar(c, cal, "event.ics")
+}
+
+// GetClubCalendar godoc
+// @Summary Subscribable calendar of a club
+// @Description Posts the club hosts or co-hosts that end no earlier than 30 days ago, and their cancellations
+// @Tags calendar
+// @Produce text/calendar
+// @Param id path int true "Club ID"
+// @Success 200 {file} binary
+// @Failure 404 {object} map[string]string
+// @Router /clubs/id/{id}/calendar.ics [get]
+func GetClubCalendar(c *gin.Context) {
+	clubID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	cal, err := calendarService.ClubCalendar(clubID, requestBaseURL(c))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	writeCalendar(c, cal, "club.ics")
+}
+
+// GetMyCalendarFeed godoc
+// @Summary Personal calendar feed
+// @Description Calendar apps cannot send a bearer token, so the feed is authorized by the secret token in its URL (see GET /me/calendar)
+// @Tags calendar
+// @Produce text/calendar
+// @Param token query string true "Feed token"
+// @Success 200 {file} binary
+// @Failure 404 {object} map[string]string
+// @Router /me/calendar.ics [get]
+func GetMyCalendarFeed(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return
+	}
+	cal, err := calendarService.UserCalendar(token, requestBaseURL(c))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	writeCalendar(c, cal, "my-events.ics")
+}
+
+// GetMyCalendar godoc
+// @Summary Personal calendar feed URL
+// @Description Returns the secret feed URL of the current user's joined posts, creating it on first use
+// @Tags calendar
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} service.CalendarFeed
+// @Router /me/calendar [get]
+func GetMyCalendar(c *gin.Context) {
+	uid, _, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	feed, err := calendarService.MyCalendar(uid, requestBaseURL(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, feed)
+}
+
+// ResetMyCalendar godoc
+// @Summary Reset personal calendar feed URL
+// @Description Issues a new secret token; subscriptions using the old URL stop receiving updates
+// @Tags calendar
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} service.CalendarFeed
+// @Router /me/calendar/reset [post]
+func ResetMyCalendar(c *gin.Context) {
+	uid, _, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	feed, err := calendarService.ResetMyCalendar(uid, requestBaseURL(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, feed)
+}
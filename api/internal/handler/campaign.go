@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"mosprom/api/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var campaignService = service.NewCampaignService()
+
+// CreateCampaign godoc
+// @Summary Create invitation campaign
+// @Description A background worker invites users matching the criteria on the given cron schedule, one batch per run, until the quota is sent. Users already invited or already members are skipped.
+// @Tags campaigns
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param input body service.CreateCampaignInput true "Campaign"
+// @Success 201 {object} model.Campaign
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string "Club is archived"
+// @Router /clubs/id/{id}/campaigns [post]
+func CreateCampaign(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var body service.CreateCampaignInput
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	campaign, err := campaignService.Create(uid, role, clubID, body)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, campaign)
+}
+
+// ListCampaigns godoc
+// @Summary List club campaigns with stats
+// @Tags campaigns
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Club ID"
+// @Success 200 {array} model.Campaign
+// @Failure 403 {object} map[string]string
+// @Router /clubs/id/{id}/campaigns [get]
+func ListCampaigns(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	items, err := campaignService.List(uid, role, clubID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// GetCampaign godoc
+// @Summary Get campaign with stats
+// @Tags campaigns
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param campaign_id path int true "Campaign ID"
+// @Success 200 {object} model.Campaign
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /clubs/id/{id}/campaigns/{campaign_id} [get]
+func GetCampaign(c *gin.Context) {
+	withCampaign(c, campaignService.Get)
+}
+
+// PauseCampaign godoc
+// @Summary Pause campaign
+// @Tags campaigns
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param campaign_id path int true "Campaign ID"
+// @Success 200 {object} model.Campaign
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /clubs/id/{id}/campaigns/{campaign_id}/pause [post]
+func PauseCampaign(c *gin.Context) {
+	withCampaign(c, campaignService.Pause)
+}
+
+// ResumeCampaign godoc
+// @Summary Resume campaign
+// @Tags campaigns
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param campaign_id path int true "Campaign ID"
+// @Success 200 {object} model.Campaign
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /clubs/id/{id}/campaigns/{campaign_id}/resume [post]
+func ResumeCampaign(c *gin.Context) {
+	withCampaign(c, campaignService.Resume)
+}
+
+func withCampaign[T any](c *gin.Context, fn func(actorID uint, role string, clubID, campaignID uint) (T, error)) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok1 := uintParam(c, "id")
+	campaignID, ok2 := uintParam(c, "campaign_id")
+	if !ok1 || !ok2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	res, err := fn(uid, role, clubID, campaignID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type checkInRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// GetCheckInCode godoc
+// @Summary Current check-in code of a post
+// @Description The code rotates every minute and the previous one stays valid for another minute; managers of the host club
+// @Tags check-in
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Post ID"
+// @Success 200 {object} service.CheckInCode
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /posts/{id}/check-in/code [get]
+func GetCheckInCode(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	postID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	code, err := clubService.CheckInCode(uid, role, postID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, code)
+}
+
+// GetCheckInQR godoc
+// @Summary Current check-in code of a post as a QR image
+// @Description Refetch after X-Code-Expires-At to show the next code; managers of the host club
+// @Tags check-in
+// @Security BearerAuth
+// @Produce png
+// @Param id path int true "Post ID"
+// @Success 200 {file} binary
+// @Header 200 {string} X-Code-Expires-At "RFC3339 time the next code is issued"
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /posts/{id}/check-in/qr.png [get]
+func GetCheckInQR(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	postID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	png, code, err := clubService.CheckInQR(uid, role, postID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Code-Expires-At", code.ExpiresAt.UTC().Format(time.RFC3339))
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+// CheckIn records the current user's attendance from a scanned code
+// @Summary Check in to a post
+// @Description Participants scan the organizer's code from an hour before the start until two hours after the end
+// @Tags check-in
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Post ID"
+// @Param input body checkInRequest true "Scanned code"
+// @Success 200 {object} model.PostAttendance
+// @Failure 400 {object} map[string]string "Invalid or expired code"
+// @Failure 403 {object} map[string]string "Not a participant"
+// @Failure 409 {object} map[string]string "Check-in is not open"
+// @Router /posts/{id}/check-in [post]
+func (h *PostHandler) CheckIn(c *gin.Context) {
+	uid, _, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	postID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var body checkInRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	att, err := h.postService.CheckIn(uid, postID, body.Code)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, att)
+}
+
+// ManualCheckIn godoc
+// @Summary Check a participant in manually
+// @Tags check-in
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Post ID"
+// @Param user_id path int true "Participant user ID"
+// @Success 200 {object} model.PostAttendance
+// @Failure 403 {object} map[string]string "Not a manager, or the user is not a participant"
+// @Failure 404 {object} map[string]string
+// @Router /posts/{id}/check-in/{user_id} [post]
+func ManualCheckIn(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	postID, ok1 := uintParam(c, "id")
+	userID, ok2 := uintParam(c, "user_id")
+	if !ok1 || !ok2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	att, err := clubService.ManualCheckIn(uid, role, postID, userID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, att)
+}
+
+// GetPostAttendance godoc
+// @Summary List check-ins of a post
+// @Tags check-in
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Post ID"
+// @Success 200 {array} model.PostAttendance
+// @Header 200 {integer} X-Total-Count "Number of check-ins"
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /posts/{id}/attendance [get]
+func GetPostAttendance(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	postID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	items, err := clubService.Attendance(uid, role, postID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Header("X-Total-Count", strconv.Itoa(len(items)))
+	c.JSON(http.StatusOK, items)
+}
@@ -86,12 +86,141 @@ func SetClubLogo(c *gin.Context) {
 		return
 	}
 	if err := clubService.SetLogo(uint(id64), filename); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		removePhoto(filename)
+		writeServiceError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"logo": filename})
 }
 
+// UpdateClub godoc
+// @Summary Update club
+// @Description Edit name, description and directions (directions are replaced as a whole). Only the club owner, club admins and platform admins may edit; archived clubs are read-only.
+// @Tags clubs
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param input body service.UpdateClubInput true "Fields to change"
+// @Success 200 {object} model.Club
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Club is archived"
+// @Router /clubs/{id} [patch]
+func UpdateClub(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var body service.UpdateClubInput
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	club, err := clubService.UpdateClub(uid, role, clubID, body)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, club)
+}
+
+// ArchiveClub godoc
+// @Summary Archive club
+// @Description Archived clubs are read-only, hidden from the club list and their chat is frozen
+// @Tags clubs
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Club ID"
+// @Success 200 {object} model.Club
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /clubs/{id}/archive [post]
+func ArchiveClub(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	club, err := clubService.Archive(uid, role, clubID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, club)
+}
+
+// UnarchiveClub godoc
+// @Summary Unarchive club
+// @Tags clubs
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Club ID"
+// @Success 200 {object} model.Club
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /clubs/{id}/unarchive [post]
+func UnarchiveClub(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	club, err := clubService.Unarchive(uid, role, clubID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, club)
+}
+
+// DeleteClub godoc
+// @Summary Delete club
+// @Description Permanently deletes the club with its posts, subscriptions and logo file
+// @Tags clubs
+// @Security BearerAuth
+// @Param id path int true "Club ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /clubs/{id} [delete]
+func DeleteClub(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	club, err := clubService.Delete(uid, role, clubID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	removePhoto(club.Logo)
+	c.Status(http.StatusNoContent)
+}
+
 // GetClubByName godoc
 // @Summary Get club by name
 // @Tags clubs
@@ -165,12 +294,19 @@ func ListClubs(c *gin.Context) {
 
 // SubscribeToClub godoc
 // @Summary Subscribe current user to a club
+// @Description Open clubs subscribe immediately (204). Request-to-join clubs file a join request instead (202); the optional message is shown to club admins.
 // @Tags clubs
 // @Security BearerAuth
+// @Accept json
+// @Produce json
 // @Param id path int true "Club ID"
+// @Param input body subscribeRequest false "Message for join requests"
+// @Success 202 {object} model.ClubJoinRequest
 // @Success 204
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string "Banned from the club"
+// @Failure 409 {object} map[string]string "Archived club, already member or pending request"
 // @Router /clubs/id/{id}/subscribe [post]
 func SubscribeToClub(c *gin.Context) {
 	uidAny, exists := c.Get("user_id")
@@ -184,8 +320,20 @@ func SubscribeToClub(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
 		return
 	}
-	if err := clubService.Subscribe(uid, uint(id64)); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var body subscribeRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	req, err := clubService.Subscribe(uid, uint(id64), body.Message)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	if req != nil {
+		c.JSON(http.StatusAccepted, req)
 		return
 	}
 	c.Status(http.StatusNoContent)
@@ -236,6 +384,31 @@ func GetUserClubs(c *gin.Context) {
 	c.JSON(http.StatusOK, clubs)
 }
 
+// GetRecommendedClubs godoc
+// @Summary Recommended clubs for me
+// @Description Ranks clubs the user has not joined: score = 0.35*DirectionMatch + 0.25*TechMatch + 0.25*MemberOverlap + 0.15*Activity. Each result explains why it was suggested.
+// @Tags profile
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Max results (default 20, max 100)"
+// @Success 200 {array} service.RecommendedClub
+// @Failure 401 {object} map[string]string
+// @Router /me/clubs/recommended [get]
+func GetRecommendedClubs(c *gin.Context) {
+	uid, _, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	res, err := clubService.RecommendedForUser(uid, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
 // GetSubscriberClubs godoc
 // @Summary List clubs a user is subscribed to
 // @Tags users
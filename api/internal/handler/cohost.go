@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"mosprom/api/internal/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type inviteCoHostBody struct {
+	ClubID uint `json:"club_id" binding:"required"`
+}
+
+// InvitePostCoHost godoc
+// @Summary Invite a club to co-host a post
+// @Description Managers of the host club invite another club; once it accepts, the post is listed by both clubs and counted in both clubs' analytics
+// @Tags co-hosts
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Post ID"
+// @Param input body inviteCoHostBody true "Invited club"
+// @Success 201 {object} model.PostCoHost
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Already co-hosting or invited, or club archived"
+// @Router /posts/{id}/co-hosts [post]
+func InvitePostCoHost(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	postID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var body inviteCoHostBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h, err := clubService.InviteCoHost(uid, role, postID, body.ClubID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, h)
+}
+
+// ListPostCoHosts godoc
+// @Summary List co-host invitations of a post
+// @Description Pending, accepted and declined; for managers of the host club
+// @Tags co-hosts
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Post ID"
+// @Success 200 {array} model.PostCoHost
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /posts/{id}/co-hosts [get]
+func ListPostCoHosts(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	postID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	items, err := clubService.PostCoHosts(uid, role, postID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// GetPostWaitlist godoc
+// @Summary List a post's waitlist
+// @Description Users waiting for a place, in promotion order; managers of the host club
+// @Tags posts
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Post ID"
+// @Success 200 {array} model.PostWaitlistEntry
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /posts/{id}/waitlist [get]
+func GetPostWaitlist(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	postID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	entries, err := clubService.PostWaitlist(uid, role, postID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// RemovePostCoHost godoc
+// @Summary Remove a co-host club
+// @Description Withdraws the invitation or ends co-hosting; managers of the host or the co-host club
+// @Tags co-hosts
+// @Security BearerAuth
+// @Param id path int true "Post ID"
+// @Param club_id path int true "Co-host club ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /posts/{id}/co-hosts/{club_id} [delete]
+func RemovePostCoHost(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	postID, ok1 := uintParam(c, "id")
+	clubID, ok2 := uintParam(c, "club_id")
+	if !ok1 || !ok2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := clubService.RemoveCoHost(uid, role, postID, clubID); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListClubCoHostings godoc
+// @Summary Co-host invitations of a club
+// @Tags co-hosts
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param status query string false "pending, accepted or declined"
+// @Success 200 {array} model.PostCoHost
+// @Failure 403 {object} map[string]string
+// @Router /clubs/id/{id}/co-hosting [get]
+func ListClubCoHostings(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	items, err := clubService.CoHostings(uid, role, clubID, model.CoHostStatus(c.Query("status")))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// AcceptCoHosting godoc
+// @Summary Accept co-hosting a post
+// @Tags co-hosts
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param post_id path int true "Post ID"
+// @Success 200 {object} model.PostCoHost
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Already answered"
+// @Router /clubs/id/{id}/co-hosting/{post_id}/accept [post]
+func AcceptCoHosting(c *gin.Context) { respondToCoHosting(c, true) }
+
+// DeclineCoHosting godoc
+// @Summary Decline co-hosting a post
+// @Tags co-hosts
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param post_id path int true "Post ID"
+// @Success 200 {object} model.PostCoHost
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Already answered"
+// @Router /clubs/id/{id}/co-hosting/{post_id}/decline [post]
+func DeclineCoHosting(c *gin.Context) { respondToCoHosting(c, false) }
+
+func respondToCoHosting(c *gin.Context, accept bool) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok1 := uintParam(c, "id")
+	postID, ok2 := uintParam(c, "post_id")
+	if !ok1 || !ok2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	h, err := clubService.RespondToCoHost(uid, role, clubID, postID, accept)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, h)
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"errors"
+	"mosprom/api/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// writeServiceError maps well-known service errors to HTTP statuses; anything else is a bad request
+func writeServiceError(c *gin.Context, err error) {
+	var ageErr *service.AgeRestrictionError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case errors.Is(err, service.ErrForbidden), errors.Is(err, service.ErrBannedFromClub),
+		errors.Is(err, service.ErrNotParticipant):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrClubArchived), errors.Is(err, service.ErrJoinRequestPending),
+		errors.Is(err, service.ErrJoinRequestDecided), errors.Is(err, service.ErrAlreadyMember),
+		errors.Is(err, service.ErrInvitationResponded), errors.Is(err, service.ErrOrganizationReviewed),
+		errors.Is(err, service.ErrINNClaimed), errors.Is(err, service.ErrOrganizationReleased),
+		errors.Is(err, service.ErrAlreadyCoHost), errors.Is(err, service.ErrCoHostResponded),
+		errors.Is(err, service.ErrRegistrationPending), errors.Is(err, service.ErrRegistrationDecided),
+		errors.Is(err, service.ErrAlreadyParticipant), errors.Is(err, service.ErrCheckedIn),
+		errors.Is(err, service.ErrCheckInClosed), errors.Is(err, service.ErrSeriesEnded):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.As(err, &ageErr):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":               err.Error(),
+			"code":                "age_restricted",
+			"min_age":             ageErr.MinAge,
+			"birth_date_required": ageErr.MissingBirthDate,
+		})
+	case errors.Is(err, service.ErrInviteUnusable):
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+// currentUser returns the authorized user id and platform role set by middleware.JWTAuth
+func currentUser(c *gin.Context) (uint, string, bool) {
+	uidAny, exists := c.Get("user_id")
+	if !exists {
+		return 0, "", false
+	}
+	uid, ok := uidAny.(uint)
+	if !ok {
+		return 0, "", false
+	}
+	role, _ := c.Get("role")
+	r, _ := role.(string)
+	return uid, r, true
+}
+
+// uintParam parses a numeric path parameter
+func uintParam(c *gin.Context, name string) (uint, bool) {
+	id64, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return uint(id64), true
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"io"
+	"log"
 	"mime/multipart"
 	"mosprom/api/internal/service"
 	"net/http"
@@ -61,7 +62,8 @@ func GetMe(c *gin.Context) {
 
 // UpdateMe godoc
 // @Summary Update current user profile
-// @Description Update current authorized user profile, accepts JSON or multipart/form-data
+// @Description Update current authorized user profile, accepts JSON or multipart/form-data.
+// @Description birth_date (YYYY-MM-DD, empty or null clears) is never returned; it only decides eligibility for age-restricted posts.
 // @Tags profile
 // @Security BearerAuth
 // @Accept json
@@ -94,15 +96,6 @@ func UpdateMe(c *gin.Context) {
 		if v := c.PostForm("university"); v != "" {
 			input.University = &v
 		}
-		if v := c.PostForm("events_count"); v != "" {
-			if n, err := strconv.Atoi(v); err == nil {
-				input.EventsCount = &n
-			}
-		}
-		if v := c.PostForm("achievements"); v != "" {
-			a := parseCSVList(v)
-			input.Achievements = &a
-		}
 		if v := c.PostForm("technologies"); v != "" {
 			t := parseCSVList(v)
 			input.Technologies = &t
@@ -111,6 +104,9 @@ func UpdateMe(c *gin.Context) {
 			d := parseCSVList(v)
 			input.Directions = &d
 		}
+		if v, ok := c.GetPostForm("birth_date"); ok {
+			input.BirthDate = &v
+		}
 		if file, err := c.FormFile("photo"); err == nil && file != nil {
 			p, err := savePhoto(file)
 			if err != nil {
@@ -140,19 +136,17 @@ func UpdateMe(c *gin.Context) {
 		if v, ok := body["university"].(string); ok {
 			input.University = &v
 		}
-		if v, ok := body["events_count"].(float64); ok {
-			n := int(v)
-			input.EventsCount = &n
-		}
-		if v, ok := body["achievements"].([]any); ok {
-			input.Achievements = sliceAnyToStringPtr(v)
-		}
 		if v, ok := body["technologies"].([]any); ok {
 			input.Technologies = sliceAnyToStringPtr(v)
 		}
 		if v, ok := body["directions"].([]any); ok {
 			input.Directions = sliceAnyToStringPtr(v)
 		}
+		if v, ok := body["birth_date"]; ok {
+			// null clears the birth date like an empty string
+			d, _ := v.(string)
+			input.BirthDate = &d
+		}
 	}
 
 	if photoPath != nil {
@@ -208,7 +202,6 @@ func CreateUser(c *gin.Context) {
 	ct := c.ContentType()
 	var input service.CreateUserInput
 
-	var achievements []string
 	var technologies []string
 	var directions []string
 
@@ -222,10 +215,6 @@ func CreateUser(c *gin.Context) {
 		input.Password = c.PostForm("password")
 		input.Description = c.PostForm("description")
 		input.University = c.PostForm("university")
-		if ec, err := strconv.Atoi(c.PostForm("events_count")); err == nil {
-			input.EventsCount = ec
-		}
-		achievements = parseCSVList(c.PostForm("achievements"))
 		technologies = parseCSVList(c.PostForm("technologies"))
 		directions = parseCSVList(c.PostForm("directions"))
 
@@ -245,8 +234,6 @@ func CreateUser(c *gin.Context) {
 			Password     string   `json:"password" binding:"required"`
 			Description  string   `json:"description"`
 			University   string   `json:"university"`
-			EventsCount  int      `json:"events_count"`
-			Achievements []string `json:"achievements"`
 			Technologies []string `json:"technologies"`
 			Directions   []string `json:"directions"`
 			PhotoBase64  string   `json:"photo_base64"` // опционально
@@ -260,14 +247,11 @@ func CreateUser(c *gin.Context) {
 		input.Password = body.Password
 		input.Description = body.Description
 		input.University = body.University
-		input.EventsCount = body.EventsCount
-		achievements = body.Achievements
 		technologies = body.Technologies
 		directions = body.Directions
 		// For simplicity, we ignore PhotoBase64 here, or could implement decoding
 	}
 
-	input.Achievements = achievements
 	input.Technologies = technologies
 	input.Directions = directions
 	input.PhotoPath = photoPath
@@ -323,16 +307,6 @@ func UpdateUser(c *gin.Context) {
 		if v := c.PostForm("university"); v != "" {
 			input.University = &v
 		}
-		if v := c.PostForm("events_count"); v != "" {
-			if n, err := strconv.Atoi(v); err == nil {
-				input.EventsCount = &n
-			}
-		}
-
-		if v := c.PostForm("achievements"); v != "" {
-			a := parseCSVList(v)
-			input.Achievements = &a
-		}
 		if v := c.PostForm("technologies"); v != "" {
 			t := parseCSVList(v)
 			input.Technologies = &t
@@ -373,13 +347,6 @@ func UpdateUser(c *gin.Context) {
 		if v, ok := body["university"].(string); ok {
 			input.University = &v
 		}
-		if v, ok := body["events_count"].(float64); ok {
-			n := int(v)
-			input.EventsCount = &n
-		}
-		if v, ok := body["achievements"].([]any); ok {
-			input.Achievements = sliceAnyToStringPtr(v)
-		}
 		if v, ok := body["technologies"].([]any); ok {
 			input.Technologies = sliceAnyToStringPtr(v)
 		}
@@ -530,6 +497,17 @@ func saveUploadedFile(fh *multipart.FileHeader, dst string) error {
 	return nil
 }
 
+// removePhoto deletes an uploaded file saved by savePhoto; missing files are ignored
+func removePhoto(filename string) {
+	name := filepath.Base(filename)
+	if name == "." || name == "" || name == string(filepath.Separator) {
+		return
+	}
+	if err := os.Remove(filepath.Join("uploads", "photos", name)); err != nil && !os.IsNotExist(err) {
+		log.Printf("remove photo %s: %v", name, err)
+	}
+}
+
 func timeNowUnix() int64 { return time.Now().Unix() }
 
 // GetPhotoByName godoc
@@ -686,16 +664,17 @@ func GetMyAchievements(c *gin.Context) {
 
 // RecomputeUserRatings godoc
 // @Summary Recompute user ratings
-// @Description Recalculate rating for all users based on participation and achievements
+// @Description Enqueue a background job recalculating rating for all users based on participation and achievements. The same job also runs nightly.
 // @Tags users
 // @Produce json
-// @Success 200 {object} map[string]string
+// @Success 202 {object} map[string]interface{}
 // @Failure 500 {object} map[string]string
 // @Router /users/recompute_ratings [post]
 func RecomputeUserRatings(c *gin.Context) {
-	if err := userService.RecomputeAllRatings(); err != nil {
+	job, err := userService.EnqueueRatingRecompute()
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	c.JSON(http.StatusAccepted, gin.H{"status": "queued", "job_id": job.ID})
 }
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mosprom/api/internal/model"
+	"mosprom/api/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxImportFileSize = 5 << 20
+
+// ImportClubPosts godoc
+// @Summary Import posts from an .ics or CSV file
+// @Description Creates posts of the club from an iCalendar export (Outlook, Google) or a spreadsheet saved as CSV; managers of the club.
+// @Description mapping is a JSON object from post fields (title, description, type, start_date, end_date, age_restriction, format, address, capacity, registration_mode, technologies, external_id) to CSV headers or iCalendar properties.
+// @Description CSV columns named like the fields are read without a mapping; .ics files read SUMMARY, DESCRIPTION, DTSTART, DTEND, LOCATION, CATEGORIES and UID.
+// @Description Rows are created in one transaction and only if none is invalid (422 with per-row errors). Rows imported before, by external_id or else by title and start date, are reported as duplicates and not created again.
+// @Tags posts
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param file formData file true ".ics or .csv file, up to 5 MB"
+// @Param mapping formData string false "Field mapping as JSON"
+// @Param type formData string false "Post type of rows without one (default activity)"
+// @Param timezone formData string false "IANA time zone of times without an offset (default UTC)"
+// @Param dry_run formData bool false "Validate and preview without creating posts"
+// @Success 200 {object} service.ImportResult
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string "Club is archived"
+// @Failure 422 {object} service.ImportResult
+// @Router /clubs/id/{id}/posts/import [post]
+func (h *PostHandler) ImportClubPosts(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	if file.Size > maxImportFileSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is larger than 5 MB"})
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxImportFileSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	input := service.ImportPostsInput{
+		Filename:    file.Filename,
+		Data:        data,
+		DefaultType: model.PostType(c.PostForm("type")),
+		TimeZone:    c.PostForm("timezone"),
+	}
+	if v := c.PostForm("mapping"); v != "" {
+		if err := json.Unmarshal([]byte(v), &input.Mapping); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "mapping must be a JSON object of strings"})
+			return
+		}
+	}
+	if v := c.PostForm("dry_run"); v != "" {
+		if input.DryRun, err = strconv.ParseBool(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "dry_run must be a boolean"})
+			return
+		}
+	}
+
+	result, err := h.postService.ImportPosts(uid, role, clubID, input)
+	if errors.Is(err, service.ErrImportInvalid) {
+		c.JSON(http.StatusUnprocessableEntity, result)
+		return
+	}
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"mosprom/api/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InviteUsersToClub godoc
+// @Summary Invite users to a club or post
+// @Description Club admins invite one or many users (e.g. from /posts/{id}/recommended_users). Users already invited, already taking part, banned or unknown are reported in "skipped".
+// @Tags invitations
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param input body service.InviteUsersInput true "Invitees"
+// @Success 201 {object} service.InviteUsersResult
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string "Club is archived"
+// @Router /clubs/id/{id}/invitations [post]
+func InviteUsersToClub(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var body service.InviteUsersInput
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	res, err := clubService.InviteUsers(uid, role, clubID, body)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, res)
+}
+
+// ListClubInvitations godoc
+// @Summary List invitations sent by a club
+// @Tags invitations
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param post_id query int false "Only invitations to this post"
+// @Param status query string false "pending, accepted or declined"
+// @Success 200 {array} model.Invitation
+// @Failure 403 {object} map[string]string
+// @Router /clubs/id/{id}/invitations [get]
+func ListClubInvitations(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var postID *uint
+	if v := c.Query("post_id"); v != "" {
+		id64, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post_id"})
+			return
+		}
+		id := uint(id64)
+		postID = &id
+	}
+	items, err := clubService.Invitations(uid, role, clubID, postID, c.Query("status"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// GetMyInvitations godoc
+// @Summary List invitations of the current user
+// @Tags invitations
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "pending, accepted or declined"
+// @Success 200 {array} model.Invitation
+// @Failure 401 {object} map[string]string
+// @Router /me/invitations [get]
+func GetMyInvitations(c *gin.Context) {
+	uid, _, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	items, err := clubService.MyInvitations(uid, c.Query("status"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// AcceptInvitation godoc
+// @Summary Accept an invitation
+// @Description Joins the post or subscribes to the club
+// @Tags invitations
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Invitation ID"
+// @Success 200 {object} model.Invitation
+// @Failure 403 {object} map[string]string "Banned from the club"
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Already answered or club archived"
+// @Router /me/invitations/{id}/accept [post]
+func AcceptInvitation(c *gin.Context) {
+	respondToInvitation(c, true)
+}
+
+// DeclineInvitation godoc
+// @Summary Decline an invitation
+// @Tags invitations
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Invitation ID"
+// @Success 200 {object} model.Invitation
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Already answered"
+// @Router /me/invitations/{id}/decline [post]
+func DeclineInvitation(c *gin.Context) {
+	respondToInvitation(c, false)
+}
+
+func respondToInvitation(c *gin.Context, accept bool) {
+	uid, _, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	inv, err := clubService.RespondToInvitation(uid, id, accept)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, inv)
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"mosprom/api/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateClubInvite godoc
+// @Summary Create club invite
+// @Description Issues a shareable token with optional expiry, use limit and a club role granted on redeem
+// @Tags clubs
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param input body service.CreateInviteInput false "Invite settings"
+// @Success 201 {object} model.ClubInvite
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string "Club is archived"
+// @Router /clubs/id/{id}/invites [post]
+func CreateClubInvite(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var body service.CreateInviteInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	invite, err := clubService.CreateInvite(uid, role, clubID, body)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, invite)
+}
+
+// ListClubInvites godoc
+// @Summary List club invites
+// @Tags clubs
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Club ID"
+// @Success 200 {array} model.ClubInvite
+// @Failure 403 {object} map[string]string
+// @Router /clubs/id/{id}/invites [get]
+func ListClubInvites(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	invites, err := clubService.Invites(uid, role, clubID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, invites)
+}
+
+// RevokeClubInvite godoc
+// @Summary Revoke club invite
+// @Tags clubs
+// @Security BearerAuth
+// @Param id path int true "Club ID"
+// @Param invite_id path int true "Invite ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /clubs/id/{id}/invites/{invite_id} [delete]
+func RevokeClubInvite(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok1 := uintParam(c, "id")
+	inviteID, ok2 := uintParam(c, "invite_id")
+	if !ok1 || !ok2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := clubService.RevokeInvite(uid, role, clubID, inviteID); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RedeemClubInvite godoc
+// @Summary Redeem club invite
+// @Description Subscribes the current user to the invite's club with the invite's role
+// @Tags clubs
+// @Security BearerAuth
+// @Produce json
+// @Param token path string true "Invite token"
+// @Success 200 {object} model.ClubInvite
+// @Failure 403 {object} map[string]string "Banned from the club"
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Already member or club archived"
+// @Failure 410 {object} map[string]string "Invite revoked, expired or used up"
+// @Router /clubs/invites/{token}/redeem [post]
+func RedeemClubInvite(c *gin.Context) {
+	uid, _, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	invite, err := clubService.RedeemInvite(uid, c.Param("token"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, invite)
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"errors"
+	"mosprom/api/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var jobService = service.NewJobService()
+
+// ListJobs godoc
+// @Summary List background jobs
+// @Description Newest first. Filter by status (queued, running, done, dead) and type.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Job status"
+// @Param type query string false "Job type"
+// @Param limit query int false "Max rows (default 100, max 500)"
+// @Success 200 {array} model.Job
+// @Failure 403 {object} map[string]string
+// @Router /admin/jobs [get]
+func ListJobs(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	items, err := jobService.List(c.Query("status"), c.Query("type"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// GetJob godoc
+// @Summary Get background job
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Job ID"
+// @Success 200 {object} model.Job
+// @Failure 404 {object} map[string]string
+// @Router /admin/jobs/{id} [get]
+func GetJob(c *gin.Context) {
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	job, err := jobService.Get(uint(id64))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// RequeueJob godoc
+// @Summary Requeue background job
+// @Description Puts a done, dead or waiting job back to the queue to run now with a fresh attempt budget
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Job ID"
+// @Success 200 {object} model.Job
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /admin/jobs/{id}/requeue [post]
+func RequeueJob(c *gin.Context) {
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	job, err := jobService.Requeue(uint(id64))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		case errors.Is(err, service.ErrJobRunning):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// ListRecurringJobs godoc
+// @Summary List recurring job schedules
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} model.RecurringJob
+// @Failure 403 {object} map[string]string
+// @Router /admin/jobs/recurring [get]
+func ListRecurringJobs(c *gin.Context) {
+	items, err := jobService.Recurring()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
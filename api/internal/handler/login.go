@@ -58,6 +58,7 @@ func Login(c *gin.Context) {
 		"user_id": user.ID,
 		"name":    user.Name,
 		"tg":      user.TelegramName,
+		"role":    user.Role,
 		"exp":     time.Now().Add(24 * time.Hour).Unix(),
 	}
 
@@ -0,0 +1,262 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type subscribeRequest struct {
+	Message string `json:"message"`
+}
+
+type moderationRequest struct {
+	Reason string `json:"reason"`
+}
+
+type banRequest struct {
+	UserID uint   `json:"user_id" binding:"required"`
+	Reason string `json:"reason"`
+}
+
+// UnsubscribeFromClub godoc
+// @Summary Unsubscribe current user from a club
+// @Tags clubs
+// @Security BearerAuth
+// @Param id path int true "Club ID"
+// @Success 204
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string "Not subscribed"
+// @Router /clubs/id/{id}/subscribe [delete]
+func UnsubscribeFromClub(c *gin.Context) {
+	uid, _, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := clubService.Unsubscribe(uid, clubID); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// KickClubMember godoc
+// @Summary Remove a member from a club
+// @Description The user is notified with the reason and may subscribe again later
+// @Tags clubs
+// @Security BearerAuth
+// @Accept json
+// @Param id path int true "Club ID"
+// @Param user_id path int true "User ID"
+// @Param input body moderationRequest false "Reason"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string "Not a member"
+// @Router /clubs/id/{id}/members/{user_id}/kick [post]
+func KickClubMember(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok1 := uintParam(c, "id")
+	userID, ok2 := uintParam(c, "user_id")
+	if !ok1 || !ok2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var body moderationRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if err := clubService.Kick(uid, role, clubID, userID, body.Reason); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListClubBans godoc
+// @Summary List banned users of a club
+// @Tags clubs
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Club ID"
+// @Success 200 {array} model.ClubBan
+// @Failure 403 {object} map[string]string
+// @Router /clubs/id/{id}/bans [get]
+func ListClubBans(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	bans, err := clubService.Bans(uid, role, clubID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, bans)
+}
+
+// BanClubMember godoc
+// @Summary Ban a user from a club
+// @Description Removes the subscription, rejects pending join requests and blocks the club chat
+// @Tags clubs
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param input body banRequest true "User and reason"
+// @Success 201 {object} model.ClubBan
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /clubs/id/{id}/bans [post]
+func BanClubMember(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var body banRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ban, err := clubService.Ban(uid, role, clubID, body.UserID, body.Reason)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, ban)
+}
+
+// UnbanClubMember godoc
+// @Summary Lift a club ban
+// @Tags clubs
+// @Security BearerAuth
+// @Param id path int true "Club ID"
+// @Param user_id path int true "User ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /clubs/id/{id}/bans/{user_id} [delete]
+func UnbanClubMember(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok1 := uintParam(c, "id")
+	userID, ok2 := uintParam(c, "user_id")
+	if !ok1 || !ok2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := clubService.Unban(uid, role, clubID, userID); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListClubJoinRequests godoc
+// @Summary List join requests of a club
+// @Tags clubs
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param status query string false "pending, approved or rejected (default: all)"
+// @Success 200 {array} model.ClubJoinRequest
+// @Failure 403 {object} map[string]string
+// @Router /clubs/id/{id}/join-requests [get]
+func ListClubJoinRequests(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	items, err := clubService.JoinRequests(uid, role, clubID, c.Query("status"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// ApproveClubJoinRequest godoc
+// @Summary Approve a join request
+// @Tags clubs
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param request_id path int true "Join request ID"
+// @Success 200 {object} model.ClubJoinRequest
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Already decided"
+// @Router /clubs/id/{id}/join-requests/{request_id}/approve [post]
+func ApproveClubJoinRequest(c *gin.Context) {
+	decideClubJoinRequest(c, true)
+}
+
+// RejectClubJoinRequest godoc
+// @Summary Reject a join request
+// @Tags clubs
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param request_id path int true "Join request ID"
+// @Success 200 {object} model.ClubJoinRequest
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Already decided"
+// @Router /clubs/id/{id}/join-requests/{request_id}/reject [post]
+func RejectClubJoinRequest(c *gin.Context) {
+	decideClubJoinRequest(c, false)
+}
+
+func decideClubJoinRequest(c *gin.Context, approve bool) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok1 := uintParam(c, "id")
+	requestID, ok2 := uintParam(c, "request_id")
+	if !ok1 || !ok2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	req, err := clubService.DecideJoinRequest(uid, role, clubID, requestID, approve)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, req)
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"mosprom/api/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var notificationService = service.NewNotificationService()
+
+// GetMyNotifications godoc
+// @Summary List current user notifications
+// @Description Newest first. Pass unread=true to get only unread notifications.
+// @Tags profile
+// @Security BearerAuth
+// @Produce json
+// @Param unread query bool false "Only unread"
+// @Success 200 {array} model.Notification
+// @Failure 401 {object} map[string]string
+// @Router /me/notifications [get]
+func GetMyNotifications(c *gin.Context) {
+	uidAny, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	uid := uidAny.(uint)
+	unread, _ := strconv.ParseBool(c.Query("unread"))
+	items, err := notificationService.List(uid, unread)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// MarkNotificationRead godoc
+// @Summary Mark a notification as read
+// @Tags profile
+// @Security BearerAuth
+// @Param id path int true "Notification ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /me/notifications/{id}/read [post]
+func MarkNotificationRead(c *gin.Context) {
+	uidAny, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	uid := uidAny.(uint)
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	found, err := notificationService.MarkRead(uid, uint(id64))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
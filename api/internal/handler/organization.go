@@ -0,0 +1,439 @@
+package handler
+
+import (
+	"mosprom/api/internal/model"
+	"mosprom/api/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var organizationService = service.NewOrganizationService()
+
+// CreateOrganization godoc
+// @Summary Register an organization
+// @Description Creates a pending organization with the current user as owner; a platform admin verifies it
+// @Tags organizations
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param input body service.OrganizationInput true "Legal details"
+// @Success 201 {object} model.Organization
+// @Failure 400 {object} map[string]string
+// @Router /organizations [post]
+func CreateOrganization(c *gin.Context) {
+	uid, _, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var body service.OrganizationInput
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	org, err := organizationService.Create(uid, body)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, org)
+}
+
+// ListOrganizations godoc
+// @Summary List verified organizations
+// @Tags organizations
+// @Produce json
+// @Success 200 {array} model.Organization
+// @Router /organizations [get]
+func ListOrganizations(c *gin.Context) {
+	items, err := organizationService.List(model.OrganizationVerified)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// GetOrganization godoc
+// @Summary Get organization
+// @Tags organizations
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {object} model.Organization
+// @Failure 404 {object} map[string]string
+// @Router /organizations/{id} [get]
+func GetOrganization(c *gin.Context) {
+	orgID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	org, err := organizationService.Get(orgID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, org)
+}
+
+// UpdateOrganization godoc
+// @Summary Update organization
+// @Description Owners edit the details; changing legal details of a reviewed organization sends it back to verification and removes the badge from its clubs
+// @Tags organizations
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param input body service.OrganizationInput true "Legal details"
+// @Success 200 {object} model.Organization
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /organizations/{id} [put]
+func UpdateOrganization(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	orgID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var body service.OrganizationInput
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	org, err := organizationService.Update(uid, role, orgID, body)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, org)
+}
+
+// GetMyOrganizations godoc
+// @Summary Organizations of the current user
+// @Description Organizations where the current user is owner or staff, in any verification status
+// @Tags organizations
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} model.Organization
+// @Router /me/organizations [get]
+func GetMyOrganizations(c *gin.Context) {
+	uid, _, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	items, err := organizationService.Mine(uid)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// ListOrganizationStaff godoc
+// @Summary List organization staff
+// @Tags organizations
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {array} model.OrganizationStaff
+// @Failure 403 {object} map[string]string
+// @Router /organizations/{id}/staff [get]
+func ListOrganizationStaff(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	orgID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	items, err := organizationService.Staff(uid, role, orgID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+type setStaffBody struct {
+	UserID uint   `json:"user_id" binding:"required"`
+	Role   string `json:"role" enums:"owner,staff"` // default staff
+}
+
+// SetOrganizationStaff godoc
+// @Summary Add organization staff
+// @Description Owners add a user account to the organization or change its role
+// @Tags organizations
+// @Security BearerAuth
+// @Accept json
+// @Param id path int true "Organization ID"
+// @Param input body setStaffBody true "Staff account"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /organizations/{id}/staff [post]
+func SetOrganizationStaff(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	orgID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var body setStaffBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := organizationService.SetStaff(uid, role, orgID, body.UserID, body.Role); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RemoveOrganizationStaff godoc
+// @Summary Remove organization staff
+// @Description Owners remove any account, staff may leave; the last owner cannot be removed
+// @Tags organizations
+// @Security BearerAuth
+// @Param id path int true "Organization ID"
+// @Param user_id path int true "User ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /organizations/{id}/staff/{user_id} [delete]
+func RemoveOrganizationStaff(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	orgID, ok1 := uintParam(c, "id")
+	userID, ok2 := uintParam(c, "user_id")
+	if !ok1 || !ok2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := organizationService.RemoveStaff(uid, role, orgID, userID); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+type attachClubBody struct {
+	OrganizationID uint `json:"organization_id" binding:"required"`
+}
+
+// AttachClubToOrganization godoc
+// @Summary Attach club to organization
+// @Description The actor must manage the club and be staff of the organization. The club shows the verified badge while the organization is verified.
+// @Tags organizations
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param input body attachClubBody true "Organization"
+// @Success 200 {object} model.Club
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /clubs/id/{id}/organization [put]
+func AttachClubToOrganization(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var body attachClubBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	club, err := organizationService.AttachClub(uid, role, clubID, body.OrganizationID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, club)
+}
+
+// DetachClubFromOrganization godoc
+// @Summary Detach club from organization
+// @Tags organizations
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Club ID"
+// @Success 200 {object} model.Club
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /clubs/id/{id}/organization [delete]
+func DetachClubFromOrganization(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	club, err := organizationService.DetachClub(uid, role, clubID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, club)
+}
+
+// AdminListOrganizations godoc
+// @Summary List organizations for review
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "pending (default), verified, rejected or all"
+// @Success 200 {array} model.Organization
+// @Failure 403 {object} map[string]string
+// @Router /admin/organizations [get]
+func AdminListOrganizations(c *gin.Context) {
+	status := model.OrganizationStatus(c.DefaultQuery("status", string(model.OrganizationPending)))
+	if status == "all" {
+		status = ""
+	}
+	items, err := organizationService.List(status)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// VerifyOrganization godoc
+// @Summary Verify organization
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {object} model.Organization
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Not pending"
+// @Router /admin/organizations/{id}/verify [post]
+func VerifyOrganization(c *gin.Context) {
+	uid, _, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	orgID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	org, err := organizationService.Verify(uid, orgID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, org)
+}
+
+type rejectOrganizationBody struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// RejectOrganization godoc
+// @Summary Reject organization
+// @Description The reason is sent to the organization's staff; they can fix the details to request another review
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param input body rejectOrganizationBody true "Reason"
+// @Success 200 {object} model.Organization
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Not pending"
+// @Router /admin/organizations/{id}/reject [post]
+func RejectOrganization(c *gin.Context) {
+	uid, _, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	orgID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var body rejectOrganizationBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	org, err := organizationService.Reject(uid, orgID, body.Reason)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, org)
+}
+
+// ReleaseOrganization godoc
+// @Summary Release organization INN
+// @Description Rejects a pending or verified organization so that another organization can register its INN.
+// @Description Its clubs lose the verified badge; the reason is sent to the organization's staff.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param input body rejectOrganizationBody true "Reason"
+// @Success 200 {object} model.Organization
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Already rejected"
+// @Router /admin/organizations/{id}/release [post]
+func ReleaseOrganization(c *gin.Context) {
+	uid, _, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	orgID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var body rejectOrganizationBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	org, err := organizationService.Release(uid, orgID, body.Reason)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, org)
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"mosprom/api/internal/model"
+	"mosprom/api/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetClubPage godoc
+// @Summary Published club page
+// @Description Ordered content blocks of the club's published page layout
+// @Tags club-pages
+// @Produce json
+// @Param id path int true "Club ID"
+// @Success 200 {object} model.ClubPageVersion
+// @Failure 404 {object} map[string]string "No published page"
+// @Router /clubs/id/{id}/page [get]
+func GetClubPage(c *gin.Context) {
+	clubID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	page, err := clubService.PublishedPage(clubID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// GetPageBlockSchemas godoc
+// @Summary Club page block schemas
+// @Description JSON schema of the data of every block type (hero, about, pinned_posts, team, faq, partners, links, gallery)
+// @Tags club-pages
+// @Produce json
+// @Success 200 {object} map[string]object
+// @Router /clubs/page/schema [get]
+func GetPageBlockSchemas(c *gin.Context) {
+	c.JSON(http.StatusOK, clubService.PageBlockSchemas())
+}
+
+// SaveClubPageDraft godoc
+// @Summary Save club page draft
+// @Description Validates the blocks and stores them as a new draft version; missing block ids are generated
+// @Tags club-pages
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param input body service.SavePageInput true "Ordered blocks"
+// @Success 201 {object} model.ClubPageVersion
+// @Failure 400 {object} map[string]string "Invalid block"
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string "Club is archived"
+// @Router /clubs/id/{id}/page/versions [post]
+func SaveClubPageDraft(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var body service.SavePageInput
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	v, err := clubService.SavePageDraft(uid, role, clubID, body)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, v)
+}
+
+// ListClubPageVersions godoc
+// @Summary List club page versions
+// @Description Newest first, without blocks
+// @Tags club-pages
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Club ID"
+// @Success 200 {array} model.ClubPageVersion
+// @Failure 403 {object} map[string]string
+// @Router /clubs/id/{id}/page/versions [get]
+func ListClubPageVersions(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	items, err := clubService.PageVersions(uid, role, clubID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// GetClubPageVersion godoc
+// @Summary Get club page version
+// @Tags club-pages
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param version path int true "Version number"
+// @Success 200 {object} model.ClubPageVersion
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /clubs/id/{id}/page/versions/{version} [get]
+func GetClubPageVersion(c *gin.Context) {
+	withPageVersion(c, clubService.PageVersion)
+}
+
+// PublishClubPageVersion godoc
+// @Summary Publish club page version
+// @Description Replaces the published layout; publishing an older version rolls back to it
+// @Tags club-pages
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param version path int true "Version number"
+// @Success 200 {object} model.ClubPageVersion
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Club is archived"
+// @Router /clubs/id/{id}/page/versions/{version}/publish [post]
+func PublishClubPageVersion(c *gin.Context) {
+	withPageVersion(c, clubService.PublishPage)
+}
+
+func withPageVersion(c *gin.Context, fn func(actorID uint, role string, clubID uint, version int) (model.ClubPageVersion, error)) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok := uintParam(c, "id")
+	version, err := strconv.Atoi(c.Param("version"))
+	if !ok || err != nil || version < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	v, err := fn(uid, role, clubID, version)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, v)
+}
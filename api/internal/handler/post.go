@@ -1,10 +1,12 @@
 package handler
 
 import (
+	"errors"
 	"mosprom/api/internal/model"
 	"mosprom/api/internal/service"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -37,45 +39,112 @@ func isValidPostFormat(postFormat model.PostFormat) bool {
 	}
 }
 
-// JoinPostRequest represents the request body for joining a post
+// JoinPostRequest represents the request body for joining a post; the user is the token's
 type JoinPostRequest struct {
 	PostID uint `json:"post_id" binding:"required"`
-	UserID uint `json:"user_id" binding:"required"`
+	// Series joins every upcoming occurrence of a recurring post, including ones created later
+	Series bool `json:"series"`
+	service.RegistrationInput
 }
 
-// Join allows a user to join a post (e.g., activity)
+// Join lets the current user join a post (e.g., activity)
 // @Summary User joins a post
+// @Description When the post is at capacity the user is put on its waitlist; see waitlist_position in the response.
+// @Description Posts with registration_mode approval file a pending registration (motivation and answers) instead.
+// @Description With series true the user joins all upcoming occurrences of a recurring post (open registration only).
 // @Tags posts
 // @Accept json
 // @Produce json
-// @Param input body handler.JoinPostRequest true "Post and User IDs"
-// @Success 200 {object} model.Post
+// @Param input body handler.JoinPostRequest true "Post ID"
+// @Success 200 {object} service.PostDTO
+// @Success 202 {object} model.PostRegistration "Registration awaits the organizers' decision"
 // @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string "Too young for the post, or no birth date set (code age_restricted)"
+// @Failure 409 {object} map[string]string "Club is archived, registration pending or already taking part"
 // @Router /posts/join [post]
 // @Security BearerAuth
 func (h *PostHandler) Join(c *gin.Context) {
+	uid, _, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
 	var body JoinPostRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	post, err := h.postService.Join(body.UserID, body.PostID)
+	if body.Series {
+		post, err := h.postService.JoinSeries(uid, body.PostID)
+		if err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		h.writePost(c, http.StatusOK, post, uid)
+		return
+	}
+	post, reg, err := h.postService.Join(uid, body.PostID, body.RegistrationInput)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeServiceError(c, err)
+		return
+	}
+	if reg != nil {
+		c.JSON(http.StatusAccepted, reg)
+		return
+	}
+	h.writePost(c, http.StatusOK, post, uid)
+}
+
+// Leave removes the current user from a post's participants or waitlist
+// @Summary Leave a post
+// @Description Cancels participation or a waitlist place. A freed place goes to the first user on the waitlist.
+// @Description With series true the user leaves the whole series of a recurring post and its upcoming occurrences.
+// @Tags posts
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Post ID"
+// @Param series query bool false "Leave the whole series"
+// @Success 200 {object} service.PostDTO
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string "Not a participant or waitlisted"
+// @Failure 409 {object} map[string]string "Attendance already recorded"
+// @Router /posts/{id}/join [delete]
+func (h *PostHandler) Leave(c *gin.Context) {
+	uid, _, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	postID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	series, _ := strconv.ParseBool(c.Query("series"))
+	leave := h.postService.Leave
+	if series {
+		leave = h.postService.LeaveSeries
+	}
+	post, err := leave(uid, postID)
+	if err != nil {
+		writeServiceError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, post)
+	h.writePost(c, http.StatusOK, post, uid)
 }
 
 // CreatePost creates a new post
 // @Summary Create a new post
-// @Description Create a new post with the provided details
+// @Description Create a new post with the provided details. Activity and educational posts may recur: each occurrence
+// @Description of the recurrence rule is created as its own post, and the first one is returned.
 // @Tags posts
 // @Accept json
 // @Produce json
 // @Param post body service.CreatePostInput true "Post details"
-// @Success 201 {object} model.Post
+// @Success 201 {object} service.PostDTO
 // @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string "Club is archived"
 // @Failure 500 {object} map[string]string
 // @Router /posts [post]
 func (h *PostHandler) CreatePost(c *gin.Context) {
@@ -99,11 +168,12 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 
 	post, err := h.postService.CreatePost(input)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, post)
+	uid, _, _ := currentUser(c)
+	h.writePost(c, http.StatusCreated, post, uid)
 }
 
 // GetPostByID retrieves a post by its ID
@@ -112,7 +182,7 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 // @Tags posts
 // @Produce json
 // @Param id path int true "Post ID"
-// @Success 200 {object} model.Post
+// @Success 200 {object} service.PostDTO
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
@@ -130,17 +200,32 @@ func (h *PostHandler) GetPostByID(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
 		return
 	}
-
-	c.JSON(http.StatusOK, post)
+	uid, _, _ := currentUser(c)
+	h.postService.RecordPostView(post.ID, uid, c.ClientIP())
+	h.writePost(c, http.StatusOK, post, uid)
 }
 
-// GetPostsByClubID retrieves all posts for a specific club
+// GetPostsByClubID retrieves posts hosted or co-hosted by a club
 // @Summary Get posts by club ID
-// @Description Get all posts for a specific club
+// @Description Posts hosted or co-hosted by the club; accepts the same filters, sorting and pagination as GET /posts
 // @Tags posts
 // @Produce json
 // @Param club_id query int true "Club ID"
-// @Success 200 {array} model.Post
+// @Param type query string false "Comma-separated post types"
+// @Param format query string false "in_person or online"
+// @Param technologies query string false "Comma-separated technology names, any matches"
+// @Param directions query string false "Comma-separated direction names, any matches"
+// @Param from query string false "Events running on or after (RFC3339 or YYYY-MM-DD)"
+// @Param to query string false "Events starting on or before (RFC3339 or YYYY-MM-DD)"
+// @Param upcoming query bool false "Only events that have not finished"
+// @Param eligible query bool false "Only posts the current user is old enough for; otherwise age_eligible flags them"
+// @Param age query int false "Only posts open to users of this age"
+// @Param sort query string false "created (default), start or popular"
+// @Param cursor query string false "X-Next-Cursor of the previous page"
+// @Param limit query int false "Page size, default 20, max 100"
+// @Success 200 {array} service.PostDTO
+// @Header 200 {integer} X-Total-Count "Number of matching posts"
+// @Header 200 {string} X-Next-Cursor "Cursor of the next page, absent on the last page"
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /posts/club [get]
@@ -151,32 +236,128 @@ func (h *PostHandler) GetPostsByClubID(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid club ID"})
 		return
 	}
-
-	posts, err := h.postService.GetPostsByClubID(uint(clubID))
+	q, err := postListQuery(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-
-	c.JSON(http.StatusOK, posts)
+	id := uint(clubID)
+	q.ClubID = &id
+	h.writePostPage(c, q)
 }
 
-// GetAllPosts retrieves all posts
-// @Summary Get all posts
-// @Description Get all posts
+// GetAllPosts lists posts
+// @Summary List posts
+// @Description Filtered, sorted and keyset-paginated posts. Follow X-Next-Cursor for the next page; X-Total-Count is the number of posts matching the filters.
 // @Tags posts
 // @Produce json
-// @Success 200 {array} model.Post
+// @Param club_id query int false "Hosted or co-hosted by the club"
+// @Param type query string false "Comma-separated post types"
+// @Param format query string false "in_person or online"
+// @Param technologies query string false "Comma-separated technology names, any matches"
+// @Param directions query string false "Comma-separated direction names, any matches"
+// @Param from query string false "Events running on or after (RFC3339 or YYYY-MM-DD)"
+// @Param to query string false "Events starting on or before (RFC3339 or YYYY-MM-DD)"
+// @Param upcoming query bool false "Only events that have not finished"
+// @Param eligible query bool false "Only posts the current user is old enough for; otherwise age_eligible flags them"
+// @Param age query int false "Only posts open to users of this age"
+// @Param sort query string false "created (default), start or popular"
+// @Param cursor query string false "X-Next-Cursor of the previous page"
+// @Param limit query int false "Page size, default 20, max 100"
+// @Success 200 {array} service.PostDTO
+// @Header 200 {integer} X-Total-Count "Number of matching posts"
+// @Header 200 {string} X-Next-Cursor "Cursor of the next page, absent on the last page"
+// @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /posts [get]
 func (h *PostHandler) GetAllPosts(c *gin.Context) {
-	posts, err := h.postService.GetAllPosts()
+	q, err := postListQuery(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if v := c.Query("club_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid club ID"})
+			return
+		}
+		clubID := uint(id)
+		q.ClubID = &clubID
+	}
+	h.writePostPage(c, q)
+}
 
-	c.JSON(http.StatusOK, posts)
+// postListQuery parses the filter, sort and pagination parameters shared by post listings
+func postListQuery(c *gin.Context) (service.PostListQuery, error) {
+	viewerID, _, _ := currentUser(c)
+	q := service.PostListQuery{
+		ViewerID:     viewerID,
+		Technologies: commaList(c.Query("technologies")),
+		Directions:   commaList(c.Query("directions")),
+		Sort:         c.Query("sort"),
+		Cursor:       c.Query("cursor"),
+	}
+	for _, t := range commaList(c.Query("type")) {
+		q.Types = append(q.Types, model.PostType(t))
+	}
+	if v := c.Query("format"); v != "" {
+		f := model.PostFormat(v)
+		q.Format = &f
+	}
+	var err error
+	if q.From, err = timeQuery(c, "from", false); err != nil {
+		return q, errors.New("invalid from")
+	}
+	if q.To, err = timeQuery(c, "to", true); err != nil {
+		return q, errors.New("invalid to")
+	}
+	if v := c.Query("upcoming"); v != "" {
+		if q.Upcoming, err = strconv.ParseBool(v); err != nil {
+			return q, errors.New("invalid upcoming")
+		}
+	}
+	if v := c.Query("eligible"); v != "" {
+		if q.EligibleOnly, err = strconv.ParseBool(v); err != nil {
+			return q, errors.New("invalid eligible")
+		}
+	}
+	if v := c.Query("age"); v != "" {
+		age, err := strconv.Atoi(v)
+		if err != nil || age < 0 {
+			return q, errors.New("invalid age")
+		}
+		q.Age = &age
+	}
+	if v := c.Query("limit"); v != "" {
+		if q.Limit, err = strconv.Atoi(v); err != nil {
+			return q, errors.New("invalid limit")
+		}
+	}
+	return q, nil
+}
+
+func (h *PostHandler) writePostPage(c *gin.Context, q service.PostListQuery) {
+	page, err := h.postService.ListPosts(q)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Header("X-Total-Count", strconv.FormatInt(page.Total, 10))
+	if page.NextCursor != "" {
+		c.Header("X-Next-Cursor", page.NextCursor)
+	}
+	c.JSON(http.StatusOK, page.Posts)
+}
+
+func commaList(v string) []string {
+	var out []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
 }
 
 // JoinedByMe lists posts the current authorized user joined
@@ -184,7 +365,7 @@ func (h *PostHandler) GetAllPosts(c *gin.Context) {
 // @Tags posts
 // @Security BearerAuth
 // @Produce json
-// @Success 200 {array} model.Post
+// @Success 200 {array} service.PostDTO
 // @Failure 401 {object} map[string]string
 // @Router /me/posts [get]
 func (h *PostHandler) JoinedByMe(c *gin.Context) {
@@ -194,12 +375,7 @@ func (h *PostHandler) JoinedByMe(c *gin.Context) {
 		return
 	}
 	uid := uidAny.(uint)
-	posts, err := h.postService.JoinedPosts(uid)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-	c.JSON(http.StatusOK, posts)
+	h.writeJoinedPosts(c, uid, uid)
 }
 
 // JoinedByUser lists posts a given user joined
@@ -207,7 +383,7 @@ func (h *PostHandler) JoinedByMe(c *gin.Context) {
 // @Tags posts
 // @Produce json
 // @Param id path int true "User ID"
-// @Success 200 {array} model.Post
+// @Success 200 {array} service.PostDTO
 // @Failure 400 {object} map[string]string
 // @Router /users/{id}/posts [get]
 func (h *PostHandler) JoinedByUser(c *gin.Context) {
@@ -217,12 +393,8 @@ func (h *PostHandler) JoinedByUser(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
 		return
 	}
-	posts, err := h.postService.JoinedPosts(uint(id))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-	c.JSON(http.StatusOK, posts)
+	viewerID, _, _ := currentUser(c)
+	h.writeJoinedPosts(c, uint(id), viewerID)
 }
 
 // UpdatePost updates an existing post
@@ -233,9 +405,10 @@ func (h *PostHandler) JoinedByUser(c *gin.Context) {
 // @Produce json
 // @Param id path int true "Post ID"
 // @Param post body service.UpdatePostInput true "Post details"
-// @Success 200 {object} model.Post
+// @Success 200 {object} service.PostDTO
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /posts/{id} [put]
 // @Security BearerAuth
@@ -267,11 +440,12 @@ func (h *PostHandler) UpdatePost(c *gin.Context) {
 
 	post, err := h.postService.UpdatePost(input)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		writeServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, post)
+	uid, _, _ := currentUser(c)
+	h.writePost(c, http.StatusOK, post, uid)
 }
 
 // DeletePost deletes a post by its ID
@@ -283,7 +457,7 @@ func (h *PostHandler) UpdatePost(c *gin.Context) {
 // @Success 204
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Router /posts/{id} [delete]
 // @Security BearerAuth
 func (h *PostHandler) DeletePost(c *gin.Context) {
@@ -295,7 +469,7 @@ func (h *PostHandler) DeletePost(c *gin.Context) {
 	}
 
 	if err := h.postService.DeletePost(uint(id)); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		writeServiceError(c, err)
 		return
 	}
 
@@ -319,6 +493,7 @@ type PostTechnologiesRequest struct {
 // @Param input body handler.PostTechnologiesRequest true "Technologies"
 // @Success 200 {array} model.Technology
 // @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Router /posts/{id}/technologies [post]
 func (h *PostHandler) SetTechnologies(c *gin.Context) {
 	idParam := c.Param("id")
@@ -334,7 +509,7 @@ func (h *PostHandler) SetTechnologies(c *gin.Context) {
 	}
 	techs, err := h.postService.SetPostTechnologies(uint(id), body.Technologies)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeServiceError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, techs)
@@ -372,7 +547,7 @@ func (h *PostHandler) GetTechnologies(c *gin.Context) {
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Router /posts/{id}/like [post]
 // @Security BearerAuth
 func (h *PostHandler) LikePost(c *gin.Context) {
@@ -400,7 +575,7 @@ func (h *PostHandler) LikePost(c *gin.Context) {
 
 	// Add like to post
 	if err := h.postService.LikePost(uint(postID), userIDUint); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeServiceError(c, err)
 		return
 	}
 
@@ -453,11 +628,14 @@ func (h *PostHandler) UnlikePost(c *gin.Context) {
 
 // GetPostParticipants retrieves participants for a post
 // @Summary Get participants for a post
-// @Description Get participants for a post by post ID
+// @Description Participants ordered by user id. Pass X-Next-Cursor as after for the next page.
 // @Tags posts
 // @Produce json
 // @Param id path int true "Post ID"
+// @Param after query int false "Return participants with a greater user id"
+// @Param limit query int false "Page size, default 50, max 200"
 // @Success 200 {array} model.User
+// @Header 200 {string} X-Next-Cursor "Value of after for the next page, absent on the last page"
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
@@ -470,7 +648,14 @@ func (h *PostHandler) GetPostParticipants(c *gin.Context) {
 		return
 	}
 
-	users, err := h.postService.GetPostParticipants(uint(id))
+	after, err := strconv.ParseUint(c.DefaultQuery("after", "0"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid after"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	users, err := h.postService.GetPostParticipants(uint(id), uint(after), limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -479,6 +664,9 @@ func (h *PostHandler) GetPostParticipants(c *gin.Context) {
 	for i := range users {
 		users[i].Password = ""
 	}
+	if n := len(users); n > 0 && n == h.postService.ParticipantsPageSize(limit) {
+		c.Header("X-Next-Cursor", strconv.FormatUint(uint64(users[n-1].ID), 10))
+	}
 
 	c.JSON(http.StatusOK, users)
 }
@@ -544,3 +732,54 @@ func (h *PostHandler) RecommendedPostsForMe(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, res)
 }
+
+// Feed returns the current user's activity feed
+// @Summary My activity feed
+// @Description New posts of subscribed (and co-hosting) clubs merged with technology-matched and trending posts, ranked by recency, technology match and engagement. Pass next_cursor to get the following page; pages of one scroll are ranked as of the first request, so items are neither repeated nor skipped.
+// @Tags posts
+// @Security BearerAuth
+// @Produce json
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Page size, default 20, max 50"
+// @Success 200 {object} service.FeedPage
+// @Failure 400 {object} map[string]string "Invalid cursor"
+// @Failure 401 {object} map[string]string
+// @Router /me/feed [get]
+func (h *PostHandler) Feed(c *gin.Context) {
+	uid, _, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	page, err := h.postService.Feed(uid, c.Query("cursor"), limit)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// writePost responds with the post as seen by viewerID (0 for anonymous)
+func (h *PostHandler) writePost(c *gin.Context, status int, post model.Post, viewerID uint) {
+	dto, err := h.postService.DTO(post, viewerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(status, dto)
+}
+
+func (h *PostHandler) writeJoinedPosts(c *gin.Context, userID, viewerID uint) {
+	posts, err := h.postService.JoinedPosts(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	dtos, err := h.postService.DTOs(posts, viewerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dtos)
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"mosprom/api/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetRegistrationQuestions godoc
+// @Summary Set registration questions of a post
+// @Description Replaces the questions applicants answer when the post's registration_mode is approval; managers of the host club
+// @Tags registrations
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Post ID"
+// @Param input body []service.RegistrationQuestionInput true "Questions in display order"
+// @Success 200 {array} model.PostRegistrationQuestion
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /posts/{id}/registration-questions [put]
+func SetRegistrationQuestions(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	postID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var body []service.RegistrationQuestionInput
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	questions, err := clubService.SetRegistrationQuestions(uid, role, postID, body)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, questions)
+}
+
+// ListPostRegistrations godoc
+// @Summary List registrations of a post
+// @Description Oldest first, the review queue for managers of the host club
+// @Tags registrations
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Post ID"
+// @Param status query string false "pending, approved or rejected (default: all)"
+// @Success 200 {array} model.PostRegistration
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /posts/{id}/registrations [get]
+func ListPostRegistrations(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	postID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	items, err := clubService.PostRegistrations(uid, role, postID, c.Query("status"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// ApprovePostRegistration godoc
+// @Summary Approve a registration
+// @Description Joins the applicant, or puts them on the waitlist when the post is at capacity
+// @Tags registrations
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Post ID"
+// @Param registration_id path int true "Registration ID"
+// @Success 200 {object} model.PostRegistration
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Already decided"
+// @Router /posts/{id}/registrations/{registration_id}/approve [post]
+func ApprovePostRegistration(c *gin.Context) {
+	decidePostRegistration(c, true)
+}
+
+// RejectPostRegistration godoc
+// @Summary Reject a registration
+// @Description The applicant is notified with the reason and may register again
+// @Tags registrations
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Post ID"
+// @Param registration_id path int true "Registration ID"
+// @Param input body moderationRequest false "Reason"
+// @Success 200 {object} model.PostRegistration
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Already decided"
+// @Router /posts/{id}/registrations/{registration_id}/reject [post]
+func RejectPostRegistration(c *gin.Context) {
+	decidePostRegistration(c, false)
+}
+
+func decidePostRegistration(c *gin.Context, approve bool) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	postID, ok1 := uintParam(c, "id")
+	registrationID, ok2 := uintParam(c, "registration_id")
+	if !ok1 || !ok2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var body moderationRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	reg, err := clubService.DecideRegistration(uid, role, postID, registrationID, approve, body.Reason)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, reg)
+}
+
+// MyRegistrations lists the current user's post registrations
+// @Summary List my registrations
+// @Tags registrations
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} model.PostRegistration
+// @Failure 401 {object} map[string]string
+// @Router /me/registrations [get]
+func (h *PostHandler) MyRegistrations(c *gin.Context) {
+	uid, _, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	items, err := h.postService.MyRegistrations(uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPostOccurrences godoc
+// @Summary Upcoming occurrences of a recurring post
+// @Description Occurrences of the post's series that have not ended, by start; each is a post of its own
+// @Tags posts
+// @Produce json
+// @Param id path int true "Post ID (any occurrence)"
+// @Success 200 {array} service.PostDTO
+// @Failure 400 {object} map[string]string "Not a recurring post"
+// @Failure 404 {object} map[string]string
+// @Router /posts/{id}/occurrences [get]
+func (h *PostHandler) GetPostOccurrences(c *gin.Context) {
+	postID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	posts, err := h.postService.Occurrences(postID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	uid, _, _ := currentUser(c)
+	dtos, err := h.postService.DTOs(posts, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dtos)
+}
+
+// CancelPostSeries godoc
+// @Summary Cancel a recurring post
+// @Description Stops the series: no more occurrences are created and upcoming ones are deleted and published as cancelled in calendars.
+// @Description A single occurrence is edited or cancelled with PUT or DELETE /posts/{id}. Managers of the host club.
+// @Tags posts
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Post ID (any occurrence)"
+// @Success 200 {object} map[string][]uint "cancelled_post_ids"
+// @Failure 400 {object} map[string]string "Not a recurring post"
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Series already cancelled"
+// @Router /posts/{id}/series [delete]
+func (h *PostHandler) CancelPostSeries(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	postID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	ids, err := h.postService.CancelSeries(uid, role, postID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	if ids == nil {
+		ids = []uint{}
+	}
+	c.JSON(http.StatusOK, gin.H{"cancelled_post_ids": ids})
+}
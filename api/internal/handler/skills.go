@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"mosprom/api/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var skillsService = service.NewSkillsService()
+
+// GetSkillsGap godoc
+// @Summary Skills-gap analytics
+// @Description Compares demand (technologies of vacancy, internship and project posts created in the range) with supply (technologies users have), grouped by direction, with per-period trends. Dates are RFC3339 or YYYY-MM-DD.
+// @Tags analytics
+// @Produce json
+// @Produce text/csv
+// @Param from query string false "Range start (default: 90 days before to)"
+// @Param to query string false "Range end (default: now)"
+// @Param interval query string false "Trend bucket: day, week or month (default)"
+// @Param direction query string false "Only this direction"
+// @Param format query string false "json (default) or csv"
+// @Success 200 {object} service.SkillsGapReport
+// @Failure 400 {object} map[string]string
+// @Router /analytics/skills-gap [get]
+func GetSkillsGap(c *gin.Context) {
+	q := service.SkillsGapQuery{Interval: c.Query("interval"), Direction: c.Query("direction")}
+	var err error
+	if q.From, err = timeQuery(c, "from", false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+		return
+	}
+	if q.To, err = timeQuery(c, "to", true); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+		return
+	}
+	report, err := skillsService.SkillsGap(q)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if c.Query("format") == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="skills-gap.csv"`)
+		c.Status(http.StatusOK)
+		if err := service.WriteSkillsGapCSV(c.Writer, report); err != nil {
+			c.Error(err)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"mosprom/api/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var webhookService = service.NewWebhookService()
+
+// CreateClubWebhook godoc
+// @Summary Create club webhook
+// @Description Subscribes a URL to club events. Empty event_types means all events. The response contains the HMAC secret; it is not shown again.
+// @Description Requests carry X-SkillBridge-Signature: sha256=hex(HMAC-SHA256(secret, X-SkillBridge-Timestamp + "." + body)).
+// @Tags webhooks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param input body service.CreateWebhookInput true "Webhook"
+// @Success 201 {object} service.CreatedWebhook
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /clubs/id/{id}/webhooks [post]
+func CreateClubWebhook(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var body service.CreateWebhookInput
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	w, err := webhookService.Create(uid, role, clubID, body)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, w)
+}
+
+// ListClubWebhooks godoc
+// @Summary List club webhooks
+// @Tags webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Club ID"
+// @Success 200 {array} model.Webhook
+// @Failure 403 {object} map[string]string
+// @Router /clubs/id/{id}/webhooks [get]
+func ListClubWebhooks(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok := uintParam(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	items, err := webhookService.List(uid, role, clubID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// DeleteClubWebhook godoc
+// @Summary Delete club webhook
+// @Tags webhooks
+// @Security BearerAuth
+// @Param id path int true "Club ID"
+// @Param webhook_id path int true "Webhook ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /clubs/id/{id}/webhooks/{webhook_id} [delete]
+func DeleteClubWebhook(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok1 := uintParam(c, "id")
+	webhookID, ok2 := uintParam(c, "webhook_id")
+	if !ok1 || !ok2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := webhookService.Delete(uid, role, clubID, webhookID); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListWebhookDeliveries godoc
+// @Summary Webhook delivery log
+// @Description Newest 100 deliveries with status, attempts and the last response
+// @Tags webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param webhook_id path int true "Webhook ID"
+// @Success 200 {array} model.WebhookDelivery
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /clubs/id/{id}/webhooks/{webhook_id}/deliveries [get]
+func ListWebhookDeliveries(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok1 := uintParam(c, "id")
+	webhookID, ok2 := uintParam(c, "webhook_id")
+	if !ok1 || !ok2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	items, err := webhookService.Deliveries(uid, role, clubID, webhookID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// ReplayWebhookDelivery godoc
+// @Summary Replay a webhook delivery
+// @Description Sends the payload of an earlier delivery again as a new delivery
+// @Tags webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Club ID"
+// @Param webhook_id path int true "Webhook ID"
+// @Param delivery_id path int true "Delivery ID"
+// @Success 202 {object} model.WebhookDelivery
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /clubs/id/{id}/webhooks/{webhook_id}/deliveries/{delivery_id}/replay [post]
+func ReplayWebhookDelivery(c *gin.Context) {
+	uid, role, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	clubID, ok1 := uintParam(c, "id")
+	webhookID, ok2 := uintParam(c, "webhook_id")
+	deliveryID, ok3 := uintParam(c, "delivery_id")
+	if !ok1 || !ok2 || !ok3 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	d, err := webhookService.Replay(uid, role, clubID, webhookID, deliveryID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, d)
+}
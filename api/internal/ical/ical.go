@@ -0,0 +1,138 @@
+// Package ical writes iCalendar (RFC 5545) documents for post calendars
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+// Event is one VEVENT. UID stays the same across versions of an event; clients apply the version
+// with the highest Sequence.
+type Event struct {
+	UID          string
+	Sequence     int
+	Status       string
+	Summary      string
+	Description  string
+	Location     string
+	URL          string
+	Start        time.Time
+	End          *time.Time // nil for events without an end
+	Created      time.Time
+	LastModified time.Time
+}
+
+// Calendar is a VCALENDAR published by the server
+type Calendar struct {
+	Name    string
+	Refresh time.Duration // suggested polling interval for subscribed feeds, 0 to omit
+	Events  []Event
+}
+
+const productID = "-//mosprom//clubs//EN"
+
+// Encode writes the calendar with CRLF line endings and long lines folded
+func (c Calendar) Encode(w io.Writer, now time.Time) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeFolded(bw, name+":"+value)
+	}
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", productID)
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		line("X-WR-CALNAME", escape(c.Name))
+	}
+	if c.Refresh > 0 {
+		d := formatDuration(c.Refresh)
+		line("REFRESH-INTERVAL;VALUE=DURATION", d)
+		line("X-PUBLISHED-TTL", d)
+	}
+	stamp := formatTime(now)
+	for _, e := range c.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("DTSTAMP", stamp)
+		line("SEQUENCE", strconv.Itoa(e.Sequence))
+		line("DTSTART", formatTime(e.Start))
+		if e.End != nil && e.End.After(e.Start) {
+			line("DTEND", formatTime(*e.End))
+		}
+		line("SUMMARY", escape(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escape(e.Description))
+		}
+		if e.Location != "" {
+			line("LOCATION", escape(e.Location))
+		}
+		if e.URL != "" {
+			line("URL", e.URL)
+		}
+		status := e.Status
+		if status == "" {
+			status = StatusConfirmed
+		}
+		line("STATUS", status)
+		if !e.Created.IsZero() {
+			line("CREATED", formatTime(e.Created))
+		}
+		if !e.LastModified.IsZero() {
+			line("LAST-MODIFIED", formatTime(e.LastModified))
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// formatDuration renders whole minutes as an RFC 5545 duration, e.g. PT1H or PT90M
+func formatDuration(d time.Duration) string {
+	m := int(d / time.Minute)
+	if m <= 0 {
+		m = 1
+	}
+	if m%60 == 0 {
+		return "PT" + strconv.Itoa(m/60) + "H"
+	}
+	return "PT" + strconv.Itoa(m) + "M"
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// escape escapes a TEXT value
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+// writeFolded writes a content line, folding it into lines of at most 75 octets without
+// splitting UTF-8 characters
+func writeFolded(w *bufio.Writer, s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		limit = 74 // continuation lines start with a space
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
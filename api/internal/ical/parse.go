@@ -0,0 +1,254 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Property is a content line of a parsed component; Value is raw, TEXT values still escaped
+type Property struct {
+	Name   string // upper case
+	Params map[string]string
+	Value  string
+}
+
+// Component is a parsed VEVENT; nested components such as VALARM are skipped
+type Component struct {
+	Props []Property
+}
+
+// Get returns the first property with the name, or nil
+func (c Component) Get(name string) *Property {
+	name = strings.ToUpper(name)
+	for i := range c.Props {
+		if c.Props[i].Name == name {
+			return &c.Props[i]
+		}
+	}
+	return nil
+}
+
+// Text returns the unescaped value of a TEXT property, joining repeated properties with commas
+func (c Component) Text(name string) string {
+	name = strings.ToUpper(name)
+	var parts []string
+	for _, p := range c.Props {
+		if p.Name == name {
+			parts = append(parts, Unescape(p.Value))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Parse reads the VEVENTs of an iCalendar document
+func Parse(r io.Reader) ([]Component, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		events  []Component
+		current *Component
+		depth   int // nesting inside the current VEVENT
+		seenCal bool
+	)
+	for n, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		p, err := parseLine(l)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+		switch p.Name {
+		case "BEGIN":
+			v := strings.ToUpper(p.Value)
+			if v == "VCALENDAR" {
+				seenCal = true
+			}
+			if current != nil {
+				depth++
+			} else if v == "VEVENT" {
+				current = &Component{}
+			}
+			continue
+		case "END":
+			if current != nil {
+				if depth > 0 {
+					depth--
+				} else {
+					events = append(events, *current)
+					current = nil
+				}
+			}
+			continue
+		}
+		if current != nil && depth == 0 {
+			current.Props = append(current.Props, p)
+		}
+	}
+	if !seenCal {
+		return nil, errors.New("not an iCalendar file")
+	}
+	if current != nil {
+		return nil, errors.New("unterminated VEVENT")
+	}
+	return events, nil
+}
+
+// unfold joins folded content lines and strips a UTF-8 BOM
+func unfold(r io.Reader) ([]string, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	var lines []string
+	first := true
+	for sc.Scan() {
+		l := strings.TrimRight(sc.Text(), "\r")
+		if first {
+			l = strings.TrimPrefix(l, "\uFEFF")
+			first = false
+		}
+		if len(lines) > 0 && (strings.HasPrefix(l, " ") || strings.HasPrefix(l, "\t")) {
+			lines[len(lines)-1] += l[1:]
+			continue
+		}
+		lines = append(lines, l)
+	}
+	return lines, sc.Err()
+}
+
+// parseLine splits "NAME;PARAM=value;PARAM="quoted":VALUE"
+func parseLine(l string) (Property, error) {
+	inQuote := false
+	colon := -1
+	for i := 0; i < len(l); i++ {
+		switch l[i] {
+		case '"':
+			inQuote = !inQuote
+		case ':':
+			if !inQuote {
+				colon = i
+			}
+		}
+		if colon >= 0 {
+			break
+		}
+	}
+	if colon < 0 {
+		return Property{}, errors.New("missing ':'")
+	}
+	head, value := l[:colon], l[colon+1:]
+	parts := splitUnquoted(head, ';')
+	p := Property{Name: strings.ToUpper(parts[0]), Value: value}
+	for _, param := range parts[1:] {
+		k, v, _ := strings.Cut(param, "=")
+		if p.Params == nil {
+			p.Params = map[string]string{}
+		}
+		p.Params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+	return p, nil
+}
+
+func splitUnquoted(s string, sep byte) []string {
+	var out []string
+	inQuote := false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			inQuote = !inQuote
+		case sep:
+			if !inQuote {
+				out = append(out, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(out, s[start:])
+}
+
+var unescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+// Unescape decodes a TEXT value
+func Unescape(s string) string {
+	return unescaper.Replace(s)
+}
+
+// Time parses a DATE or DATE-TIME property. UTC values end in Z; TZID names an IANA zone, and
+// floating values or unknown zones (e.g. Windows names from Outlook) are read in loc.
+// allDay is set for VALUE=DATE.
+func (p Property) Time(loc *time.Location) (t time.Time, allDay bool, err error) {
+	v := strings.TrimSpace(p.Value)
+	if p.Params["VALUE"] == "DATE" || len(v) == len("20060102") {
+		t, err = time.ParseInLocation("20060102", v, loc)
+		return t, true, err
+	}
+	if strings.HasSuffix(v, "Z") {
+		t, err = time.Parse("20060102T150405Z", v)
+		return t, false, err
+	}
+	if tz := p.Params["TZID"]; tz != "" {
+		if l, lerr := time.LoadLocation(strings.TrimPrefix(tz, "/")); lerr == nil {
+			loc = l
+		}
+	}
+	t, err = time.ParseInLocation("20060102T150405", v, loc)
+	return t, false, err
+}
+
+// ParseDuration parses a DURATION value such as PT1H30M, P1D or P2W
+func ParseDuration(s string) (time.Duration, error) {
+	v := strings.ToUpper(strings.TrimSpace(s))
+	sign := time.Duration(1)
+	if strings.HasPrefix(v, "-") {
+		sign = -1
+	}
+	v = strings.TrimLeft(v, "+-")
+	if !strings.HasPrefix(v, "P") || len(v) < 3 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	var d time.Duration
+	inTime := false
+	n := 0
+	digits := false
+	for _, r := range v[1:] {
+		switch {
+		case r >= '0' && r <= '9':
+			n = n*10 + int(r-'0')
+			digits = true
+			continue
+		case r == 'T':
+			inTime = true
+			continue
+		}
+		if !digits {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		var unit time.Duration
+		switch {
+		case r == 'W' && !inTime:
+			unit = 7 * 24 * time.Hour
+		case r == 'D' && !inTime:
+			unit = 24 * time.Hour
+		case r == 'H' && inTime:
+			unit = time.Hour
+		case r == 'M' && inTime:
+			unit = time.Minute
+		case r == 'S' && inTime:
+			unit = time.Second
+		default:
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		d += time.Duration(n) * unit
+		n, digits = 0, false
+	}
+	if digits {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return sign * d, nil
+}
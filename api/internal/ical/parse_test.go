@@ -0,0 +1,141 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleCalendar = "\uFEFFBEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:first@example.com\r\n" +
+	"SUMMARY:Go meetup\\, spring\r\n" +
+	"DESCRIPTION:Talks and pizza.\\nBring a laptop\\; we code\r\n" +
+	" \tlive.\r\n" +
+	"DTSTART;TZID=\"UTC\":20260105T180000\r\n" +
+	"LOCATION;ALTREP=\"https://maps.example.com/a:b\":Room 1\r\n" +
+	"CATEGORIES:Go,Postgres\r\n" +
+	"CATEGORIES:Docker\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"ACTION:DISPLAY\r\n" +
+	"DESCRIPTION:Reminder\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"\r\n" +
+	"begin:vevent\r\n" +
+	"uid:second@example.com\r\n" +
+	"summary:Hackathon\r\n" +
+	"end:vevent\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParse(t *testing.T) {
+	events, err := Parse(strings.NewReader(sampleCalendar))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	e := events[0]
+	for _, tc := range []struct{ name, want string }{
+		{"uid", "first@example.com"},
+		{"SUMMARY", "Go meetup, spring"},
+		// folded with a space and continued with a tab; the VALARM description is skipped
+		{"DESCRIPTION", "Talks and pizza.\nBring a laptop; we code\tlive."},
+		{"LOCATION", "Room 1"},
+		{"CATEGORIES", "Go,Postgres,Docker"},
+		{"ACTION", ""},
+	} {
+		if got := e.Text(tc.name); got != tc.want {
+			t.Errorf("Text(%s) = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+	if p := e.Get("location"); p == nil || p.Params["ALTREP"] != "https://maps.example.com/a:b" {
+		t.Errorf("LOCATION = %+v, want ALTREP with a colon", p)
+	}
+	if p := e.Get("DTSTART"); p == nil || p.Params["TZID"] != "UTC" || p.Value != "20260105T180000" {
+		t.Errorf("DTSTART = %+v", p)
+	}
+	if e.Get("X-MISSING") != nil {
+		t.Error("Get of a missing property is not nil")
+	}
+	if got := events[1].Text("SUMMARY"); got != "Hackathon" {
+		t.Errorf("second event SUMMARY = %q, want Hackathon", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, doc := range []string{
+		"",
+		"BEGIN:VEVENT\r\nSUMMARY:x\r\nEND:VEVENT\r\n",
+		"BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nSUMMARY:x\r\n",
+		"BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nSUMMARY x\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n",
+	} {
+		if _, err := Parse(strings.NewReader(doc)); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", doc)
+		}
+	}
+}
+
+func TestPropertyTime(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	for _, tc := range []struct {
+		name   string
+		prop   Property
+		want   time.Time
+		allDay bool
+	}{
+		{"utc", Property{Value: "20260105T150000Z"}, time.Date(2026, 1, 5, 15, 0, 0, 0, time.UTC), false},
+		{"floating", Property{Value: "20260105T180000"}, time.Date(2026, 1, 5, 18, 0, 0, 0, msk), false},
+		{"tzid", Property{Params: map[string]string{"TZID": "UTC"}, Value: "20260105T180000"}, time.Date(2026, 1, 5, 18, 0, 0, 0, time.UTC), false},
+		{"tzid with a slash", Property{Params: map[string]string{"TZID": "/UTC"}, Value: "20260105T180000"}, time.Date(2026, 1, 5, 18, 0, 0, 0, time.UTC), false},
+		{"unknown tzid", Property{Params: map[string]string{"TZID": "Russian Standard Time"}, Value: "20260105T180000"}, time.Date(2026, 1, 5, 18, 0, 0, 0, msk), false},
+		{"date", Property{Value: "20260105"}, time.Date(2026, 1, 5, 0, 0, 0, 0, msk), true},
+		{"value date", Property{Params: map[string]string{"VALUE": "DATE"}, Value: " 20260105 "}, time.Date(2026, 1, 5, 0, 0, 0, 0, msk), true},
+	} {
+		got, allDay, err := tc.prop.Time(msk)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if !got.Equal(tc.want) || allDay != tc.allDay {
+			t.Errorf("%s: Time = %s, all day %v; want %s, %v", tc.name, got, allDay, tc.want, tc.allDay)
+		}
+	}
+	for _, v := range []string{"", "2026-01-05", "20260105T1800", "20261305T180000Z"} {
+		if _, _, err := (Property{Value: v}).Time(msk); err == nil {
+			t.Errorf("Time(%q) succeeded, want an error", v)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want time.Duration
+	}{
+		{"PT1H30M", 90 * time.Minute},
+		{"PT90S", 90 * time.Second},
+		{"P1D", 24 * time.Hour},
+		{"P2W", 14 * 24 * time.Hour},
+		{"P1DT2H", 26 * time.Hour},
+		{"+PT15M", 15 * time.Minute},
+		{"-PT15M", -15 * time.Minute},
+		{" pt1h ", time.Hour},
+	} {
+		got, err := ParseDuration(tc.in)
+		if err != nil {
+			t.Errorf("ParseDuration(%q): %v", tc.in, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParseDuration(%q) = %s, want %s", tc.in, got, tc.want)
+		}
+	}
+	for _, in := range []string{"", "P", "PT", "P1", "PT1", "1H", "P1H", "PT1D", "PTH", "P1X"} {
+		if _, err := ParseDuration(in); err == nil {
+			t.Errorf("ParseDuration(%q) succeeded, want an error", in)
+		}
+	}
+}
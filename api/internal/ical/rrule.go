@@ -0,0 +1,216 @@
+package ical
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence frequencies supported by ParseRecurrence
+const (
+	FreqDaily  = "DAILY"
+	FreqWeekly = "WEEKLY"
+)
+
+// maxRecurrencePeriods bounds the expansion of a rule whose BYDAY never matches
+const maxRecurrencePeriods = 10000
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// Recurrence is the supported RRULE subset: FREQ=DAILY or WEEKLY, INTERVAL, BYDAY (plain weekdays),
+// and at most one of UNTIL and COUNT. Weeks start on Monday.
+type Recurrence struct {
+	Freq     string
+	Interval int
+	ByDay    []time.Weekday // sorted Monday first
+	Until    *time.Time     // inclusive
+	// UntilDate marks an UNTIL given as a date: the whole day in the location of the series' start
+	UntilDate bool
+	Count     int // 0 when unbounded or bounded by Until
+}
+
+// ParseRecurrence parses a rule such as "FREQ=WEEKLY;BYDAY=TU,TH;COUNT=10"; an "RRULE:" prefix is allowed
+func ParseRecurrence(s string) (Recurrence, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	r := Recurrence{Interval: 1}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(part, "=")
+		k = strings.ToUpper(strings.TrimSpace(k))
+		v = strings.ToUpper(strings.TrimSpace(v))
+		if !ok || v == "" {
+			return Recurrence{}, fmt.Errorf("invalid RRULE part %q", part)
+		}
+		if seen[k] {
+			return Recurrence{}, fmt.Errorf("repeated RRULE part %s", k)
+		}
+		seen[k] = true
+		switch k {
+		case "FREQ":
+			if v != FreqDaily && v != FreqWeekly {
+				return Recurrence{}, fmt.Errorf("FREQ=%s is not supported; use DAILY or WEEKLY", v)
+			}
+			r.Freq = v
+		case "INTERVAL":
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > 365 {
+				return Recurrence{}, fmt.Errorf("invalid INTERVAL %q", v)
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return Recurrence{}, fmt.Errorf("invalid COUNT %q", v)
+			}
+			r.Count = n
+		case "UNTIL":
+			if t, err := time.Parse("20060102T150405Z", v); err == nil {
+				r.Until = &t
+			} else if t, err := time.Parse("20060102", v); err == nil {
+				r.Until, r.UntilDate = &t, true
+			} else {
+				return Recurrence{}, fmt.Errorf("invalid UNTIL %q", v)
+			}
+		case "BYDAY":
+			for _, code := range strings.Split(v, ",") {
+				d, ok := weekdayCodes[code]
+				if !ok {
+					return Recurrence{}, fmt.Errorf("BYDAY=%s is not supported; use MO..SU", code)
+				}
+				if !containsWeekday(r.ByDay, d) {
+					r.ByDay = append(r.ByDay, d)
+				}
+			}
+			sort.Slice(r.ByDay, func(i, j int) bool { return mondayFirst(r.ByDay[i]) < mondayFirst(r.ByDay[j]) })
+		case "WKST":
+			if v != "MO" {
+				return Recurrence{}, errors.New("only WKST=MO is supported")
+			}
+		default:
+			return Recurrence{}, fmt.Errorf("RRULE part %s is not supported", k)
+		}
+	}
+	if r.Freq == "" {
+		return Recurrence{}, errors.New("RRULE requires FREQ")
+	}
+	if r.Count > 0 && r.Until != nil {
+		return Recurrence{}, errors.New("RRULE cannot have both COUNT and UNTIL")
+	}
+	return r, nil
+}
+
+// String renders the rule in canonical form
+func (r Recurrence) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, 0, len(r.ByDay))
+		for _, d := range r.ByDay {
+			codes = append(codes, strings.ToUpper(d.String()[:2]))
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil && r.UntilDate {
+		parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+	} else if r.Until != nil {
+		parts = append(parts, "UNTIL="+formatTime(*r.Until))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Bounded reports whether the rule ends by COUNT or UNTIL
+func (r Recurrence) Bounded() bool {
+	return r.Count > 0 || r.Until != nil
+}
+
+// Between returns the starts of occurrences of a series starting at start that fall in (after, to],
+// at most limit of them. Occurrences keep the wall-clock time of start in its location, and COUNT
+// counts from start.
+func (r Recurrence) Between(start, after, to time.Time, limit int) []time.Time {
+	var until *time.Time
+	if r.Until != nil {
+		u := *r.Until
+		if r.UntilDate {
+			y, m, d := u.Date()
+			u = time.Date(y, m, d+1, 0, 0, 0, 0, start.Location()).Add(-time.Nanosecond)
+		}
+		until = &u
+	}
+	var out []time.Time
+	n := 0
+	emit := func(t time.Time) bool {
+		if t.Before(start) {
+			return true
+		}
+		if until != nil && t.After(*until) || t.After(to) {
+			return false
+		}
+		n++
+		if r.Count > 0 && n > r.Count {
+			return false
+		}
+		if t.After(after) {
+			out = append(out, t)
+		}
+		return len(out) < limit
+	}
+	y, m, d := start.Date()
+	h, mi, s := start.Clock()
+	at := func(days int) time.Time {
+		return time.Date(y, m, d+days, h, mi, s, start.Nanosecond(), start.Location())
+	}
+	switch r.Freq {
+	case FreqDaily:
+		for p := 0; p < maxRecurrencePeriods; p++ {
+			t := at(p * r.Interval)
+			if len(r.ByDay) > 0 && !containsWeekday(r.ByDay, t.Weekday()) {
+				continue
+			}
+			if !emit(t) {
+				break
+			}
+		}
+	case FreqWeekly:
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []time.Weekday{start.Weekday()}
+		}
+		weekStart := -mondayFirst(start.Weekday()) // offset of the start's Monday
+	weeks:
+		for p := 0; p < maxRecurrencePeriods; p++ {
+			for _, wd := range days {
+				if !emit(at(weekStart + p*7*r.Interval + mondayFirst(wd))) {
+					break weeks
+				}
+			}
+		}
+	}
+	return out
+}
+
+func mondayFirst(d time.Weekday) int {
+	return (int(d) + 6) % 7
+}
+
+func containsWeekday(days []time.Weekday, d time.Weekday) bool {
+	for _, x := range days {
+		if x == d {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package ical
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"FREQ=DAILY", "FREQ=DAILY"},
+		{"FREQ=DAILY;INTERVAL=1", "FREQ=DAILY"},
+		{"RRULE:freq=weekly;byday=th,tu,th;count=10", "FREQ=WEEKLY;BYDAY=TU,TH;COUNT=10"},
+		{" FREQ=WEEKLY;INTERVAL=2;BYDAY=SU,MO;WKST=MO; ", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,SU"},
+		{"FREQ=DAILY;UNTIL=20260107", "FREQ=DAILY;UNTIL=20260107"},
+		{"FREQ=WEEKLY;UNTIL=20260119T150000Z", "FREQ=WEEKLY;UNTIL=20260119T150000Z"},
+	} {
+		r, err := ParseRecurrence(tc.in)
+		if err != nil {
+			t.Errorf("ParseRecurrence(%q): %v", tc.in, err)
+			continue
+		}
+		if got := r.String(); got != tc.want {
+			t.Errorf("ParseRecurrence(%q).String() = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestParseRecurrenceErrors(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"COUNT=3",
+		"FREQ",
+		"FREQ=",
+		"FREQ=MONTHLY",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;INTERVAL=366",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;COUNT=x",
+		"FREQ=DAILY;UNTIL=2026-01-07",
+		"FREQ=DAILY;COUNT=2;UNTIL=20260107",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=WEEKLY;WKST=SU",
+		"FREQ=DAILY;BYMONTH=1",
+	} {
+		if _, err := ParseRecurrence(in); err == nil {
+			t.Errorf("ParseRecurrence(%q) succeeded, want an error", in)
+		}
+	}
+}
+
+func TestRecurrenceBetween(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	est := time.FixedZone("EST", -5*60*60)
+	day := func(loc *time.Location, month time.Month, d, h, m int) time.Time {
+		return time.Date(2026, month, d, h, m, 0, 0, loc)
+	}
+	// Monday
+	monday := day(msk, 1, 5, 18, 0)
+	for _, tc := range []struct {
+		name      string
+		rule      string
+		start     time.Time
+		after, to time.Time // zero after means just before start
+		limit     int
+		want      []time.Time
+	}{
+		{
+			name:  "daily count",
+			rule:  "FREQ=DAILY;COUNT=3",
+			start: monday,
+			want:  []time.Time{day(msk, 1, 5, 18, 0), day(msk, 1, 6, 18, 0), day(msk, 1, 7, 18, 0)},
+		},
+		{
+			name:  "count is taken from the start, not from after",
+			rule:  "FREQ=DAILY;COUNT=5",
+			start: monday,
+			after: day(msk, 1, 7, 18, 0),
+			want:  []time.Time{day(msk, 1, 8, 18, 0), day(msk, 1, 9, 18, 0)},
+		},
+		{
+			name:  "weekly byday count skips days before the start",
+			rule:  "FREQ=WEEKLY;BYDAY=TU,TH;COUNT=4",
+			start: monday,
+			want:  []time.Time{day(msk, 1, 6, 18, 0), day(msk, 1, 8, 18, 0), day(msk, 1, 13, 18, 0), day(msk, 1, 15, 18, 0)},
+		},
+		{
+			name:  "until as a date-time is inclusive",
+			rule:  "FREQ=WEEKLY;UNTIL=20260119T150000Z",
+			start: monday,
+			want:  []time.Time{day(msk, 1, 5, 18, 0), day(msk, 1, 12, 18, 0), day(msk, 1, 19, 18, 0)},
+		},
+		{
+			name:  "until as a date covers the whole day in the start's location",
+			rule:  "FREQ=DAILY;UNTIL=20260107",
+			start: day(est, 1, 5, 22, 0),
+			want:  []time.Time{day(est, 1, 5, 22, 0), day(est, 1, 6, 22, 0), day(est, 1, 7, 22, 0)},
+		},
+		{
+			name:  "weekly interval with byday",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=4",
+			start: day(msk, 1, 7, 18, 0),
+			want:  []time.Time{day(msk, 1, 7, 18, 0), day(msk, 1, 19, 18, 0), day(msk, 1, 21, 18, 0), day(msk, 2, 2, 18, 0)},
+		},
+		{
+			name:  "daily interval with byday",
+			rule:  "FREQ=DAILY;INTERVAL=2;BYDAY=MO,TU",
+			start: monday,
+			to:    day(msk, 1, 20, 0, 0),
+			want:  []time.Time{day(msk, 1, 5, 18, 0), day(msk, 1, 13, 18, 0), day(msk, 1, 19, 18, 0)},
+		},
+		{
+			name:  "unbounded rule stops at to",
+			rule:  "FREQ=WEEKLY",
+			start: monday,
+			to:    day(msk, 1, 19, 18, 0),
+			want:  []time.Time{day(msk, 1, 5, 18, 0), day(msk, 1, 12, 18, 0), day(msk, 1, 19, 18, 0)},
+		},
+		{
+			name:  "limit",
+			rule:  "FREQ=DAILY",
+			start: monday,
+			limit: 2,
+			want:  []time.Time{day(msk, 1, 5, 18, 0), day(msk, 1, 6, 18, 0)},
+		},
+		{
+			name:  "byday that never matches a daily interval",
+			rule:  "FREQ=DAILY;INTERVAL=7;BYDAY=TU",
+			start: monday,
+			want:  nil,
+		},
+	} {
+		r, err := ParseRecurrence(tc.rule)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		after := tc.after
+		if after.IsZero() {
+			after = tc.start.Add(-time.Second)
+		}
+		to := tc.to
+		if to.IsZero() {
+			to = tc.start.AddDate(1, 0, 0)
+		}
+		limit := tc.limit
+		if limit == 0 {
+			limit = 100
+		}
+		got := r.Between(tc.start, after, to, limit)
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if !got[i].Equal(tc.want[i]) || got[i].Location() != tc.start.Location() {
+				t.Errorf("%s: occurrence %d = %s, want %s", tc.name, i, got[i], tc.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1-a * * * *",
+		"@reboot",
+		// dates that never exist
+		"0 0 30 2 *",
+		"0 0 31 4,6,9,11 *",
+	} {
+		if _, err := ParseCron(spec); err == nil {
+			t.Errorf("ParseCron(%q) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	// Thursday
+	from := time.Date(2026, 1, 15, 10, 30, 20, 0, time.UTC)
+	for _, tc := range []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 1, 15, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 1, 15, 10, 45, 0, 0, time.UTC)},
+		{"10-20/5 * * * *", time.Date(2026, 1, 15, 11, 10, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2026, 1, 15, 10, 45, 0, 0, time.UTC)},
+		{"0,30 * * * *", time.Date(2026, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{"30 10 15 1 *", time.Date(2027, 1, 15, 10, 30, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 0", time.Date(2026, 1, 18, 0, 0, 0, 0, time.UTC)},
+		// 7 is Sunday too
+		{"0 0 * * 7", time.Date(2026, 1, 18, 0, 0, 0, 0, time.UTC)},
+		// either restricted day field may match
+		{"0 0 1 * 1", time.Date(2026, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 16 * 1", time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// February 31st never comes, Mondays in February do
+		{"0 0 31 2 1", time.Date(2026, 2, 2, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{"@midnight", time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2026, 1, 18, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{" @annually ", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+	} {
+		s, err := ParseCron(tc.spec)
+		if err != nil {
+			t.Errorf("ParseCron(%q): %v", tc.spec, err)
+			continue
+		}
+		if got := s.Next(from); !got.Equal(tc.want) {
+			t.Errorf("%q: Next(%s) = %s, want %s", tc.spec, from, got, tc.want)
+		}
+	}
+}
+
+func TestScheduleNextLocation(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	s, err := ParseCron("0 9 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2026, 1, 15, 10, 0, 0, 0, msk)
+	want := time.Date(2026, 1, 16, 9, 0, 0, 0, msk)
+	if got := s.Next(from); !got.Equal(want) || got.Location() != msk {
+		t.Errorf("Next(%s) = %s, want %s", from, got, want)
+	}
+}
+
+func TestScheduleNextStrictlyAfter(t *testing.T) {
+	s, err := ParseCron("0 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	want := time.Date(2026, 1, 15, 11, 0, 0, 0, time.UTC)
+	if got := s.Next(from); !got.Equal(want) {
+		t.Errorf("Next(%s) = %s, want %s", from, got, want)
+	}
+}
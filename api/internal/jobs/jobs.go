@@ -0,0 +1,223 @@
+// Package jobs is a durable background job queue on top of Postgres.
+// Workers claim jobs with FOR UPDATE SKIP LOCKED, so any number of API instances can run a Runner.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mosprom/api/internal/model"
+	"mosprom/api/internal/repository"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultMaxAttempts is how many times a job runs before it is dead-lettered
+	DefaultMaxAttempts = 5
+
+	// lease after which a running job is considered abandoned by a crashed worker
+	lease = 10 * time.Minute
+
+	backoffBase = 30 * time.Second
+	backoffMax  = time.Hour
+
+	// how often a runner deletes finished jobs older than its retention
+	pruneInterval = time.Hour
+)
+
+// Handler processes a claimed job; returning an error schedules a retry
+type Handler func(ctx context.Context, job model.Job) error
+
+// Runner executes jobs for the registered handlers and enqueues recurring jobs
+type Runner struct {
+	id        string
+	interval  time.Duration
+	retention time.Duration
+	handlers  map[string]Handler
+	prunedAt  time.Time
+}
+
+// NewRunner creates a worker polling the queue every interval. Jobs that succeeded are deleted
+// once they are older than retention; zero keeps them forever.
+func NewRunner(interval, retention time.Duration) *Runner {
+	host, _ := os.Hostname()
+	return &Runner{
+		id:        host + "/" + uuid.NewString(),
+		interval:  interval,
+		retention: retention,
+		handlers:  make(map[string]Handler),
+	}
+}
+
+// Register adds a handler working on the raw job
+func (r *Runner) Register(jobType string, h Handler) {
+	r.handlers[jobType] = h
+}
+
+// Handle registers a typed handler; the job payload is decoded into T before fn is called
+func Handle[T any](r *Runner, jobType string, fn func(ctx context.Context, payload T) error) {
+	r.Register(jobType, func(ctx context.Context, job model.Job) error {
+		var payload T
+		if len(job.Payload) > 0 {
+			if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+				return fmt.Errorf("decode %s payload: %w", jobType, err)
+			}
+		}
+		return fn(ctx, payload)
+	})
+}
+
+// Enqueue adds a job to run as soon as a worker is free
+func Enqueue(jobType string, payload any) (model.Job, error) {
+	return EnqueueAt(jobType, payload, time.Now())
+}
+
+// EnqueueAt adds a job that becomes runnable at runAt
+func EnqueueAt(jobType string, payload any, runAt time.Time) (model.Job, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return model.Job{}, err
+	}
+	job := model.Job{
+		Type:        jobType,
+		Payload:     raw,
+		Status:      model.JobQueued,
+		RunAt:       runAt,
+		MaxAttempts: DefaultMaxAttempts,
+	}
+	if err := repository.CreateJob(&job); err != nil {
+		return model.Job{}, err
+	}
+	return job, nil
+}
+
+// Schedule registers a recurring job: a job of jobType with payload is enqueued each time spec fires.
+// Definitions are stored by name, so calling Schedule on every start is safe.
+func (r *Runner) Schedule(name, spec, jobType string, payload any) error {
+	sched, err := ParseCron(spec)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return repository.UpsertRecurringJob(&model.RecurringJob{
+		Name:      name,
+		Spec:      spec,
+		Type:      jobType,
+		Payload:   raw,
+		NextRunAt: sched.Next(time.Now()),
+	})
+}
+
+// Run processes jobs until ctx is cancelled
+func (r *Runner) Run(ctx context.Context) error {
+	types := make([]string, 0, len(r.handlers))
+	for t := range r.handlers {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := repository.EnqueueDueRecurringJobs(time.Now(), DefaultMaxAttempts, nextRecurringRun); err != nil {
+			log.Printf("jobs: enqueue recurring: %v", err)
+		}
+		r.prune()
+		// drain the queue before sleeping again
+		for ctx.Err() == nil {
+			job, err := repository.ClaimJob(r.id, types, time.Now(), lease)
+			if err != nil {
+				log.Printf("jobs: claim: %v", err)
+				break
+			}
+			if job == nil {
+				break
+			}
+			r.execute(ctx, *job)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// prune deletes succeeded jobs past the retention, at most once per pruneInterval
+func (r *Runner) prune() {
+	if r.retention <= 0 || time.Since(r.prunedAt) < pruneInterval {
+		return
+	}
+	r.prunedAt = time.Now()
+	if _, err := repository.PruneJobs(time.Now().Add(-r.retention)); err != nil {
+		log.Printf("jobs: prune: %v", err)
+	}
+}
+
+func (r *Runner) execute(ctx context.Context, job model.Job) {
+	err := r.call(ctx, job)
+	if err == nil {
+		if err := repository.CompleteJob(job.ID, r.id); err != nil {
+			log.Printf("jobs: complete %d: %v", job.ID, err)
+		}
+		return
+	}
+
+	var retryAt *time.Time
+	if job.Attempts < job.MaxAttempts {
+		t := time.Now().Add(Backoff(job.Attempts))
+		retryAt = &t
+		log.Printf("jobs: %s #%d attempt %d failed, retry at %s: %v", job.Type, job.ID, job.Attempts, t.Format(time.RFC3339), err)
+	} else {
+		log.Printf("jobs: %s #%d dead after %d attempts: %v", job.Type, job.ID, job.Attempts, err)
+	}
+	if err := repository.FailJob(job.ID, r.id, err.Error(), retryAt); err != nil {
+		log.Printf("jobs: fail %d: %v", job.ID, err)
+	}
+}
+
+// call runs the handler, turning a panic into an ordinary failure
+func (r *Runner) call(ctx context.Context, job model.Job) (err error) {
+	h, ok := r.handlers[job.Type]
+	if !ok {
+		return fmt.Errorf("no handler for job type %q", job.Type)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return h(ctx, job)
+}
+
+// Backoff returns the delay before the retry that follows the given attempt: 30s, 1m, 2m, ... capped at 1h
+func Backoff(attempt int) time.Duration {
+	d := backoffBase
+	for i := 1; i < attempt && d < backoffMax; i++ {
+		d *= 2
+	}
+	if d > backoffMax {
+		d = backoffMax
+	}
+	return d
+}
+
+func nextRecurringRun(rj model.RecurringJob) (time.Time, error) {
+	sched, err := ParseCron(rj.Spec)
+	if err != nil {
+		return time.Time{}, err
+	}
+	next := sched.Next(time.Now())
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("cron %q never fires", rj.Spec)
+	}
+	return next, nil
+}
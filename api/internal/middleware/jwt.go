@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"os"
 	"strings"
@@ -16,33 +17,69 @@ func JWTAuth() gin.HandlerFunc {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "no bearer token"})
 			return
 		}
-		tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
-		secret := os.Getenv("JWT_SECRET")
-		if secret == "" {
-			secret = "secret"
+		if err := authenticate(c, strings.TrimPrefix(authHeader, "Bearer ")); err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
 		}
+		c.Next()
+	}
+}
 
-		token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-			return []byte(secret), nil
-		})
-		if err != nil || !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
-			return
+// OptionalJWTAuth sets user_id and role like JWTAuth when a valid bearer token is sent and lets
+// anonymous requests (or invalid tokens) through without them
+func OptionalJWTAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authHeader := c.GetHeader("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+			_ = authenticate(c, strings.TrimPrefix(authHeader, "Bearer "))
 		}
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid claims"})
-			return
+		c.Next()
+	}
+}
+
+// WebsocketAuth is JWTAuth for websocket upgrades: browsers cannot set headers there, so the
+// token may also come in the token query parameter
+func WebsocketAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenStr := c.Query("token")
+		if authHeader := c.GetHeader("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+			tokenStr = strings.TrimPrefix(authHeader, "Bearer ")
 		}
-		userID, ok := claims["user_id"].(float64)
-		if !ok {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "user_id missing"})
+		if tokenStr == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "no bearer token"})
 			return
 		}
-		c.Set("user_id", uint(userID))
-		if role, rok := claims["role"].(string); rok {
-			c.Set("role", role)
+		if err := authenticate(c, tokenStr); err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
 		}
 		c.Next()
 	}
 }
+
+// authenticate validates the token and stores its user_id and role in the context
+func authenticate(c *gin.Context, tokenStr string) error {
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		secret = "secret"
+	}
+
+	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	})
+	if err != nil || !token.Valid {
+		return errors.New("invalid token")
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return errors.New("invalid claims")
+	}
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		return errors.New("user_id missing")
+	}
+	c.Set("user_id", uint(userID))
+	if role, rok := claims["role"].(string); rok {
+		c.Set("role", role)
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole allows the request only if the JWT carried the given role; use after JWTAuth
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if r, _ := c.Get("role"); r != role {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+			return
+		}
+		c.Next()
+	}
+}
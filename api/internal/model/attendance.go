@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+)
+
+type CheckInMethod string
+
+const (
+	CheckInQR     CheckInMethod = "qr"     // participant scanned the organizer's rotating code
+	CheckInManual CheckInMethod = "manual" // checked in by an organizer
+)
+
+// PostAttendance is a participant's verified attendance of a post. users.events_count and ratings
+// count attendances, not joins.
+type PostAttendance struct {
+	ID            uint          `json:"id" gorm:"primaryKey"`
+	PostID        uint          `json:"post_id" gorm:"not null;uniqueIndex:ux_post_attendances,priority:1"`
+	Post          *Post         `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID        uint          `json:"user_id" gorm:"not null;uniqueIndex:ux_post_attendances,priority:2;index"`
+	User          *User         `json:"user,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Method        CheckInMethod `json:"method" gorm:"type:varchar(10);not null"`
+	CheckedInByID *uint         `json:"checked_in_by_id"` // organizer for manual check-ins
+	CreatedAt     time.Time     `json:"created_at"`
+}
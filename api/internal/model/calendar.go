@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// CalendarToken is the secret in a user's calendar feed URL; calendar apps cannot send bearer tokens
+type CalendarToken struct {
+	UserID    uint      `json:"-" gorm:"primaryKey;autoIncrement:false"`
+	User      *User     `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Token     string    `json:"token" gorm:"type:varchar(64);not null;uniqueIndex"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CancelledPost keeps a deleted post's calendar data so feeds can publish the cancellation
+// to the participants' and clubs' calendars
+type CancelledPost struct {
+	PostID         uint          `gorm:"primaryKey;autoIncrement:false"`
+	ClubID         uint          `gorm:"not null;index"`
+	CoHostClubIDs  pq.Int64Array `gorm:"type:bigint[]"`
+	ParticipantIDs pq.Int64Array `gorm:"type:bigint[]"`
+	Title          string
+	StartDate      time.Time `gorm:"not null"`
+	EndDate        *time.Time
+	Sequence       int
+	CreatedAt      time.Time // of the post
+	CancelledAt    time.Time `gorm:"not null;index"`
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type CampaignStatus string
+
+const (
+	CampaignActive    CampaignStatus = "active"
+	CampaignPaused    CampaignStatus = "paused"
+	CampaignCompleted CampaignStatus = "completed" // quota reached
+)
+
+// Campaign periodically invites users matching its criteria to the club (or to PostID) until Quota invitations are sent
+type Campaign struct {
+	ID      uint   `json:"id" gorm:"primaryKey"`
+	ClubID  uint   `json:"club_id" gorm:"not null;index"`
+	Club    *Club  `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	PostID  *uint  `json:"post_id"`
+	Post    *Post  `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Name    string `json:"name" gorm:"not null"`
+	Message string `json:"message"`
+	// Targeting: every set criterion must match; technologies and directions match if the user has any of them
+	Technologies   pq.StringArray `json:"technologies" gorm:"type:text[]" swaggertype:"array,string"`
+	Directions     pq.StringArray `json:"directions" gorm:"type:text[]" swaggertype:"array,string"`
+	University     string         `json:"university"`
+	MinRating      float64        `json:"min_rating" gorm:"type:double precision;not null;default:0"`
+	MemberOfClubID *uint          `json:"member_of_club_id"`
+	// Delivery
+	Quota       int            `json:"quota" gorm:"not null"`      // total invitations to send
+	BatchSize   int            `json:"batch_size" gorm:"not null"` // invitations per run
+	Schedule    string         `json:"schedule" gorm:"not null"`   // cron spec
+	Status      CampaignStatus `json:"status" gorm:"type:varchar(20);not null;default:active;index"`
+	NextRunAt   *time.Time     `json:"next_run_at" gorm:"index"`
+	LastRunAt   *time.Time     `json:"last_run_at"`
+	SentCount   int            `json:"sent_count" gorm:"not null;default:0"`
+	CreatedByID uint           `json:"created_by_id"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+
+	Stats *CampaignStats `json:"stats,omitempty" gorm:"-"`
+}
+
+// CampaignStats counts the campaign's invitations by outcome
+type CampaignStats struct {
+	Sent     int `json:"sent"`
+	Pending  int `json:"pending"`
+	Accepted int `json:"accepted"`
+	Declined int `json:"declined"`
+}
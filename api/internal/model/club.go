@@ -1,5 +1,15 @@
 package model
 
+import (
+	"time"
+)
+
+// Roles of subscribers inside a club (club_subscribers.role)
+const (
+	ClubRoleMember = "member"
+	ClubRoleAdmin  = "admin"
+)
+
 // Club represents a club entity
 type Club struct {
 	ID               uint        `json:"id" gorm:"primaryKey"`
@@ -12,6 +22,13 @@ type Club struct {
 	Subscribers      []User      `json:"subscribers" gorm:"many2many:club_subscribers;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 	SubscribersCount int         `json:"subscribers_count"`
 	ChatID           string      `json:"chat_id"`
+	Visibility       string      `json:"visibility" gorm:"type:varchar(20);not null;default:open"` // ClubVisibilityOpen or ClubVisibilityRequest
+	// OrganizationID is the enterprise owning the club; Verified mirrors its verification for the badge
+	OrganizationID *uint         `json:"organization_id" gorm:"index"`
+	Organization   *Organization `json:"organization,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Verified       bool          `json:"verified" gorm:"not null;default:false"`
+	// ArchivedAt is set for archived clubs: read-only, hidden from listings, chat frozen
+	ArchivedAt *time.Time `json:"archived_at"`
 }
 
 // (Event entity removed; participation is now tied to Post)
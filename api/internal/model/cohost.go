@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+)
+
+type CoHostStatus string
+
+const (
+	CoHostPending  CoHostStatus = "pending"
+	CoHostAccepted CoHostStatus = "accepted"
+	CoHostDeclined CoHostStatus = "declined"
+)
+
+// PostCoHost invites another club to co-host a post. Once accepted the post is listed by that club
+// and counted in its analytics alongside the host club (Post.ClubID).
+type PostCoHost struct {
+	ID          uint         `json:"id" gorm:"primaryKey"`
+	PostID      uint         `json:"post_id" gorm:"not null;uniqueIndex:ux_post_co_hosts,priority:1"`
+	Post        *Post        `json:"post,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ClubID      uint         `json:"club_id" gorm:"not null;uniqueIndex:ux_post_co_hosts,priority:2;index"`
+	Club        *Club        `json:"club,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Status      CoHostStatus `json:"status" gorm:"type:varchar(20);not null;default:pending"`
+	InvitedByID uint         `json:"invited_by_id"`
+	RespondedAt *time.Time   `json:"responded_at"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
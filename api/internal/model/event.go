@@ -0,0 +1,67 @@
+package model
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type EventType string
+
+const (
+	EventPostCreated          EventType = "post.created"
+	EventPostUpdated          EventType = "post.updated"
+	EventPostDeleted          EventType = "post.deleted"
+	EventPostLiked            EventType = "post.liked"
+	EventPostUnliked          EventType = "post.unliked"
+	EventUserJoinedPost       EventType = "post.joined"
+	EventUserLeftPost         EventType = "post.left"
+	EventUserWaitlisted       EventType = "post.waitlisted"
+	EventWaitlistPromoted     EventType = "post.waitlist_promoted"
+	EventRegistrationCreated  EventType = "post.registration_created"
+	EventRegistrationApproved EventType = "post.registration_approved"
+	EventRegistrationRejected EventType = "post.registration_rejected"
+	EventUserCheckedIn        EventType = "post.checked_in"
+	EventClubCreated          EventType = "club.created"
+	EventClubUpdated          EventType = "club.updated"
+	EventClubArchived         EventType = "club.archived"
+	EventClubUnarchived       EventType = "club.unarchived"
+	EventClubDeleted          EventType = "club.deleted"
+	EventUserSubscribedToClub EventType = "club.subscribed"
+	EventClubUnsubscribed     EventType = "club.unsubscribed"
+	EventClubMemberKicked     EventType = "club.member_kicked"
+	EventClubMemberBanned     EventType = "club.member_banned"
+	EventClubJoinRequested    EventType = "club.join_requested"
+	EventClubJoinRejected     EventType = "club.join_rejected"
+	EventInvitationCreated    EventType = "invitation.created"
+	EventInvitationAccepted   EventType = "invitation.accepted"
+	EventInvitationDeclined   EventType = "invitation.declined"
+	EventOrganizationVerified EventType = "organization.verified"
+	EventOrganizationRejected EventType = "organization.rejected"
+)
+
+// EventPayload is the body of every domain event; ids not related to the event are left zero
+type EventPayload struct {
+	PostID         uint     `json:"post_id,omitempty"`
+	ClubID         uint     `json:"club_id,omitempty"`
+	UserID         uint     `json:"user_id,omitempty"`
+	PostType       PostType `json:"post_type,omitempty"`
+	Reason         string   `json:"reason,omitempty"` // kick/ban/rejection reason
+	OrganizationID uint     `json:"organization_id,omitempty"`
+}
+
+// OutboxEvent is a domain event written in the same transaction as the change that caused it.
+// The dispatcher delivers it to subscribers at least once and sets DispatchedAt.
+type OutboxEvent struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	Type          EventType      `json:"type" gorm:"type:varchar(64);not null;index"`
+	ClubID        *uint          `json:"club_id" gorm:"index"`
+	Payload       JSON           `json:"payload" gorm:"type:jsonb" swaggertype:"object"`
+	Attempts      int            `json:"attempts" gorm:"not null;default:0"`
+	LastError     string         `json:"last_error"`
+	DeliveredTo   pq.StringArray `json:"delivered_to" gorm:"type:text[]" swaggertype:"array,string"` // subscribers that handled it; retries skip them
+	NextAttemptAt time.Time      `json:"next_attempt_at" gorm:"not null"`
+	DispatchedAt  *time.Time     `json:"dispatched_at"`
+	FailedAt      *time.Time     `json:"failed_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
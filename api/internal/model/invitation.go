@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+)
+
+type InvitationStatus string
+
+const (
+	InvitationPending  InvitationStatus = "pending"
+	InvitationAccepted InvitationStatus = "accepted"
+	InvitationDeclined InvitationStatus = "declined"
+)
+
+// Invitation is a personal invite from a club admin to a user: to a post when PostID is set, otherwise to the club
+type Invitation struct {
+	ID          uint             `json:"id" gorm:"primaryKey"`
+	ClubID      uint             `json:"club_id" gorm:"not null;index"`
+	Club        *Club            `json:"club,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	PostID      *uint            `json:"post_id" gorm:"index"`
+	Post        *Post            `json:"post,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID      uint             `json:"user_id" gorm:"not null;index"`
+	User        *User            `json:"user,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	InvitedByID uint             `json:"invited_by_id"`
+	CampaignID  *uint            `json:"campaign_id" gorm:"index"` // set for invitations sent by a campaign
+	Message     string           `json:"message"`
+	Status      InvitationStatus `json:"status" gorm:"type:varchar(20);not null;default:pending;index"`
+	RespondedAt *time.Time       `json:"responded_at"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
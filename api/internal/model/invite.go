@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+)
+
+// ClubInvite is a shareable token that subscribes whoever redeems it, bypassing join requests
+type ClubInvite struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	ClubID      uint       `json:"club_id" gorm:"not null;index"`
+	Club        *Club      `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Token       string     `json:"token" gorm:"type:varchar(64);not null;uniqueIndex"`
+	Role        string     `json:"role" gorm:"type:varchar(20);not null;default:member"` // club role granted on redeem
+	MaxUses     *int       `json:"max_uses"`                                             // nil = unlimited
+	Uses        int        `json:"uses" gorm:"not null;default:0"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedByID uint       `json:"created_by_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// Usable reports whether the invite can still be redeemed at t
+func (i ClubInvite) Usable(t time.Time) bool {
+	if i.RevokedAt != nil {
+		return false
+	}
+	if i.ExpiresAt != nil && !t.Before(*i.ExpiresAt) {
+		return false
+	}
+	return i.MaxUses == nil || i.Uses < *i.MaxUses
+}
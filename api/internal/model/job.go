@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+)
+
+type JobStatus string
+
+const (
+	JobQueued  JobStatus = "queued"  // ждёт выполнения (в т.ч. повторного)
+	JobRunning JobStatus = "running" // захвачена воркером
+	JobDone    JobStatus = "done"
+	JobDead    JobStatus = "dead" // исчерпаны попытки
+)
+
+// Job is a unit of background work stored in Postgres
+type Job struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Type        string     `json:"type" gorm:"type:varchar(64);not null;index"`
+	Payload     JSON       `json:"payload" gorm:"type:jsonb" swaggertype:"object"`
+	Status      JobStatus  `json:"status" gorm:"type:varchar(20);not null;default:queued;index:idx_jobs_status_run_at,priority:1"`
+	RunAt       time.Time  `json:"run_at" gorm:"not null;index:idx_jobs_status_run_at,priority:2"`
+	Attempts    int        `json:"attempts" gorm:"not null;default:0"`
+	MaxAttempts int        `json:"max_attempts" gorm:"not null;default:5"`
+	LastError   string     `json:"last_error"`
+	LockedBy    string     `json:"locked_by"`
+	LockedAt    *time.Time `json:"locked_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// RecurringJob enqueues a Job of Type every time the cron Spec fires
+type RecurringJob struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	Name      string     `json:"name" gorm:"uniqueIndex;not null"`
+	Spec      string     `json:"spec" gorm:"not null"`
+	Type      string     `json:"type" gorm:"type:varchar(64);not null"`
+	Payload   JSON       `json:"payload" gorm:"type:jsonb" swaggertype:"object"`
+	NextRunAt time.Time  `json:"next_run_at" gorm:"not null;index"`
+	LastRunAt *time.Time `json:"last_run_at"`
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"time"
+)
+
+// Club visibility: open clubs accept subscriptions directly, request clubs go through join requests
+const (
+	ClubVisibilityOpen    = "open"
+	ClubVisibilityRequest = "request"
+)
+
+type JoinRequestStatus string
+
+const (
+	JoinRequestPending  JoinRequestStatus = "pending"
+	JoinRequestApproved JoinRequestStatus = "approved"
+	JoinRequestRejected JoinRequestStatus = "rejected"
+)
+
+// ClubJoinRequest is a user's request to join a request-to-join club
+type ClubJoinRequest struct {
+	ID          uint              `json:"id" gorm:"primaryKey"`
+	ClubID      uint              `json:"club_id" gorm:"not null;index"`
+	Club        *Club             `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID      uint              `json:"user_id" gorm:"not null;index"`
+	User        *User             `json:"user,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Message     string            `json:"message"`
+	Status      JoinRequestStatus `json:"status" gorm:"type:varchar(20);not null;default:pending"`
+	DecidedByID *uint             `json:"decided_by_id"`
+	DecidedAt   *time.Time        `json:"decided_at"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+// ClubBan keeps a user out of the club: no subscription, join requests or chat
+type ClubBan struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	ClubID     uint      `json:"club_id" gorm:"not null;uniqueIndex:ux_club_bans,priority:1"`
+	Club       *Club     `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID     uint      `json:"user_id" gorm:"not null;uniqueIndex:ux_club_bans,priority:2"`
+	User       *User     `json:"user,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Reason     string    `json:"reason"`
+	BannedByID uint      `json:"banned_by_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+)
+
+type NotificationType string
+
+const (
+	PostReminderNotification   NotificationType = "post_reminder"   // напоминание о начале мероприятия
+	NewParticipantNotification NotificationType = "new_participant" // организатору: кто-то записался на пост
+	ClubMembershipNotification NotificationType = "club_membership" // исключение, бан или отказ во вступлении
+	InvitationNotification     NotificationType = "invitation"      // приглашение в клуб или на пост
+	OrganizationNotification   NotificationType = "organization"    // решение по верификации организации
+	WaitlistNotification       NotificationType = "waitlist"        // освободилось место, пользователь переведён из листа ожидания
+	RegistrationNotification   NotificationType = "registration"    // новая заявка на пост или решение по заявке
+)
+
+// Notification is an in-app message addressed to a single user
+type Notification struct {
+	ID        uint             `json:"id" gorm:"primaryKey"`
+	UserID    uint             `json:"user_id" gorm:"not null;index;uniqueIndex:ux_notifications_user_event,priority:1"`
+	User      *User            `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Type      NotificationType `json:"type" gorm:"type:varchar(40);not null"`
+	Title     string           `json:"title"`
+	Body      string           `json:"body"`
+	PostID    *uint            `json:"post_id"`
+	ClubID    *uint            `json:"club_id"`
+	EventID   *uint            `json:"-" gorm:"uniqueIndex:ux_notifications_user_event,priority:2"` // outbox event that caused it, for idempotency
+	ReadAt    *time.Time       `json:"read_at"`
+	CreatedAt time.Time        `json:"created_at"`
+}
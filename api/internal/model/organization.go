@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+)
+
+type OrganizationStatus string
+
+const (
+	OrganizationPending  OrganizationStatus = "pending"
+	OrganizationVerified OrganizationStatus = "verified"
+	OrganizationRejected OrganizationStatus = "rejected"
+)
+
+// Roles of staff accounts inside an organization
+const (
+	OrgRoleOwner = "owner" // manages staff and legal details
+	OrgRoleStaff = "staff" // may attach the organization's clubs
+)
+
+// Organization is an enterprise that owns clubs. Legal details are checked by a platform admin;
+// clubs of verified organizations carry a badge.
+type Organization struct {
+	ID              uint                `json:"id" gorm:"primaryKey"`
+	Name            string              `json:"name" gorm:"not null"`
+	LegalName       string              `json:"legal_name" gorm:"not null"`
+	INN             string              `json:"inn" gorm:"type:varchar(12);index;not null"` // ИНН, 10 or 12 digits; unique among organizations that are not rejected
+	OGRN            string              `json:"ogrn" gorm:"type:varchar(15)"`               // ОГРН, 13 or 15 digits
+	LegalAddress    string              `json:"legal_address"`
+	Website         string              `json:"website"`
+	ContactEmail    string              `json:"contact_email"`
+	Status          OrganizationStatus  `json:"status" gorm:"type:varchar(20);not null;default:pending;index"`
+	RejectionReason string              `json:"rejection_reason"`
+	ReviewedByID    *uint               `json:"reviewed_by_id"`
+	ReviewedAt      *time.Time          `json:"reviewed_at"`
+	CreatedByID     uint                `json:"created_by_id"`
+	Staff           []OrganizationStaff `json:"staff,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CreatedAt       time.Time           `json:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at"`
+}
+
+// OrganizationStaff links a user account to an organization
+type OrganizationStaff struct {
+	OrganizationID uint      `json:"organization_id" gorm:"primaryKey"`
+	UserID         uint      `json:"user_id" gorm:"primaryKey;index"`
+	User           *User     `json:"user,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Role           string    `json:"role" gorm:"type:varchar(20);not null;default:staff"`
+	CreatedAt      time.Time `json:"created_at"`
+}
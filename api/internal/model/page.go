@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+)
+
+type PageVersionStatus string
+
+const (
+	PageDraft     PageVersionStatus = "draft"
+	PagePublished PageVersionStatus = "published" // at most one per club
+	PageArchived  PageVersionStatus = "archived"  // previously published
+)
+
+// ClubPageVersion is an immutable snapshot of a club page layout; Blocks is an ordered []pages.Block
+type ClubPageVersion struct {
+	ID          uint              `json:"id" gorm:"primaryKey"`
+	ClubID      uint              `json:"club_id" gorm:"not null;uniqueIndex:ux_club_page_versions,priority:1"`
+	Club        *Club             `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Version     int               `json:"version" gorm:"not null;uniqueIndex:ux_club_page_versions,priority:2"`
+	Blocks      JSON              `json:"blocks" gorm:"type:jsonb;not null" swaggertype:"array,object"`
+	Status      PageVersionStatus `json:"status" gorm:"type:varchar(20);not null;default:draft"`
+	CreatedByID uint              `json:"created_by_id"`
+	CreatedAt   time.Time         `json:"created_at"`
+	PublishedAt *time.Time        `json:"published_at"`
+}
@@ -23,21 +23,29 @@ const (
 )
 
 type Post struct {
-	ID                uint         `json:"id" gorm:"primaryKey"`
-	Title             string       `json:"title" gorm:"not null"`
-	Description       string       `json:"description"`
-	Type              PostType     `json:"type" gorm:"type:varchar(20);not null"`
-	StartDate         *time.Time   `json:"start_date"`
-	EndDate           *time.Time   `json:"end_date"`
-	AgeRestriction    *int         `json:"age_restriction"`
-	Format            *PostFormat  `json:"format" gorm:"type:varchar(20)"`
-	Address           string       `json:"address"`
-	ClubID            uint         `json:"club_id" gorm:"not null"`
-	Club              *Club        `json:"club" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	Participants      []User       `json:"participants" gorm:"many2many:post_participants;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	ParticipantsCount int          `json:"participants_count"`
-	Technologies      []Technology `json:"technologies" gorm:"many2many:post_technologies;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	Likes             []Like       `json:"likes" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	CreatedAt         time.Time    `json:"created_at"`
-	UpdatedAt         time.Time    `json:"updated_at"`
+	ID                uint                       `json:"id" gorm:"primaryKey"`
+	Title             string                     `json:"title" gorm:"not null"`
+	Description       string                     `json:"description"`
+	Type              PostType                   `json:"type" gorm:"type:varchar(20);not null"`
+	StartDate         *time.Time                 `json:"start_date"`
+	EndDate           *time.Time                 `json:"end_date"`
+	AgeRestriction    *int                       `json:"age_restriction"`
+	Format            *PostFormat                `json:"format" gorm:"type:varchar(20)"`
+	Address           string                     `json:"address"`
+	ClubID            uint                       `json:"club_id" gorm:"not null"`
+	Club              *Club                      `json:"club" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	SeriesID          *uint                      `json:"series_id" gorm:"index"` // set on occurrences of a recurring post
+	Series            *PostSeries                `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	CoHosts           []PostCoHost               `json:"co_hosts,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"` // accepted co-host clubs when loaded
+	Participants      []User                     `json:"participants" gorm:"many2many:post_participants;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	ParticipantsCount int                        `json:"participants_count"`
+	Capacity          *int                       `json:"capacity"` // participant limit, nil for unlimited; further joins are waitlisted
+	RegistrationMode  RegistrationMode           `json:"registration_mode" gorm:"type:varchar(20);not null;default:open"`
+	Questions         []PostRegistrationQuestion `json:"registration_questions,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Technologies      []Technology               `json:"technologies" gorm:"many2many:post_technologies;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Likes             []Like                     `json:"likes" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Sequence          int                        `json:"-" gorm:"not null;default:0"` // iCalendar SEQUENCE, bumped on every update
+	ImportKey         *string                    `json:"-" gorm:"type:varchar(300)"`  // source identity of imported posts, unique per club
+	CreatedAt         time.Time                  `json:"created_at"`
+	UpdatedAt         time.Time                  `json:"updated_at"`
 }
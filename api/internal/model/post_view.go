@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+)
+
+// PostView is one view of a post page, used for view-to-join conversion analytics.
+// A viewer is counted once per post and UTC day.
+type PostView struct {
+	ID       uint       `json:"id" gorm:"primaryKey"`
+	PostID   uint       `json:"post_id" gorm:"not null;index:ix_post_views_post_time,priority:1"`
+	Post     *Post      `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ViewedAt time.Time  `json:"viewed_at" gorm:"not null;index:ix_post_views_post_time,priority:2"`
+	Viewer   string     `json:"-" gorm:"type:varchar(80);not null;default:''"` // "user:<id>" or "ip:<hash>"; empty in views recorded before deduplication
+	ViewDate *time.Time `json:"-" gorm:"type:date"`                            // UTC day of ViewedAt
+}
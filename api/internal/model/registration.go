@@ -0,0 +1,56 @@
+package model
+
+import (
+	"time"
+)
+
+// Registration modes of a post: open posts admit joins directly, approval posts collect registrations
+// that organizers approve or reject
+type RegistrationMode string
+
+const (
+	RegistrationOpen     RegistrationMode = "open"
+	RegistrationApproval RegistrationMode = "approval"
+)
+
+type RegistrationStatus string
+
+const (
+	RegistrationPending  RegistrationStatus = "pending"
+	RegistrationApproved RegistrationStatus = "approved"
+	RegistrationRejected RegistrationStatus = "rejected"
+)
+
+// PostRegistrationQuestion is an organizer-defined question asked when registering for an approval post
+type PostRegistrationQuestion struct {
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	PostID   uint   `json:"post_id" gorm:"not null;index"`
+	Post     *Post  `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Position int    `json:"position" gorm:"not null"`
+	Text     string `json:"text" gorm:"not null"`
+	Required bool   `json:"required"`
+}
+
+// RegistrationAnswer keeps the question text as asked, so later edits of the questions do not change answers
+type RegistrationAnswer struct {
+	QuestionID uint   `json:"question_id"`
+	Question   string `json:"question"`
+	Answer     string `json:"answer"`
+}
+
+// PostRegistration is a user's application to take part in an approval post. Approving it joins the user
+// (or puts them on the waitlist when the post is full).
+type PostRegistration struct {
+	ID          uint               `json:"id" gorm:"primaryKey"`
+	PostID      uint               `json:"post_id" gorm:"not null;index"`
+	Post        *Post              `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID      uint               `json:"user_id" gorm:"not null;index"`
+	User        *User              `json:"user,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Motivation  string             `json:"motivation"`
+	Answers     JSON               `json:"answers" gorm:"type:jsonb" swaggertype:"array,object"` // []RegistrationAnswer
+	Status      RegistrationStatus `json:"status" gorm:"type:varchar(20);not null;default:pending"`
+	Reason      string             `json:"reason"` // rejection reason
+	DecidedByID *uint              `json:"decided_by_id"`
+	DecidedAt   *time.Time         `json:"decided_at"`
+	CreatedAt   time.Time          `json:"created_at"`
+}
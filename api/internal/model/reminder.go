@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+)
+
+type ReminderStatus string
+
+const (
+	ReminderPending   ReminderStatus = "pending"
+	ReminderSent      ReminderStatus = "sent"
+	ReminderCancelled ReminderStatus = "cancelled"
+)
+
+// PostReminder is a notification to post participants scheduled OffsetMinutes before Post.StartDate.
+// Rows are removed together with the post.
+type PostReminder struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	PostID        uint           `json:"post_id" gorm:"not null;index"`
+	Post          *Post          `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	OffsetMinutes int            `json:"offset_minutes" gorm:"not null"`
+	FireAt        time.Time      `json:"fire_at" gorm:"not null;index"`
+	Status        ReminderStatus `json:"status" gorm:"type:varchar(20);not null;default:pending;index"`
+	SentAt        *time.Time     `json:"sent_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+)
+
+// PostSeries is a recurring post. Its occurrences are ordinary posts with SeriesID set, created from
+// the template fields up to a horizon that a background job keeps extending. An occurrence can be
+// edited or deleted (cancelled) on its own; occurrences are never re-created once their start passed
+// MaterializedUntil.
+type PostSeries struct {
+	ID                uint             `json:"id" gorm:"primaryKey"`
+	ClubID            uint             `json:"club_id" gorm:"not null;index"`
+	Club              *Club            `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	RRule             string           `json:"rrule" gorm:"column:rrule;type:varchar(255);not null"` // canonical RRULE subset, see ical.Recurrence
+	StartDate         time.Time        `json:"start_date" gorm:"not null"`                           // DTSTART of the rule
+	UTCOffset         int              `json:"utc_offset"`                                           // seconds east of UTC of the wall clock the rule is expanded in
+	DurationSeconds   *int64           `json:"duration_seconds"`                                     // end of each occurrence after its start, nil without an end
+	Title             string           `json:"title"`
+	Description       string           `json:"description"`
+	Type              PostType         `json:"type" gorm:"type:varchar(20);not null"`
+	AgeRestriction    *int             `json:"age_restriction"`
+	Format            *PostFormat      `json:"format" gorm:"type:varchar(20)"`
+	Address           string           `json:"address"`
+	Capacity          *int             `json:"capacity"`
+	RegistrationMode  RegistrationMode `json:"registration_mode" gorm:"type:varchar(20);not null;default:open"`
+	MaterializedUntil time.Time        `json:"materialized_until" gorm:"not null;index"` // occurrences starting up to here were created
+	EndedAt           *time.Time       `json:"ended_at"`                                 // set when the series is cancelled
+	CreatedAt         time.Time        `json:"created_at"`
+	UpdatedAt         time.Time        `json:"updated_at"`
+}
+
+// PostSeriesMember is a user who joined a whole series; they join every occurrence as it is created
+type PostSeriesMember struct {
+	SeriesID  uint        `json:"series_id" gorm:"primaryKey;autoIncrement:false"`
+	Series    *PostSeries `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID    uint        `json:"user_id" gorm:"primaryKey;autoIncrement:false;index"`
+	User      *User       `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CreatedAt time.Time   `json:"created_at"`
+}
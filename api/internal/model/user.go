@@ -1,20 +1,29 @@
 package model
 
 import (
+	"time"
+
 	"github.com/lib/pq"
 )
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin" // администратор платформы
+)
+
 // User — основная сущность пользователя
 type User struct {
 	ID           uint           `json:"id" gorm:"primaryKey"`
 	TelegramName string         `json:"telegram_name" gorm:"uniqueIndex;not null"`
 	Name         string         `json:"name"`
 	Password     string         `json:"-"` // не отдаём наружу
+	Role         string         `json:"role" gorm:"type:varchar(20);not null;default:user"`
 	Description  string         `json:"description"`
 	Photo        string         `json:"photo"` // относительный путь до файла
 	Achievements pq.StringArray `json:"achievements" gorm:"type:text[]" swaggertype:"array,string"`
-	EventsCount  int            `json:"events_count"`
+	EventsCount  int            `json:"events_count"` // verified attendances (check-ins)
 	University   string         `json:"university"`
+	BirthDate    *time.Time     `json:"-" gorm:"type:date"` // private: responses expose only eligibility for age-restricted posts
 	Technologies []Technology   `json:"technologies" gorm:"many2many:user_technologies;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 	Directions   []Direction    `json:"directions" gorm:"many2many:user_directions;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 	// Subscriptions
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+)
+
+// ParticipationStatus is the outcome of joining a post
+type ParticipationStatus string
+
+const (
+	ParticipationJoined     ParticipationStatus = "joined"
+	ParticipationWaitlisted ParticipationStatus = "waitlisted"
+)
+
+// PostWaitlistEntry queues a user for a post that is at capacity. Entries are promoted to participants
+// in id order as places free up.
+type PostWaitlistEntry struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	PostID    uint      `json:"post_id" gorm:"not null;uniqueIndex:ux_post_waitlist,priority:1"`
+	Post      *Post     `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:ux_post_waitlist,priority:2;index"`
+	User      *User     `json:"user,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CreatedAt time.Time `json:"created_at"`
+}
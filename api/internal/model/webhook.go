@@ -0,0 +1,61 @@
+package model
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Webhook is a club's subscription to domain events delivered by signed HTTP POST to URL
+type Webhook struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	ClubID      uint           `json:"club_id" gorm:"not null;index"`
+	Club        *Club          `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	URL         string         `json:"url" gorm:"not null"`
+	Secret      string         `json:"-" gorm:"not null"`                                         // HMAC key, shown only once on creation
+	EventTypes  pq.StringArray `json:"event_types" gorm:"type:text[]" swaggertype:"array,string"` // empty = all events
+	Active      bool           `json:"active" gorm:"not null;default:true"`
+	CreatedByID uint           `json:"created_by_id"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// Accepts reports whether the webhook is subscribed to the event type
+func (w Webhook) Accepts(t EventType) bool {
+	if len(w.EventTypes) == 0 {
+		return true
+	}
+	for _, et := range w.EventTypes {
+		if EventType(et) == t {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	DeliveryPending   WebhookDeliveryStatus = "pending"
+	DeliveryRetrying  WebhookDeliveryStatus = "retrying"
+	DeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	DeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is one event sent (or to be sent) to a webhook; Payload is the exact request body
+type WebhookDelivery struct {
+	ID             uint                  `json:"id" gorm:"primaryKey"`
+	WebhookID      uint                  `json:"webhook_id" gorm:"not null;index"`
+	Webhook        *Webhook              `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	EventID        uint                  `json:"event_id" gorm:"not null"`
+	EventType      EventType             `json:"event_type" gorm:"type:varchar(64);not null"`
+	Payload        JSON                  `json:"payload" gorm:"type:jsonb" swaggertype:"object"`
+	Status         WebhookDeliveryStatus `json:"status" gorm:"type:varchar(20);not null;default:pending"`
+	Attempts       int                   `json:"attempts" gorm:"not null;default:0"`
+	ResponseStatus int                   `json:"response_status"`
+	LastError      string                `json:"last_error"`
+	ReplayOfID     *uint                 `json:"replay_of_id"`
+	DeliveredAt    *time.Time            `json:"delivered_at"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+}
@@ -0,0 +1,106 @@
+package pages
+
+// Block types of a club page
+const (
+	BlockHero        = "hero"
+	BlockAbout       = "about"
+	BlockPinnedPosts = "pinned_posts"
+	BlockTeam        = "team"
+	BlockFAQ         = "faq"
+	BlockPartners    = "partners"
+	BlockLinks       = "links"
+	BlockGallery     = "gallery"
+)
+
+// MaxBlocks is the maximum number of blocks on one page
+const MaxBlocks = 50
+
+const urlPattern = `^https?://\S+$`
+
+func obj(required []string, props map[string]*Schema) *Schema {
+	closed := false
+	return &Schema{Type: "object", Properties: props, Required: required, AdditionalProperties: &closed}
+}
+
+func str(maxLen int) *Schema {
+	return &Schema{Type: "string", MaxLength: &maxLen}
+}
+
+func nonEmpty(maxLen int) *Schema {
+	one := 1
+	return &Schema{Type: "string", MinLength: &one, MaxLength: &maxLen}
+}
+
+func url() *Schema {
+	maxLen := 2000
+	return &Schema{Type: "string", MaxLength: &maxLen, Pattern: urlPattern}
+}
+
+func list(item *Schema, maxItems int) *Schema {
+	one := 1
+	return &Schema{Type: "array", Items: item, MinItems: &one, MaxItems: &maxItems}
+}
+
+func id() *Schema {
+	one := 1.0
+	return &Schema{Type: "integer", Minimum: &one}
+}
+
+// Schemas maps every block type to the schema of its data
+var Schemas = map[string]*Schema{
+	BlockHero: obj([]string{"title"}, map[string]*Schema{
+		"title":    nonEmpty(120),
+		"subtitle": str(300),
+		"image":    str(500), // uploaded file name or URL
+		"cta": obj([]string{"label", "url"}, map[string]*Schema{
+			"label": nonEmpty(40),
+			"url":   url(),
+		}),
+	}),
+	BlockAbout: obj([]string{"text"}, map[string]*Schema{
+		"title": str(120),
+		"text":  nonEmpty(5000),
+	}),
+	BlockPinnedPosts: obj([]string{"post_ids"}, map[string]*Schema{
+		"title":    str(120),
+		"post_ids": list(id(), 12),
+	}),
+	BlockTeam: obj([]string{"members"}, map[string]*Schema{
+		"title": str(120),
+		"members": list(obj([]string{"name"}, map[string]*Schema{
+			"user_id": id(),
+			"name":    nonEmpty(120),
+			"role":    str(120),
+			"photo":   str(500),
+		}), 50),
+	}),
+	BlockFAQ: obj([]string{"items"}, map[string]*Schema{
+		"title": str(120),
+		"items": list(obj([]string{"question", "answer"}, map[string]*Schema{
+			"question": nonEmpty(300),
+			"answer":   nonEmpty(3000),
+		}), 50),
+	}),
+	BlockPartners: obj([]string{"partners"}, map[string]*Schema{
+		"title": str(120),
+		"partners": list(obj([]string{"name"}, map[string]*Schema{
+			"name": nonEmpty(120),
+			"logo": str(500),
+			"url":  url(),
+		}), 50),
+	}),
+	BlockLinks: obj([]string{"links"}, map[string]*Schema{
+		"title": str(120),
+		"links": list(obj([]string{"label", "url"}, map[string]*Schema{
+			"label": nonEmpty(80),
+			"url":   url(),
+		}), 20),
+	}),
+	BlockGallery: obj([]string{"images"}, map[string]*Schema{
+		"title": str(120),
+		"images": list(obj([]string{"url"}, map[string]*Schema{
+			"url":     nonEmpty(500),
+			"caption": str(300),
+		}), 50),
+	}),
+}
@@ -0,0 +1,58 @@
+package pages
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+// Block is one typed content block of a club page; Data must match Schemas[Type]
+type Block struct {
+	ID   string          `json:"id"` // stable key for the app, unique within the page
+	Type string          `json:"type" enums:"hero,about,pinned_posts,team,faq,partners,links,gallery"`
+	Data json.RawMessage `json:"data" swaggertype:"object"`
+}
+
+var blockIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Validate checks the block list: size, known types, unique ids and data against the type schema
+func Validate(blocks []Block) error {
+	if len(blocks) > MaxBlocks {
+		return fmt.Errorf("a page can have at most %d blocks", MaxBlocks)
+	}
+	seen := make(map[string]bool, len(blocks))
+	for i, b := range blocks {
+		schema, ok := Schemas[b.Type]
+		if !ok {
+			return fmt.Errorf("blocks[%d]: unknown block type %q", i, b.Type)
+		}
+		if !blockIDPattern.MatchString(b.ID) {
+			return fmt.Errorf("blocks[%d]: id must be 1-64 letters, digits, '-' or '_'", i)
+		}
+		if seen[b.ID] {
+			return fmt.Errorf("blocks[%d]: duplicate id %q", i, b.ID)
+		}
+		seen[b.ID] = true
+		if err := schema.ValidateJSON(b.Data); err != nil {
+			return fmt.Errorf("blocks[%d] (%s): %w", i, b.Type, err)
+		}
+	}
+	return nil
+}
+
+// PinnedPostIDs returns the post ids referenced by pinned_posts blocks
+func PinnedPostIDs(blocks []Block) []uint {
+	var ids []uint
+	for _, b := range blocks {
+		if b.Type != BlockPinnedPosts {
+			continue
+		}
+		var data struct {
+			PostIDs []uint `json:"post_ids"`
+		}
+		if err := json.Unmarshal(b.Data, &data); err == nil {
+			ids = append(ids, data.PostIDs...)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"fmt"
+	"mosprom/api/internal/model"
+	"time"
+)
+
+// AgeRestrictionError is returned when a user is too young for a post's age restriction,
+// or has no birth date to check it against
+type AgeRestrictionError struct {
+	MinAge           int
+	MissingBirthDate bool
+}
+
+func (e *AgeRestrictionError) Error() string {
+	if e.MissingBirthDate {
+		return fmt.Sprintf("post is restricted to ages %d+; set your birth date to join", e.MinAge)
+	}
+	return fmt.Sprintf("post is restricted to ages %d+", e.MinAge)
+}
+
+// ageAt returns the full years between birth and at
+func ageAt(birth, at time.Time) int {
+	years := at.Year() - birth.Year()
+	if at.Month() < birth.Month() || at.Month() == birth.Month() && at.Day() < birth.Day() {
+		years--
+	}
+	return years
+}
+
+// checkAgeEligible checks the user's age at the start of the post (now for posts without a start date)
+func checkAgeEligible(post model.Post, user model.User) error {
+	if post.AgeRestriction == nil || *post.AgeRestriction <= 0 {
+		return nil
+	}
+	if user.BirthDate == nil {
+		return &AgeRestrictionError{MinAge: *post.AgeRestriction, MissingBirthDate: true}
+	}
+	at := time.Now()
+	if post.StartDate != nil {
+		at = *post.StartDate
+	}
+	if ageAt(*user.BirthDate, at) < *post.AgeRestriction {
+		return &AgeRestrictionError{MinAge: *post.AgeRestriction}
+	}
+	return nil
+}
+
+// ageEligibleSQL is the SQL counterpart of checkAgeEligible: true when the user given by the named
+// parameter userParam may take part in the post aliased alias
+func ageEligibleSQL(alias, userParam string) string {
+	return fmt.Sprintf(`(COALESCE(%[1]s.age_restriction, 0) <= 0 OR EXISTS (
+		SELECT 1 FROM users au
+		WHERE au.id = @%[2]s AND au.birth_date IS NOT NULL
+		  AND EXTRACT(YEAR FROM age(COALESCE(%[1]s.start_date, now()), au.birth_date::timestamptz)) >= %[1]s.age_restriction))`, alias, userParam)
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"mosprom/api/internal/db"
+	"mosprom/api/internal/model"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// RecordPostView stores a view of a post unless the viewer already viewed it on the same UTC day
+func RecordPostView(postID uint, viewer string, at time.Time) error {
+	day := at.UTC().Truncate(24 * time.Hour)
+	view := model.PostView{PostID: postID, ViewedAt: at, Viewer: viewer, ViewDate: &day}
+	return db.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&view).Error
+}
+
+// SubscriberGrowthRow is one bucket of club subscriber growth
+type SubscriberGrowthRow struct {
+	Period         time.Time `json:"period"`
+	NewSubscribers int       `json:"new_subscribers"`
+	Total          int       `json:"total"`
+}
+
+// GetClubSubscriberGrowth buckets subscriptions in [from, to) by unit (day, week or month) with a running total.
+// Only current subscribers are counted: unsubscribing removes the row.
+func GetClubSubscriberGrowth(clubID uint, from, to time.Time, unit string) ([]SubscriberGrowthRow, error) {
+	q := `
+		WITH buckets AS (
+			SELECT generate_series(date_trunc(@unit, @from::timestamptz), @to::timestamptz - interval '1 microsecond', ('1 ' || @unit)::interval) AS period
+		),
+		joins AS (
+			SELECT date_trunc(@unit, created_at) AS period, COUNT(*) AS cnt
+			FROM club_subscribers
+			WHERE club_id = @club AND created_at >= @from AND created_at < @to
+			GROUP BY 1
+		),
+		base AS (
+			SELECT COUNT(*) AS cnt FROM club_subscribers WHERE club_id = @club AND created_at < @from
+		)
+		SELECT b.period,
+			   COALESCE(j.cnt, 0) AS new_subscribers,
+			   (SELECT cnt FROM base) + SUM(COALESCE(j.cnt, 0)) OVER (ORDER BY b.period) AS total
+		FROM buckets b
+		LEFT JOIN joins j ON j.period = b.period
+		ORDER BY b.period`
+	var rows []SubscriberGrowthRow
+	err := db.DB.Raw(q, map[string]any{"club": clubID, "from": from, "to": to, "unit": unit}).Scan(&rows).Error
+	return rows, err
+}
+
+// PostsByTypeRow counts club posts of one type
+type PostsByTypeRow struct {
+	Type  model.PostType `json:"type"`
+	Count int            `json:"count"`
+}
+
+// GetClubPostsByType counts posts hosted or co-hosted by the club created in [from, to) per type
+func GetClubPostsByType(clubID uint, from, to time.Time) ([]PostsByTypeRow, error) {
+	var rows []PostsByTypeRow
+	err := db.DB.Model(&model.Post{}).
+		Select("type, COUNT(*) AS count").
+		Where(hostedByClub("posts"), map[string]any{"club": clubID}).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Group("type").
+		Order("count DESC, type ASC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// PostEngagementRow holds views, joins and likes of one post within the range
+type PostEngagementRow struct {
+	PostID uint           `json:"post_id"`
+	Title  string         `json:"title"`
+	Type   model.PostType `json:"type"`
+	Views  int            `json:"views"`
+	Joins  int            `json:"joins"`
+	Likes  int            `json:"likes"`
+}
+
+// GetClubPostEngagement counts views, joins and likes that happened in [from, to) for every post the club
+// hosts or co-hosts; co-hosted posts are attributed in full to each host
+func GetClubPostEngagement(clubID uint, from, to time.Time) ([]PostEngagementRow, error) {
+	q := `
+		SELECT p.id AS post_id, p.title, p.type,
+			   COALESCE(v.cnt, 0) AS views,
+			   COALESCE(j.cnt, 0) AS joins,
+			   COALESCE(l.cnt, 0) AS likes
+		FROM posts p
+		LEFT JOIN (
+			SELECT post_id, COUNT(*) AS cnt FROM post_views
+			WHERE viewed_at >= @from AND viewed_at < @to GROUP BY post_id
+		) v ON v.post_id = p.id
+		LEFT JOIN (
+			SELECT post_id, COUNT(*) AS cnt FROM post_participants
+			WHERE created_at >= @from AND created_at < @to GROUP BY post_id
+		) j ON j.post_id = p.id
+		LEFT JOIN (
+			SELECT post_id, COUNT(*) AS cnt FROM likes
+			WHERE created_at >= @from AND created_at < @to GROUP BY post_id
+		) l ON l.post_id = p.id
+		WHERE ` + hostedByClub("p") + `
+		ORDER BY joins DESC, likes DESC, p.id ASC`
+	var rows []PostEngagementRow
+	err := db.DB.Raw(q, map[string]any{"club": clubID, "from": from, "to": to}).Scan(&rows).Error
+	return rows, err
+}
+
+// ActiveMemberRow holds a user's joins and likes on the club's posts within the range
+type ActiveMemberRow struct {
+	UserID uint   `json:"user_id"`
+	Name   string `json:"name"`
+	Joins  int    `json:"joins"`
+	Likes  int    `json:"likes"`
+}
+
+// GetClubActiveMembers ranks users by joins plus likes on the club's posts in [from, to)
+func GetClubActiveMembers(clubID uint, from, to time.Time, limit int) ([]ActiveMemberRow, error) {
+	q := `
+		WITH acts AS (
+			SELECT pp.user_id, 1 AS joins, 0 AS likes
+			FROM post_participants pp JOIN posts p ON p.id = pp.post_id
+			WHERE ` + hostedByClub("p") + ` AND pp.created_at >= @from AND pp.created_at < @to
+			UNION ALL
+			SELECT l.user_id, 0, 1
+			FROM likes l JOIN posts p ON p.id = l.post_id
+			WHERE ` + hostedByClub("p") + ` AND l.created_at >= @from AND l.created_at < @to
+		)
+		SELECT u.id AS user_id,
+			   COALESCE(NULLIF(u.name, ''), u.telegram_name) AS name,
+			   SUM(a.joins) AS joins,
+			   SUM(a.likes) AS likes
+		FROM acts a
+		JOIN users u ON u.id = a.user_id
+		GROUP BY u.id, u.name, u.telegram_name
+		ORDER BY SUM(a.joins) + SUM(a.likes) DESC, u.id ASC
+		LIMIT @limit`
+	var rows []ActiveMemberRow
+	err := db.DB.Raw(q, map[string]any{"club": clubID, "from": from, "to": to, "limit": limit}).Scan(&rows).Error
+	return rows, err
+}
+
+// TechnologyShareRow counts club members having a technology
+type TechnologyShareRow struct {
+	Name    string `json:"name"`
+	Members int    `json:"members"`
+}
+
+// GetClubMemberTechnologies returns the most common technologies among current club subscribers
+func GetClubMemberTechnologies(clubID uint, limit int) ([]TechnologyShareRow, error) {
+	var rows []TechnologyShareRow
+	err := db.DB.Table("club_subscribers cs").
+		Select("t.name, COUNT(*) AS members").
+		Joins("JOIN user_technologies ut ON ut.user_id = cs.user_id").
+		Joins("JOIN technologies t ON t.id = ut.technology_id").
+		Where("cs.club_id = ?", clubID).
+		Group("t.name").
+		Order("members DESC, t.name ASC").
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"errors"
+	"mosprom/api/internal/db"
+	"mosprom/api/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrNotParticipant = errors.New("user does not take part in the post")
+	ErrCheckedIn      = errors.New("attendance is already recorded")
+)
+
+// CheckInUser records the participant's attendance and bumps users.events_count. Checking in twice
+// returns the first record unchanged.
+func CheckInUser(att *model.PostAttendance) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		post, err := lockPost(tx, att.PostID)
+		if err != nil {
+			return err
+		}
+		var cnt int64
+		if err := tx.Table("post_participants").Where("post_id = ? AND user_id = ?", att.PostID, att.UserID).Count(&cnt).Error; err != nil {
+			return err
+		}
+		if cnt == 0 {
+			return ErrNotParticipant
+		}
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(att)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return tx.Where("post_id = ? AND user_id = ?", att.PostID, att.UserID).First(att).Error
+		}
+		if err := tx.Model(&model.User{}).Where("id = ?", att.UserID).UpdateColumn("events_count", gorm.Expr("events_count + 1")).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, model.EventUserCheckedIn, model.EventPayload{PostID: post.ID, ClubID: post.ClubID, UserID: att.UserID, PostType: post.Type})
+	})
+}
+
+// GetPostAttendance returns the post's check-ins in order
+func GetPostAttendance(postID uint) ([]model.PostAttendance, error) {
+	var items []model.PostAttendance
+	err := db.DB.Preload("User").Where("post_id = ?", postID).Order("created_at ASC, id ASC").Find(&items).Error
+	return items, err
+}
+
+// CountUserAttendance returns how many posts the user attended
+func CountUserAttendance(userID uint) (int64, error) {
+	var cnt int64
+	err := db.DB.Model(&model.PostAttendance{}).Where("user_id = ?", userID).Count(&cnt).Error
+	return cnt, err
+}
+
+// AddAchievementOnce appends the achievement unless the user already has it
+func AddAchievementOnce(userID uint, achievement string) error {
+	return db.DB.Model(&model.User{}).
+		Where("id = ? AND NOT (? = ANY(COALESCE(achievements, '{}')))", userID, achievement).
+		Update("achievements", gorm.Expr("array_append(achievements, ?)", achievement)).Error
+}
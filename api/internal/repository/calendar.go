@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"mosprom/api/internal/db"
+	"mosprom/api/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// cancelPosts stores CancelledPost rows for the dated posts matching cond before they are deleted;
+// it must run while their participants and co-hosts still exist
+func cancelPosts(tx *gorm.DB, cond string, args ...any) error {
+	return tx.Exec(`
+		INSERT INTO cancelled_posts (post_id, club_id, co_host_club_ids, participant_ids, title, start_date, end_date, sequence, created_at, cancelled_at)
+		SELECT p.id, p.club_id,
+			   COALESCE((SELECT array_agg(h.club_id) FROM post_co_hosts h WHERE h.post_id = p.id AND h.status = 'accepted'), '{}'),
+			   COALESCE((SELECT array_agg(pp.user_id) FROM post_participants pp WHERE pp.post_id = p.id), '{}'),
+			   p.title, p.start_date, p.end_date, p.sequence + 1, p.created_at, now()
+		FROM posts p
+		WHERE p.start_date IS NOT NULL AND `+cond+`
+		ON CONFLICT (post_id) DO NOTHING`, args...).Error
+}
+
+// GetCalendarToken returns the user's calendar token, creating it with newToken on first use
+func GetCalendarToken(userID uint, newToken string) (model.CalendarToken, error) {
+	t := model.CalendarToken{UserID: userID, Token: newToken}
+	if err := db.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&t).Error; err != nil {
+		return model.CalendarToken{}, err
+	}
+	err := db.DB.First(&t, "user_id = ?", userID).Error
+	return t, err
+}
+
+// RotateCalendarToken replaces the user's calendar token, invalidating the old feed URL
+func RotateCalendarToken(userID uint, newToken string) (model.CalendarToken, error) {
+	t := model.CalendarToken{UserID: userID, Token: newToken, CreatedAt: time.Now()}
+	err := db.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"token", "created_at"}),
+	}).Create(&t).Error
+	return t, err
+}
+
+// GetUserIDByCalendarToken resolves a feed token to its user
+func GetUserIDByCalendarToken(token string) (uint, error) {
+	var t model.CalendarToken
+	err := db.DB.Where("token = ?", token).First(&t).Error
+	return t.UserID, err
+}
+
+// GetUserCalendarPosts returns dated posts the user joined that end after since, by start
+func GetUserCalendarPosts(userID uint, since time.Time) ([]model.Post, error) {
+	var posts []model.Post
+	err := db.DB.Preload("Club").
+		Joins("JOIN post_participants pp ON pp.post_id = posts.id").
+		Where("pp.user_id = ? AND posts.start_date IS NOT NULL AND COALESCE(posts.end_date, posts.start_date) >= ?", userID, since).
+		Order("posts.start_date ASC, posts.id ASC").
+		Find(&posts).Error
+	return posts, err
+}
+
+// GetClubCalendarPosts returns dated posts hosted or co-hosted by the club that end after since, by start
+func GetClubCalendarPosts(clubID uint, since time.Time) ([]model.Post, error) {
+	var posts []model.Post
+	err := db.DB.Preload("Club").
+		Where(hostedByClub("posts"), map[string]any{"club": clubID}).
+		Where("posts.start_date IS NOT NULL AND COALESCE(posts.end_date, posts.start_date) >= ?", since).
+		Order("posts.start_date ASC, posts.id ASC").
+		Find(&posts).Error
+	return posts, err
+}
+
+// GetCancelledPost returns the cancellation of a deleted post
+func GetCancelledPost(postID uint) (model.CancelledPost, error) {
+	var c model.CancelledPost
+	err := db.DB.First(&c, "post_id = ?", postID).Error
+	return c, err
+}
+
+// GetUserCancelledPosts returns cancellations of posts the user had joined, cancelled after since
+func GetUserCancelledPosts(userID uint, since time.Time) ([]model.CancelledPost, error) {
+	var items []model.CancelledPost
+	err := db.DB.Where("? = ANY(participant_ids) AND cancelled_at >= ?", userID, since).
+		Order("start_date ASC, post_id ASC").Find(&items).Error
+	return items, err
+}
+
+// GetClubCancelledPosts returns cancellations of posts the club hosted or co-hosted, cancelled after since
+func GetClubCancelledPosts(clubID uint, since time.Time) ([]model.CancelledPost, error) {
+	var items []model.CancelledPost
+	err := db.DB.Where("(club_id = @club OR @club = ANY(co_host_club_ids)) AND cancelled_at >= @since",
+		map[string]any{"club": clubID, "since": since}).
+		Order("start_date ASC, post_id ASC").Find(&items).Error
+	return items, err
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"mosprom/api/internal/db"
+	"mosprom/api/internal/model"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func CreateCampaign(c *model.Campaign) error {
+	return db.DB.Create(c).Error
+}
+
+// GetClubCampaigns returns newest-first campaigns of the club
+func GetClubCampaigns(clubID uint) ([]model.Campaign, error) {
+	var items []model.Campaign
+	err := db.DB.Where("club_id = ?", clubID).Order("created_at DESC, id DESC").Find(&items).Error
+	return items, err
+}
+
+func GetClubCampaign(clubID, campaignID uint) (model.Campaign, error) {
+	var c model.Campaign
+	err := db.DB.Where("id = ? AND club_id = ?", campaignID, clubID).First(&c).Error
+	return c, err
+}
+
+// SetCampaignStatus pauses or resumes a campaign; nextRunAt is stored as given
+func SetCampaignStatus(campaignID uint, status model.CampaignStatus, nextRunAt *time.Time) error {
+	return db.DB.Model(&model.Campaign{}).Where("id = ?", campaignID).
+		Updates(map[string]any{"status": status, "next_run_at": nextRunAt}).Error
+}
+
+// GetCampaignStats counts invitations of the given campaigns by status
+func GetCampaignStats(campaignIDs []uint) (map[uint]model.CampaignStats, error) {
+	out := make(map[uint]model.CampaignStats, len(campaignIDs))
+	if len(campaignIDs) == 0 {
+		return out, nil
+	}
+	var rows []struct {
+		CampaignID uint
+		Status     model.InvitationStatus
+		Cnt        int
+	}
+	if err := db.DB.Model(&model.Invitation{}).
+		Select("campaign_id, status, COUNT(*) AS cnt").
+		Where("campaign_id IN ?", campaignIDs).
+		Group("campaign_id, status").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		st := out[r.CampaignID]
+		st.Sent += r.Cnt
+		switch r.Status {
+		case model.InvitationPending:
+			st.Pending += r.Cnt
+		case model.InvitationAccepted:
+			st.Accepted += r.Cnt
+		case model.InvitationDeclined:
+			st.Declined += r.Cnt
+		}
+		out[r.CampaignID] = st
+	}
+	return out, nil
+}
+
+// RunNextDueCampaign locks one active campaign due at now (skipping rows locked by other instances), invites
+// up to one batch of matching users and reschedules it with nextRun. Returns false when nothing is due.
+func RunNextDueCampaign(now time.Time, nextRun func(model.Campaign) time.Time) (bool, error) {
+	found := false
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var c model.Campaign
+		res := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_run_at <= ?", model.CampaignActive, now).
+			Order("next_run_at ASC, id ASC").
+			Limit(1).
+			Find(&c)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		found = true
+
+		updates := map[string]any{"last_run_at": now}
+		var archived int64
+		if err := tx.Model(&model.Club{}).Where("id = ? AND archived_at IS NOT NULL", c.ClubID).Count(&archived).Error; err != nil {
+			return err
+		}
+		if archived > 0 {
+			updates["status"] = model.CampaignPaused
+			updates["next_run_at"] = nil
+			return tx.Model(&model.Campaign{}).Where("id = ?", c.ID).Updates(updates).Error
+		}
+
+		batch := min(c.BatchSize, c.Quota-c.SentCount)
+		sent := 0
+		if batch > 0 {
+			candidates, err := campaignCandidates(tx, c, batch)
+			if err != nil {
+				return err
+			}
+			campaignID := c.ID
+			created, _, err := createInvitations(tx, model.Invitation{
+				ClubID:      c.ClubID,
+				PostID:      c.PostID,
+				CampaignID:  &campaignID,
+				InvitedByID: c.CreatedByID,
+				Message:     c.Message,
+			}, candidates)
+			if err != nil {
+				return err
+			}
+			sent = len(created)
+		}
+		updates["sent_count"] = c.SentCount + sent
+		if c.SentCount+sent >= c.Quota {
+			updates["status"] = model.CampaignCompleted
+			updates["next_run_at"] = nil
+		} else {
+			updates["next_run_at"] = nextRun(c)
+		}
+		return tx.Model(&model.Campaign{}).Where("id = ?", c.ID).Updates(updates).Error
+	})
+	return found, err
+}
+
+// campaignCandidates selects up to limit users matching the campaign criteria, best rated first. Users who
+// are already members or participants, were already invited, are banned or own the club are left out.
+func campaignCandidates(tx *gorm.DB, c model.Campaign, limit int) ([]uint, error) {
+	q := tx.Table("users u").
+		Where("COALESCE(u.rating, 0) >= ?", c.MinRating).
+		Where("u.id <> COALESCE((SELECT creator_id FROM clubs WHERE id = ?), 0)", c.ClubID).
+		Where("NOT EXISTS (SELECT 1 FROM club_bans b WHERE b.club_id = ? AND b.user_id = u.id)", c.ClubID)
+
+	if uni := strings.TrimSpace(c.University); uni != "" {
+		q = q.Where("LOWER(TRIM(u.university)) = LOWER(?)", uni)
+	}
+	if names := lowerNames(c.Technologies); len(names) > 0 {
+		q = q.Where(`EXISTS (
+			SELECT 1 FROM user_technologies ut JOIN technologies t ON t.id = ut.technology_id
+			WHERE ut.user_id = u.id AND LOWER(t.name) IN ?)`, names)
+	}
+	if names := lowerNames(c.Directions); len(names) > 0 {
+		q = q.Where(`EXISTS (
+			SELECT 1 FROM user_directions ud JOIN directions d ON d.id = ud.direction_id
+			WHERE ud.user_id = u.id AND LOWER(d.name) IN ?)`, names)
+	}
+	if c.MemberOfClubID != nil {
+		q = q.Where("EXISTS (SELECT 1 FROM club_subscribers cs WHERE cs.club_id = ? AND cs.user_id = u.id)", *c.MemberOfClubID)
+	}
+
+	if c.PostID != nil {
+		q = q.Where("NOT EXISTS (SELECT 1 FROM post_participants pp WHERE pp.post_id = ? AND pp.user_id = u.id)", *c.PostID).
+			Where("NOT EXISTS (SELECT 1 FROM invitations i WHERE i.post_id = ? AND i.user_id = u.id)", *c.PostID)
+	} else {
+		q = q.Where("NOT EXISTS (SELECT 1 FROM club_subscribers cs WHERE cs.club_id = ? AND cs.user_id = u.id)", c.ClubID).
+			Where("NOT EXISTS (SELECT 1 FROM invitations i WHERE i.club_id = ? AND i.post_id IS NULL AND i.user_id = u.id)", c.ClubID)
+	}
+
+	var ids []uint
+	err := q.Order("u.rating DESC, u.id ASC").Limit(limit).Pluck("u.id", &ids).Error
+	return ids, err
+}
+
+func lowerNames(names []string) []string {
+	out := make([]string, 0, len(names))
+	for _, n := range names {
+		if n = strings.ToLower(strings.TrimSpace(n)); n != "" {
+			out = append(out, n)
+		}
+	}
+	return out
+}
@@ -1,15 +1,31 @@
 package repository
 
 import (
+	"errors"
 	"mosprom/api/internal/db"
 	"mosprom/api/internal/model"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+// ErrClubArchived is returned by writes to an archived (read-only) club
+var ErrClubArchived = errors.New("club is archived")
+
 func CreateClub(club *model.Club) error {
-	return db.DB.Create(club).Error
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(club).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, model.EventClubCreated, model.EventPayload{ClubID: club.ID, UserID: club.CreatorID})
+	})
+}
+
+func GetClubByID(id uint) (model.Club, error) {
+	var club model.Club
+	err := db.DB.Preload("Directions").Preload("Creator").First(&club, id).Error
+	return club, err
 }
 
 func GetClubByName(name string) (model.Club, error) {
@@ -22,11 +38,12 @@ func ListClubsByDirections(directionNames []string) ([]model.Club, error) {
 	var clubs []model.Club
 	if len(directionNames) == 0 {
 		// return all with preloads
-		err := db.DB.Preload("Directions").Preload("Creator").Find(&clubs).Error
+		err := db.DB.Preload("Directions").Preload("Creator").Where("clubs.archived_at IS NULL").Find(&clubs).Error
 		return clubs, err
 	}
 	// join directions via club_directions to filter by names
-	err := db.DB.Joins("JOIN club_directions cd ON cd.club_id = clubs.id").
+	err := db.DB.Where("clubs.archived_at IS NULL").
+		Joins("JOIN club_directions cd ON cd.club_id = clubs.id").
 		Joins("JOIN directions d ON d.id = cd.direction_id").
 		Where("d.name IN ?", directionNames).
 		Preload("Directions").Preload("Creator").
@@ -39,11 +56,12 @@ func SetClubLogo(clubID uint, filename string) error {
 	return db.DB.Model(&model.Club{}).Where("id = ?", clubID).Update("logo", filename).Error
 }
 
-// ListClubsFiltered returns clubs filtered by optional exact name and by having ALL of the given directions.
+// ListClubsFiltered returns non-archived clubs filtered by optional exact name and by having ALL of the given directions.
 func ListClubsFiltered(name *string, directions []string) ([]model.Club, error) {
 	var clubs []model.Club
 	q := db.DB.Model(&model.Club{}).
-		Preload("Directions").Preload("Creator")
+		Preload("Directions").Preload("Creator").
+		Where("clubs.archived_at IS NULL")
 
 	if name != nil && strings.TrimSpace(*name) != "" {
 		q = q.Where("clubs.name = ?", strings.TrimSpace(*name))
@@ -80,36 +98,43 @@ func uniqueStrings(in []string) []string {
 }
 
 // SubscribeUserToClub creates a subscription relation and updates counters transactionally.
+// Banned users get ErrBannedFromClub; request-to-join clubs return ErrJoinRequestRequired.
 func SubscribeUserToClub(userID, clubID uint) error {
 	return db.DB.Transaction(func(tx *gorm.DB) error {
-		// Ensure entities exist
-		var club model.Club
-		if err := tx.First(&club, clubID).Error; err != nil {
-			return err
-		}
-		var user model.User
-		if err := tx.First(&user, userID).Error; err != nil {
-			return err
-		}
+		return subscribeUserToClub(tx, userID, clubID, false)
+	})
+}
 
-		// Check if already subscribed
-		var count int64
-		if err := tx.Table("club_subscribers").Where("club_id = ? AND user_id = ?", clubID, userID).Count(&count).Error; err != nil {
+// subscribeUserToClub subscribes inside the caller's transaction; invited users skip the join-request gate
+func subscribeUserToClub(tx *gorm.DB, userID, clubID uint, invited bool) error {
+	// Ensure entities exist
+	var club model.Club
+	if err := tx.First(&club, clubID).Error; err != nil {
+		return err
+	}
+	if club.ArchivedAt != nil {
+		return ErrClubArchived
+	}
+	var user model.User
+	if err := tx.First(&user, userID).Error; err != nil {
+		return err
+	}
+	banned, err := isBannedFromClub(tx, clubID, userID)
+	if err != nil {
+		return err
+	}
+	if banned {
+		return ErrBannedFromClub
+	}
+	if club.Visibility == model.ClubVisibilityRequest && !invited {
+		member, err := isClubSubscriber(tx, clubID, userID)
+		if err != nil || member {
 			return err
 		}
-		if count == 0 {
-			if err := tx.Model(&club).Association("Subscribers").Append(&user); err != nil {
-				return err
-			}
-			if err := tx.Model(&model.Club{}).Where("id = ?", clubID).UpdateColumn("subscribers_count", gorm.Expr("subscribers_count + 1")).Error; err != nil {
-				return err
-			}
-			if err := tx.Model(&model.User{}).Where("id = ?", userID).UpdateColumn("clubs_count", gorm.Expr("clubs_count + 1")).Error; err != nil {
-				return err
-			}
-		}
-		return nil
-	})
+		return ErrJoinRequestRequired
+	}
+	_, err = addClubSubscriber(tx, clubID, userID)
+	return err
 }
 
 // GetClubSubscribers returns users subscribed to the club
@@ -137,3 +162,247 @@ func GetClubByChatID(chatID string) (model.Club, error) {
 	err := db.DB.Preload("Directions").Preload("Creator").Where("chat_id = ?", chatID).First(&club).Error
 	return club, err
 }
+
+// GetClubMemberRole returns the role of a subscriber inside the club, or "" if the user is not subscribed
+func GetClubMemberRole(clubID, userID uint) (string, error) {
+	var roles []string
+	err := db.DB.Table("club_subscribers").
+		Where("club_id = ? AND user_id = ?", clubID, userID).
+		Pluck("role", &roles).Error
+	if err != nil || len(roles) == 0 {
+		return "", err
+	}
+	return roles[0], nil
+}
+
+// UpdateClub saves name, description and visibility and, when directions is not nil, replaces the club directions
+func UpdateClub(club *model.Club, directions *[]model.Direction) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Club{}).Where("id = ?", club.ID).
+			Updates(map[string]any{"name": club.Name, "description": club.Description, "visibility": club.Visibility}).Error; err != nil {
+			return err
+		}
+		if directions != nil {
+			if err := tx.Model(club).Association("Directions").Replace(directions); err != nil {
+				return err
+			}
+		}
+		return recordEvent(tx, model.EventClubUpdated, model.EventPayload{ClubID: club.ID})
+	})
+}
+
+// SetClubArchived archives (at != nil) or unarchives a club
+func SetClubArchived(clubID uint, at *time.Time) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.Club{}).Where("id = ?", clubID).Update("archived_at", at)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		eventType := model.EventClubArchived
+		if at == nil {
+			eventType = model.EventClubUnarchived
+		}
+		return recordEvent(tx, eventType, model.EventPayload{ClubID: clubID})
+	})
+}
+
+// IsClubArchived reports whether the club exists and is archived
+func IsClubArchived(clubID uint) (bool, error) {
+	var cnt int64
+	err := db.DB.Model(&model.Club{}).Where("id = ? AND archived_at IS NOT NULL", clubID).Count(&cnt).Error
+	return cnt > 0, err
+}
+
+// IsPostClubArchived reports whether the post's club is archived
+func IsPostClubArchived(postID uint) (bool, error) {
+	var cnt int64
+	err := db.DB.Model(&model.Club{}).
+		Joins("JOIN posts ON posts.club_id = clubs.id").
+		Where("posts.id = ? AND clubs.archived_at IS NOT NULL", postID).
+		Count(&cnt).Error
+	return cnt > 0, err
+}
+
+// DeleteClub removes a club with its posts (participants, technologies, likes) and subscriptions,
+// keeping users' clubs_count and events_count in sync. Returns the deleted club.
+func DeleteClub(clubID uint) (model.Club, error) {
+	var club model.Club
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&club, clubID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`
+			UPDATE users SET events_count = GREATEST(users.events_count - x.cnt, 0)
+			FROM (
+				SELECT user_id, COUNT(*) AS cnt
+				FROM post_attendances
+				WHERE post_id IN (SELECT id FROM posts WHERE club_id = ?)
+				GROUP BY user_id
+			) x
+			WHERE users.id = x.user_id`, clubID).Error; err != nil {
+			return err
+		}
+		if err := cancelPosts(tx, "p.club_id = ?", clubID); err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM post_participants WHERE post_id IN (SELECT id FROM posts WHERE club_id = ?)", clubID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM post_technologies WHERE post_id IN (SELECT id FROM posts WHERE club_id = ?)", clubID).Error; err != nil {
+			return err
+		}
+		// likes, reminders and attendances go with the posts via ON DELETE CASCADE
+		if err := tx.Where("club_id = ?", clubID).Delete(&model.Post{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`
+			UPDATE users SET clubs_count = GREATEST(clubs_count - 1, 0)
+			WHERE id IN (SELECT user_id FROM club_subscribers WHERE club_id = ?)`, clubID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM club_subscribers WHERE club_id = ?", clubID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM club_directions WHERE club_id = ?", clubID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&model.Club{}, clubID).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, model.EventClubDeleted, model.EventPayload{ClubID: clubID, UserID: club.CreatorID})
+	})
+	return club, err
+}
+
+// GetClubsByIDs fetches clubs with preloads and returns map[id]club
+func GetClubsByIDs(ids []uint) (map[uint]model.Club, error) {
+	if len(ids) == 0 {
+		return map[uint]model.Club{}, nil
+	}
+	var clubs []model.Club
+	if err := db.DB.Preload("Directions").Preload("Creator").Where("id IN ?", ids).Find(&clubs).Error; err != nil {
+		return nil, err
+	}
+	m := make(map[uint]model.Club, len(clubs))
+	for _, c := range clubs {
+		m[c.ID] = c
+	}
+	return m, nil
+}
+
+// ClubRecommendationRow holds the score components of a recommended club
+type ClubRecommendationRow struct {
+	ClubID             uint    `gorm:"column:club_id"`
+	Score              float64 `gorm:"column:score"`
+	DirectionMatch     float64 `gorm:"column:direction_match"`
+	TechMatch          float64 `gorm:"column:tech_match"`
+	MemberOverlap      float64 `gorm:"column:member_overlap"`
+	Activity           float64 `gorm:"column:activity"`
+	CommonDirections   string  `gorm:"column:common_directions"`
+	CommonTechnologies string  `gorm:"column:common_technologies"`
+	SharedMembers      int     `gorm:"column:shared_members"`
+	RecentPosts        int     `gorm:"column:recent_posts"`
+}
+
+// ClubRecommendationWeights weighs the components of a club recommendation score
+type ClubRecommendationWeights struct {
+	Direction, Tech, Overlap, Activity float64
+}
+
+// GetClubRecommendationsForUser ranks non-archived clubs the user has not joined, does not own and is not banned from:
+// DirectionMatch = |ClubDirs ∩ UserDirs| / |ClubDirs|
+// TechMatch      = |UserTech ∩ tech of the club's directions| / |UserTech|
+// MemberOverlap  = subscribers shared with the user's clubs / subscribers_count
+// Activity       = n / (n + 3), n = posts created since `since`
+func GetClubRecommendationsForUser(userID uint, w ClubRecommendationWeights, since time.Time, limit int) ([]ClubRecommendationRow, error) {
+	q := `
+		WITH my_clubs AS (
+			SELECT club_id FROM club_subscribers WHERE user_id = @uid
+		),
+		my_tech AS (
+			SELECT technology_id FROM user_technologies WHERE user_id = @uid
+		),
+		candidates AS (
+			SELECT c.id, c.subscribers_count
+			FROM clubs c
+			WHERE c.archived_at IS NULL
+			  AND c.creator_id IS DISTINCT FROM @uid
+			  AND c.id NOT IN (SELECT club_id FROM my_clubs)
+			  AND NOT EXISTS (SELECT 1 FROM club_bans b WHERE b.club_id = c.id AND b.user_id = @uid)
+		),
+		dir AS (
+			SELECT cd.club_id,
+				   COUNT(*) AS total,
+				   COUNT(ud.direction_id) AS common,
+				   STRING_AGG(d.name, ', ' ORDER BY d.name) FILTER (WHERE ud.direction_id IS NOT NULL) AS common_names
+			FROM club_directions cd
+			JOIN candidates ca ON ca.id = cd.club_id
+			JOIN directions d ON d.id = cd.direction_id
+			LEFT JOIN user_directions ud ON ud.direction_id = cd.direction_id AND ud.user_id = @uid
+			GROUP BY cd.club_id
+		),
+		tech AS (
+			SELECT cd.club_id,
+				   COUNT(DISTINCT t.id) AS common,
+				   STRING_AGG(DISTINCT t.name, ', ' ORDER BY t.name) AS common_names
+			FROM club_directions cd
+			JOIN candidates ca ON ca.id = cd.club_id
+			JOIN direction_technologies dt ON dt.direction_id = cd.direction_id
+			JOIN my_tech mt ON mt.technology_id = dt.technology_id
+			JOIN technologies t ON t.id = dt.technology_id
+			GROUP BY cd.club_id
+		),
+		overlap AS (
+			SELECT cs.club_id, COUNT(DISTINCT cs.user_id) AS shared
+			FROM club_subscribers cs
+			JOIN candidates ca ON ca.id = cs.club_id
+			JOIN club_subscribers mine ON mine.user_id = cs.user_id
+			JOIN my_clubs mc ON mc.club_id = mine.club_id
+			WHERE cs.user_id <> @uid
+			GROUP BY cs.club_id
+		),
+		activity AS (
+			SELECT p.club_id, COUNT(*) AS recent_posts
+			FROM posts p
+			JOIN candidates ca ON ca.id = p.club_id
+			WHERE p.created_at >= @since
+			GROUP BY p.club_id
+		),
+		parts AS (
+			SELECT ca.id AS club_id,
+				   COALESCE(dir.common::float / NULLIF(dir.total, 0), 0) AS direction_match,
+				   COALESCE(tech.common::float / NULLIF((SELECT COUNT(*) FROM my_tech), 0), 0) AS tech_match,
+				   LEAST(COALESCE(overlap.shared::float / NULLIF(ca.subscribers_count, 0), 0), 1) AS member_overlap,
+				   COALESCE(activity.recent_posts, 0)::float / (COALESCE(activity.recent_posts, 0) + 3) AS activity,
+				   COALESCE(dir.common_names, '') AS common_directions,
+				   COALESCE(tech.common_names, '') AS common_technologies,
+				   COALESCE(overlap.shared, 0) AS shared_members,
+				   COALESCE(activity.recent_posts, 0) AS recent_posts
+			FROM candidates ca
+			LEFT JOIN dir ON dir.club_id = ca.id
+			LEFT JOIN tech ON tech.club_id = ca.id
+			LEFT JOIN overlap ON overlap.club_id = ca.id
+			LEFT JOIN activity ON activity.club_id = ca.id
+		)
+		SELECT parts.*,
+			   (@wd * direction_match + @wt * tech_match + @wo * member_overlap + @wa * activity) AS score
+		FROM parts
+		ORDER BY score DESC, club_id ASC
+		LIMIT @limit`
+
+	var rows []ClubRecommendationRow
+	err := db.DB.Raw(q, map[string]any{
+		"uid":   userID,
+		"since": since,
+		"wd":    w.Direction,
+		"wt":    w.Tech,
+		"wo":    w.Overlap,
+		"wa":    w.Activity,
+		"limit": limit,
+	}).Scan(&rows).Error
+	return rows, err
+}
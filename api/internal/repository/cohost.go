@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"errors"
+	"mosprom/api/internal/db"
+	"mosprom/api/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrAlreadyCoHost is returned when inviting the host club or a club already invited or co-hosting
+	ErrAlreadyCoHost = errors.New("club already hosts the post or is invited")
+	// ErrCoHostResponded is returned when answering a co-host invitation twice
+	ErrCoHostResponded = errors.New("co-host invitation is already answered")
+)
+
+// hostedByClub is a condition on the posts table aliased as alias matching posts hosted or co-hosted
+// by the club bound to the @club named parameter
+func hostedByClub(alias string) string {
+	return "(" + alias + ".club_id = @club OR EXISTS (SELECT 1 FROM post_co_hosts h WHERE h.post_id = " + alias +
+		".id AND h.club_id = @club AND h.status = '" + string(model.CoHostAccepted) + "'))"
+}
+
+// preloadCoHosts loads accepted co-host clubs of the queried posts
+func preloadCoHosts(q *gorm.DB) *gorm.DB {
+	return q.Preload("CoHosts", "status = ?", model.CoHostAccepted).Preload("CoHosts.Club")
+}
+
+// InvitePostCoHost invites the club to co-host the post. A club that declined before can be invited again.
+func InvitePostCoHost(postID, clubID, invitedByID uint) (model.PostCoHost, error) {
+	var h model.PostCoHost
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var post model.Post
+		if err := tx.Select("id", "club_id").First(&post, postID).Error; err != nil {
+			return err
+		}
+		if post.ClubID == clubID {
+			return ErrAlreadyCoHost
+		}
+		var club model.Club
+		if err := tx.Select("id", "archived_at").First(&club, clubID).Error; err != nil {
+			return err
+		}
+		if club.ArchivedAt != nil {
+			return ErrClubArchived
+		}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("post_id = ? AND club_id = ?", postID, clubID).First(&h).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			h = model.PostCoHost{PostID: postID, ClubID: clubID, Status: model.CoHostPending, InvitedByID: invitedByID}
+			return tx.Create(&h).Error
+		case err != nil:
+			return err
+		case h.Status != model.CoHostDeclined:
+			return ErrAlreadyCoHost
+		}
+		h.Status = model.CoHostPending
+		h.InvitedByID = invitedByID
+		h.RespondedAt = nil
+		return tx.Model(&model.PostCoHost{}).Where("id = ?", h.ID).
+			Updates(map[string]any{"status": h.Status, "invited_by_id": invitedByID, "responded_at": nil}).Error
+	})
+	return h, err
+}
+
+// GetPostCoHosts returns co-host invitations of the post in any status
+func GetPostCoHosts(postID uint) ([]model.PostCoHost, error) {
+	var items []model.PostCoHost
+	err := db.DB.Preload("Club").Where("post_id = ?", postID).Order("created_at ASC, id ASC").Find(&items).Error
+	return items, err
+}
+
+// GetClubCoHostings returns newest-first co-host invitations addressed to the club, optionally by status
+func GetClubCoHostings(clubID uint, status model.CoHostStatus) ([]model.PostCoHost, error) {
+	var items []model.PostCoHost
+	q := db.DB.Preload("Post").Preload("Post.Club").Where("club_id = ?", clubID)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	err := q.Order("created_at DESC, id DESC").Find(&items).Error
+	return items, err
+}
+
+// RespondToPostCoHost accepts or declines the club's pending co-host invitation
+func RespondToPostCoHost(clubID, postID uint, accept bool, at time.Time) (model.PostCoHost, error) {
+	var h model.PostCoHost
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("post_id = ? AND club_id = ?", postID, clubID).First(&h).Error; err != nil {
+			return err
+		}
+		if h.Status != model.CoHostPending {
+			return ErrCoHostResponded
+		}
+		h.Status = model.CoHostDeclined
+		if accept {
+			h.Status = model.CoHostAccepted
+		}
+		h.RespondedAt = &at
+		return tx.Model(&model.PostCoHost{}).Where("id = ?", h.ID).
+			Updates(map[string]any{"status": h.Status, "responded_at": at}).Error
+	})
+	return h, err
+}
+
+// RemovePostCoHost withdraws the invitation or ends co-hosting
+func RemovePostCoHost(postID, clubID uint) error {
+	res := db.DB.Where("post_id = ? AND club_id = ?", postID, clubID).Delete(&model.PostCoHost{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"mosprom/api/internal/db"
+	"time"
+)
+
+// FeedRow holds the score components of one feed item
+type FeedRow struct {
+	PostID     uint    `gorm:"column:post_id"`
+	Score      float64 `gorm:"column:score"`
+	Recency    float64 `gorm:"column:recency"`
+	TechMatch  float64 `gorm:"column:tech_match"`
+	Engagement int     `gorm:"column:engagement"`
+	Subscribed bool    `gorm:"column:subscribed"`
+}
+
+// FeedWeights weighs the components of a feed score
+type FeedWeights struct {
+	Recency, Tech, Engagement, Subscribed float64
+}
+
+// FeedQuery describes one page of a user's feed. Everything is evaluated as of AsOf, so pages of the
+// same scroll session (same AsOf) are ranked identically and keyset pagination neither repeats nor skips items.
+type FeedQuery struct {
+	UserID        uint
+	AsOf          time.Time
+	FreshSince    time.Time // subscribed clubs' posts older than this are left out
+	TrendingSince time.Time // likes and joins since this count as engagement
+	RecencyHours  float64   // time constant of the recency decay
+	Weights       FeedWeights
+	AfterScore    float64 // keyset: last item of the previous page, AfterPostID 0 for the first page
+	AfterPostID   uint
+	Limit         int
+}
+
+// GetFeedPage ranks posts for the user's feed, merging three sources:
+// new posts hosted or co-hosted by subscribed clubs, posts matching the user's technologies and trending posts.
+// Recency    = exp(-age / RecencyHours)
+// TechMatch  = |UserTech ∩ PostTech| / |PostTech|
+// Engagement = likes + 2 * joins since TrendingSince, normalised as e / (e + 10)
+// Posts of archived clubs, clubs the user is banned from and posts the user is too young for are left out.
+func GetFeedPage(q FeedQuery) ([]FeedRow, error) {
+	sql := `
+		WITH my_clubs AS (
+			SELECT club_id FROM club_subscribers WHERE user_id = @uid AND created_at <= @as_of
+		),
+		my_tech AS (
+			SELECT technology_id FROM user_technologies WHERE user_id = @uid AND created_at <= @as_of
+		),
+		tech AS (
+			SELECT pt.post_id, COUNT(mt.technology_id)::float / COUNT(*) AS tech_match
+			FROM post_technologies pt
+			LEFT JOIN my_tech mt ON mt.technology_id = pt.technology_id
+			GROUP BY pt.post_id
+		),
+		eng AS (
+			SELECT post_id, SUM(w) AS engagement
+			FROM (
+				SELECT post_id, 1 AS w FROM likes WHERE created_at > @trending_since AND created_at <= @as_of
+				UNION ALL
+				SELECT post_id, 2 FROM post_participants WHERE created_at > @trending_since AND created_at <= @as_of
+			) e
+			GROUP BY post_id
+		),
+		candidates AS (
+			SELECT p.id AS post_id,
+				   EXP(-LEAST(GREATEST(EXTRACT(EPOCH FROM (@as_of::timestamptz - p.created_at)), 0) / 3600.0 / @tau, 700)) AS recency,
+				   COALESCE(t.tech_match, 0) AS tech_match,
+				   COALESCE(e.engagement, 0) AS engagement,
+				   (p.club_id IN (SELECT club_id FROM my_clubs) OR EXISTS (
+						SELECT 1 FROM post_co_hosts h
+						WHERE h.post_id = p.id AND h.status = 'accepted' AND h.club_id IN (SELECT club_id FROM my_clubs)
+				   )) AS subscribed,
+				   p.created_at
+			FROM posts p
+			JOIN clubs c ON c.id = p.club_id
+			LEFT JOIN tech t ON t.post_id = p.id
+			LEFT JOIN eng e ON e.post_id = p.id
+			WHERE p.created_at <= @as_of
+			  AND c.archived_at IS NULL
+			  AND NOT EXISTS (SELECT 1 FROM club_bans b WHERE b.club_id = p.club_id AND b.user_id = @uid)
+			  AND ` + ageEligibleSQL("p", "uid") + `
+		),
+		scored AS (
+			SELECT post_id, recency, tech_match, engagement, subscribed,
+				   @wr * recency
+				   + @wt * tech_match
+				   + @we * (engagement::float / (engagement + 10))
+				   + @ws * (CASE WHEN subscribed THEN 1 ELSE 0 END) AS score
+			FROM candidates
+			WHERE (subscribed AND created_at > @fresh_since) OR tech_match > 0 OR engagement > 0
+		)
+		SELECT * FROM scored
+		WHERE @after_id = 0 OR (score, post_id) < (@after_score::float8, @after_id)
+		ORDER BY score DESC, post_id DESC
+		LIMIT @limit`
+
+	var rows []FeedRow
+	err := db.DB.Raw(sql, map[string]any{
+		"uid":            q.UserID,
+		"as_of":          q.AsOf,
+		"fresh_since":    q.FreshSince,
+		"trending_since": q.TrendingSince,
+		"tau":            q.RecencyHours,
+		"wr":             q.Weights.Recency,
+		"wt":             q.Weights.Tech,
+		"we":             q.Weights.Engagement,
+		"ws":             q.Weights.Subscribed,
+		"after_score":    q.AfterScore,
+		"after_id":       q.AfterPostID,
+		"limit":          q.Limit,
+	}).Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"mosprom/api/internal/db"
+	"mosprom/api/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ImportedPost is a post to create by ImportPosts with the names of its technologies
+type ImportedPost struct {
+	Post         model.Post
+	Technologies []string
+}
+
+// GetImportedPostIDs maps the import keys already used in the club to their posts
+func GetImportedPostIDs(clubID uint, keys []string) (map[string]uint, error) {
+	out := map[string]uint{}
+	if len(keys) == 0 {
+		return out, nil
+	}
+	var rows []struct {
+		ID        uint
+		ImportKey string
+	}
+	err := db.DB.Model(&model.Post{}).Select("id, import_key").
+		Where("club_id = ? AND import_key IN ?", clubID, keys).Scan(&rows).Error
+	for _, r := range rows {
+		out[r.ImportKey] = r.ID
+	}
+	return out, err
+}
+
+// ImportPosts creates the posts in one transaction. A post whose import key was taken in the
+// meantime is skipped and keeps a zero ID.
+func ImportPosts(items []ImportedPost) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		for i := range items {
+			post := &items[i].Post
+			res := tx.Clauses(clause.OnConflict{DoNothing: true}).Omit(clause.Associations).Create(post)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				post.ID = 0
+				continue
+			}
+			techs, err := findOrCreateTechnologiesByNames(tx, items[i].Technologies)
+			if err != nil {
+				return err
+			}
+			for _, t := range techs {
+				if err := tx.Exec("INSERT INTO post_technologies (post_id, technology_id) VALUES (?, ?) ON CONFLICT DO NOTHING", post.ID, t.ID).Error; err != nil {
+					return err
+				}
+			}
+			if err := recordEvent(tx, model.EventPostCreated, model.EventPayload{PostID: post.ID, ClubID: post.ClubID, PostType: post.Type}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"errors"
+	"mosprom/api/internal/db"
+	"mosprom/api/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvitationResponded is returned when accepting or declining an invitation twice
+var ErrInvitationResponded = errors.New("invitation is already answered")
+
+// Reasons an invitee was skipped by CreateInvitations
+const (
+	InviteSkipUnknownUser    = "unknown_user"
+	InviteSkipAlreadyInvited = "already_invited"
+	InviteSkipAlreadyMember  = "already_member"
+	InviteSkipBanned         = "banned"
+)
+
+// CreateInvitations stores one pending invitation per user from the template (ClubID, PostID, InvitedByID, Message).
+// Users that do not exist, are banned, already take part or were already invited are skipped with a reason.
+func CreateInvitations(tmpl model.Invitation, userIDs []uint) ([]model.Invitation, map[uint]string, error) {
+	var created []model.Invitation
+	var skipped map[uint]string
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		created, skipped, err = createInvitations(tx, tmpl, userIDs)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return created, skipped, nil
+}
+
+func createInvitations(tx *gorm.DB, tmpl model.Invitation, userIDs []uint) ([]model.Invitation, map[uint]string, error) {
+	created := make([]model.Invitation, 0, len(userIDs))
+	skipped := make(map[uint]string)
+	for _, uid := range userIDs {
+		if _, seen := skipped[uid]; seen {
+			continue
+		}
+		var users int64
+		if err := tx.Model(&model.User{}).Where("id = ?", uid).Count(&users).Error; err != nil {
+			return nil, nil, err
+		}
+		if users == 0 {
+			skipped[uid] = InviteSkipUnknownUser
+			continue
+		}
+		banned, err := isBannedFromClub(tx, tmpl.ClubID, uid)
+		if err != nil {
+			return nil, nil, err
+		}
+		if banned {
+			skipped[uid] = InviteSkipBanned
+			continue
+		}
+		var member bool
+		if tmpl.PostID != nil {
+			var cnt int64
+			err = tx.Table("post_participants").Where("post_id = ? AND user_id = ?", *tmpl.PostID, uid).Count(&cnt).Error
+			member = cnt > 0
+		} else {
+			member, err = isClubSubscriber(tx, tmpl.ClubID, uid)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if member {
+			skipped[uid] = InviteSkipAlreadyMember
+			continue
+		}
+
+		inv := tmpl
+		inv.ID = 0
+		inv.UserID = uid
+		inv.Status = model.InvitationPending
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&inv)
+		if res.Error != nil {
+			return nil, nil, res.Error
+		}
+		if res.RowsAffected == 0 {
+			skipped[uid] = InviteSkipAlreadyInvited
+			continue
+		}
+		if err := recordEvent(tx, model.EventInvitationCreated, invitationPayload(inv)); err != nil {
+			return nil, nil, err
+		}
+		created = append(created, inv)
+	}
+	return created, skipped, nil
+}
+
+func invitationPayload(inv model.Invitation) model.EventPayload {
+	p := model.EventPayload{ClubID: inv.ClubID, UserID: inv.UserID}
+	if inv.PostID != nil {
+		p.PostID = *inv.PostID
+	}
+	return p
+}
+
+// GetClubInvitations returns newest-first invitations of the club, optionally filtered by post and status
+func GetClubInvitations(clubID uint, postID *uint, status model.InvitationStatus) ([]model.Invitation, error) {
+	var items []model.Invitation
+	q := db.DB.Preload("User").Where("club_id = ?", clubID)
+	if postID != nil {
+		q = q.Where("post_id = ?", *postID)
+	}
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	err := q.Order("created_at DESC, id DESC").Find(&items).Error
+	return items, err
+}
+
+// GetUserInvitations returns newest-first invitations addressed to the user
+func GetUserInvitations(userID uint, status model.InvitationStatus) ([]model.Invitation, error) {
+	var items []model.Invitation
+	q := db.DB.Preload("Club").Preload("Post").Where("user_id = ?", userID)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	err := q.Order("created_at DESC, id DESC").Find(&items).Error
+	return items, err
+}
+
+// RespondToInvitation accepts or declines the user's pending invitation. Accepting joins the post
+// or subscribes to the club (bypassing join requests) in the same transaction.
+func RespondToInvitation(userID, invitationID uint, accept bool) (model.Invitation, error) {
+	var inv model.Invitation
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", invitationID, userID).First(&inv).Error; err != nil {
+			return err
+		}
+		if inv.Status != model.InvitationPending {
+			return ErrInvitationResponded
+		}
+		eventType := model.EventInvitationDeclined
+		inv.Status = model.InvitationDeclined
+		if accept {
+			var err error
+			if inv.PostID != nil {
+				_, err = joinUserToPost(tx, userID, *inv.PostID)
+			} else {
+				err = subscribeUserToClub(tx, userID, inv.ClubID, true)
+			}
+			if err != nil {
+				return err
+			}
+			eventType = model.EventInvitationAccepted
+			inv.Status = model.InvitationAccepted
+		}
+		now := time.Now()
+		inv.RespondedAt = &now
+		if err := tx.Model(&model.Invitation{}).Where("id = ?", inv.ID).
+			Updates(map[string]any{"status": inv.Status, "responded_at": now}).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, eventType, invitationPayload(inv))
+	})
+	return inv, err
+}
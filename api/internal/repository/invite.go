@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"errors"
+	"mosprom/api/internal/db"
+	"mosprom/api/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInviteUnusable is returned for revoked, expired or used-up invites
+var ErrInviteUnusable = errors.New("invite is revoked, expired or used up")
+
+func CreateClubInvite(invite *model.ClubInvite) error {
+	return db.DB.Create(invite).Error
+}
+
+// GetClubInvites returns newest-first invites of the club, including revoked and expired ones
+func GetClubInvites(clubID uint) ([]model.ClubInvite, error) {
+	var items []model.ClubInvite
+	err := db.DB.Where("club_id = ?", clubID).Order("created_at DESC, id DESC").Find(&items).Error
+	return items, err
+}
+
+// RevokeClubInvite marks the invite revoked; returns gorm.ErrRecordNotFound if it is not the club's
+func RevokeClubInvite(clubID, inviteID uint) error {
+	res := db.DB.Model(&model.ClubInvite{}).
+		Where("id = ? AND club_id = ? AND revoked_at IS NULL", inviteID, clubID).
+		Update("revoked_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		var cnt int64
+		if err := db.DB.Model(&model.ClubInvite{}).Where("id = ? AND club_id = ?", inviteID, clubID).Count(&cnt).Error; err != nil {
+			return err
+		}
+		if cnt == 0 {
+			return gorm.ErrRecordNotFound
+		}
+	}
+	return nil
+}
+
+// RedeemClubInvite subscribes the user with the invite's role and counts the use. The invite row is locked,
+// so concurrent redeems cannot exceed MaxUses. Pending join requests of the user are marked approved.
+func RedeemClubInvite(token string, userID uint, now time.Time) (model.ClubInvite, error) {
+	var invite model.ClubInvite
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("token = ?", token).First(&invite).Error; err != nil {
+			return err
+		}
+		if !invite.Usable(now) {
+			return ErrInviteUnusable
+		}
+		var club model.Club
+		if err := tx.First(&club, invite.ClubID).Error; err != nil {
+			return err
+		}
+		if club.ArchivedAt != nil {
+			return ErrClubArchived
+		}
+		if err := tx.First(&model.User{}, userID).Error; err != nil {
+			return err
+		}
+		banned, err := isBannedFromClub(tx, invite.ClubID, userID)
+		if err != nil {
+			return err
+		}
+		if banned {
+			return ErrBannedFromClub
+		}
+		added, err := addClubSubscriber(tx, invite.ClubID, userID)
+		if err != nil {
+			return err
+		}
+		if !added {
+			return ErrAlreadyMember
+		}
+		if invite.Role != model.ClubRoleMember {
+			if err := tx.Exec("UPDATE club_subscribers SET role = ? WHERE club_id = ? AND user_id = ?", invite.Role, invite.ClubID, userID).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&model.ClubJoinRequest{}).
+			Where("club_id = ? AND user_id = ? AND status = ?", invite.ClubID, userID, model.JoinRequestPending).
+			Updates(map[string]any{"status": model.JoinRequestApproved, "decided_by_id": invite.CreatedByID, "decided_at": now}).Error; err != nil {
+			return err
+		}
+		invite.Uses++
+		return tx.Model(&model.ClubInvite{}).Where("id = ?", invite.ID).UpdateColumn("uses", gorm.Expr("uses + 1")).Error
+	})
+	return invite, err
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"mosprom/api/internal/db"
+	"mosprom/api/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrJobRunning is returned when trying to requeue a job that a worker currently holds
+var ErrJobRunning = errors.New("job is running")
+
+func CreateJob(job *model.Job) error {
+	return db.DB.Create(job).Error
+}
+
+// ClaimJob locks the next due job of one of the given types and marks it running for workerID.
+// A running job whose lock is older than lease is treated as abandoned by a crashed worker and claimed again.
+// Returns nil when there is nothing to do.
+func ClaimJob(workerID string, types []string, now time.Time, lease time.Duration) (*model.Job, error) {
+	if len(types) == 0 {
+		return nil, nil
+	}
+	var claimed *model.Job
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var job model.Job
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("type IN ?", types).
+			Where("(status = ? AND run_at <= ?) OR (status = ? AND locked_at < ?)",
+				model.JobQueued, now, model.JobRunning, now.Add(-lease)).
+			Order("run_at ASC, id ASC").
+			First(&job).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&job).Updates(map[string]any{
+			"status":    model.JobRunning,
+			"locked_by": workerID,
+			"locked_at": now,
+			"attempts":  gorm.Expr("attempts + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		job.Status = model.JobRunning
+		job.LockedBy = workerID
+		job.LockedAt = &now
+		job.Attempts++
+		claimed = &job
+		return nil
+	})
+	return claimed, err
+}
+
+// CompleteJob marks a job done if workerID still holds it
+func CompleteJob(id uint, workerID string) error {
+	now := time.Now()
+	return db.DB.Model(&model.Job{}).
+		Where("id = ? AND status = ? AND locked_by = ?", id, model.JobRunning, workerID).
+		Updates(map[string]any{"status": model.JobDone, "finished_at": now, "last_error": ""}).Error
+}
+
+// FailJob records a failed attempt. With retryAt the job goes back to the queue, without it the job is dead-lettered.
+func FailJob(id uint, workerID string, errMsg string, retryAt *time.Time) error {
+	updates := map[string]any{"last_error": errMsg, "locked_by": "", "locked_at": nil}
+	if retryAt != nil {
+		updates["status"] = model.JobQueued
+		updates["run_at"] = *retryAt
+	} else {
+		updates["status"] = model.JobDead
+		updates["finished_at"] = time.Now()
+	}
+	return db.DB.Model(&model.Job{}).
+		Where("id = ? AND status = ? AND locked_by = ?", id, model.JobRunning, workerID).
+		Updates(updates).Error
+}
+
+// PruneJobs deletes jobs that finished successfully before the cutoff; dead jobs are kept for inspection
+func PruneJobs(before time.Time) (int64, error) {
+	res := db.DB.Where("status = ? AND finished_at < ?", model.JobDone, before).Delete(&model.Job{})
+	return res.RowsAffected, res.Error
+}
+
+// ListJobs returns the newest jobs, optionally filtered by status and type
+func ListJobs(status, jobType string, limit int) ([]model.Job, error) {
+	var jobs []model.Job
+	q := db.DB.Model(&model.Job{})
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	if jobType != "" {
+		q = q.Where("type = ?", jobType)
+	}
+	err := q.Order("id DESC").Limit(limit).Find(&jobs).Error
+	return jobs, err
+}
+
+func GetJobByID(id uint) (model.Job, error) {
+	var job model.Job
+	err := db.DB.First(&job, id).Error
+	return job, err
+}
+
+// RequeueJob puts a finished, dead or waiting job back to the queue to run now with a fresh attempt budget
+func RequeueJob(id uint) (model.Job, error) {
+	var job model.Job
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&job, id).Error; err != nil {
+			return err
+		}
+		if job.Status == model.JobRunning {
+			return ErrJobRunning
+		}
+		return tx.Model(&job).Updates(map[string]any{
+			"status":      model.JobQueued,
+			"run_at":      time.Now(),
+			"attempts":    0,
+			"locked_by":   "",
+			"locked_at":   nil,
+			"finished_at": nil,
+		}).Error
+	})
+	if err != nil {
+		return model.Job{}, err
+	}
+	return GetJobByID(id)
+}
+
+// UpsertRecurringJob creates or updates a recurring job definition by name.
+// next_run_at is only reset when the schedule itself changes so restarts don't skip or repeat runs.
+func UpsertRecurringJob(rj *model.RecurringJob) error {
+	return db.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "name"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"type":        gorm.Expr("EXCLUDED.type"),
+			"payload":     gorm.Expr("EXCLUDED.payload"),
+			"next_run_at": gorm.Expr("CASE WHEN recurring_jobs.spec <> EXCLUDED.spec THEN EXCLUDED.next_run_at ELSE recurring_jobs.next_run_at END"),
+			"spec":        gorm.Expr("EXCLUDED.spec"),
+		}),
+	}).Create(rj).Error
+}
+
+func ListRecurringJobs() ([]model.RecurringJob, error) {
+	var items []model.RecurringJob
+	err := db.DB.Order("name ASC").Find(&items).Error
+	return items, err
+}
+
+// EnqueueDueRecurringJobs enqueues one job for every due recurring definition and advances its next_run_at.
+// Definitions locked by another instance are skipped, so each run is enqueued once. A definition whose next
+// run cannot be computed is left alone, without a job, and reported in the returned error.
+func EnqueueDueRecurringJobs(now time.Time, maxAttempts int, next func(model.RecurringJob) (time.Time, error)) (int, error) {
+	enqueued := 0
+	var nextErr error
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var due []model.RecurringJob
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("next_run_at <= ?", now).
+			Find(&due).Error; err != nil {
+			return err
+		}
+		for _, rj := range due {
+			nextRun, err := next(rj)
+			if err != nil {
+				nextErr = errors.Join(nextErr, fmt.Errorf("recurring job %s: %w", rj.Name, err))
+				continue
+			}
+			job := model.Job{
+				Type:        rj.Type,
+				Payload:     rj.Payload,
+				Status:      model.JobQueued,
+				RunAt:       now,
+				MaxAttempts: maxAttempts,
+			}
+			if err := tx.Create(&job).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&model.RecurringJob{}).Where("id = ?", rj.ID).
+				Updates(map[string]any{"last_run_at": now, "next_run_at": nextRun}).Error; err != nil {
+				return err
+			}
+			enqueued++
+		}
+		return nil
+	})
+	if err != nil {
+		return enqueued, err
+	}
+	return enqueued, nextErr
+}
@@ -3,14 +3,35 @@ package repository
 import (
 	"mosprom/api/internal/db"
 	"mosprom/api/internal/model"
+
+	"gorm.io/gorm"
 )
 
 func CreateLike(like *model.Like) error {
-	return db.DB.Create(like).Error
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(like).Error; err != nil {
+			return err
+		}
+		var post model.Post
+		if err := tx.Select("id, club_id, type").First(&post, like.PostID).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, model.EventPostLiked, model.EventPayload{PostID: post.ID, ClubID: post.ClubID, UserID: like.UserID, PostType: post.Type})
+	})
 }
 
 func DeleteLike(userID, postID uint) error {
-	return db.DB.Where("user_id = ? AND post_id = ?", userID, postID).Delete(&model.Like{}).Error
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("user_id = ? AND post_id = ?", userID, postID).Delete(&model.Like{})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		var post model.Post
+		if err := tx.Select("id, club_id, type").First(&post, postID).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, model.EventPostUnliked, model.EventPayload{PostID: post.ID, ClubID: post.ClubID, UserID: userID, PostType: post.Type})
+	})
 }
 
 func GetLikeByUserAndPost(userID, postID uint) (model.Like, error) {
@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"errors"
+	"mosprom/api/internal/db"
+	"mosprom/api/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrBannedFromClub      = errors.New("user is banned from this club")
+	ErrJoinRequestRequired = errors.New("club accepts members by join request only")
+	ErrJoinRequestPending  = errors.New("join request is already pending")
+	ErrJoinRequestDecided  = errors.New("join request is already decided")
+	ErrAlreadyMember       = errors.New("user is already a club member")
+)
+
+// addClubSubscriber inserts the subscription and bumps counters; returns false if the user was already subscribed
+func addClubSubscriber(tx *gorm.DB, clubID, userID uint) (bool, error) {
+	res := tx.Exec("INSERT INTO club_subscribers (club_id, user_id) VALUES (?, ?) ON CONFLICT DO NOTHING", clubID, userID)
+	if res.Error != nil || res.RowsAffected == 0 {
+		return false, res.Error
+	}
+	if err := tx.Model(&model.Club{}).Where("id = ?", clubID).UpdateColumn("subscribers_count", gorm.Expr("subscribers_count + 1")).Error; err != nil {
+		return false, err
+	}
+	if err := tx.Model(&model.User{}).Where("id = ?", userID).UpdateColumn("clubs_count", gorm.Expr("clubs_count + 1")).Error; err != nil {
+		return false, err
+	}
+	return true, recordEvent(tx, model.EventUserSubscribedToClub, model.EventPayload{ClubID: clubID, UserID: userID})
+}
+
+// removeClubSubscriber deletes the subscription and decrements counters; returns false if the user was not subscribed
+func removeClubSubscriber(tx *gorm.DB, clubID, userID uint) (bool, error) {
+	res := tx.Exec("DELETE FROM club_subscribers WHERE club_id = ? AND user_id = ?", clubID, userID)
+	if res.Error != nil || res.RowsAffected == 0 {
+		return false, res.Error
+	}
+	if err := tx.Model(&model.Club{}).Where("id = ?", clubID).UpdateColumn("subscribers_count", gorm.Expr("GREATEST(subscribers_count - 1, 0)")).Error; err != nil {
+		return false, err
+	}
+	if err := tx.Model(&model.User{}).Where("id = ?", userID).UpdateColumn("clubs_count", gorm.Expr("GREATEST(clubs_count - 1, 0)")).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func isClubSubscriber(tx *gorm.DB, clubID, userID uint) (bool, error) {
+	var cnt int64
+	err := tx.Table("club_subscribers").Where("club_id = ? AND user_id = ?", clubID, userID).Count(&cnt).Error
+	return cnt > 0, err
+}
+
+func isBannedFromClub(tx *gorm.DB, clubID, userID uint) (bool, error) {
+	var cnt int64
+	err := tx.Model(&model.ClubBan{}).Where("club_id = ? AND user_id = ?", clubID, userID).Count(&cnt).Error
+	return cnt > 0, err
+}
+
+// IsUserBannedFromClub reports whether the user is banned from the club
+func IsUserBannedFromClub(clubID, userID uint) (bool, error) {
+	return isBannedFromClub(db.DB, clubID, userID)
+}
+
+// UnsubscribeUserFromClub removes the subscription; returns gorm.ErrRecordNotFound if the user was not subscribed
+func UnsubscribeUserFromClub(userID, clubID uint) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		removed, err := removeClubSubscriber(tx, clubID, userID)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return gorm.ErrRecordNotFound
+		}
+		return recordEvent(tx, model.EventClubUnsubscribed, model.EventPayload{ClubID: clubID, UserID: userID})
+	})
+}
+
+// KickClubMember removes a subscriber; returns gorm.ErrRecordNotFound if the user was not subscribed
+func KickClubMember(clubID, userID uint, reason string) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		removed, err := removeClubSubscriber(tx, clubID, userID)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return gorm.ErrRecordNotFound
+		}
+		return recordEvent(tx, model.EventClubMemberKicked, model.EventPayload{ClubID: clubID, UserID: userID, Reason: reason})
+	})
+}
+
+// BanClubMember stores (or updates) the ban, drops the subscription and rejects pending join requests
+func BanClubMember(ban *model.ClubBan) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&model.User{}, ban.UserID).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "club_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"reason", "banned_by_id"}),
+		}).Create(ban).Error; err != nil {
+			return err
+		}
+		if _, err := removeClubSubscriber(tx, ban.ClubID, ban.UserID); err != nil {
+			return err
+		}
+		now := time.Now()
+		if err := tx.Model(&model.ClubJoinRequest{}).
+			Where("club_id = ? AND user_id = ? AND status = ?", ban.ClubID, ban.UserID, model.JoinRequestPending).
+			Updates(map[string]any{"status": model.JoinRequestRejected, "decided_by_id": ban.BannedByID, "decided_at": now}).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, model.EventClubMemberBanned, model.EventPayload{ClubID: ban.ClubID, UserID: ban.UserID, Reason: ban.Reason})
+	})
+}
+
+// UnbanClubMember lifts a ban; returns gorm.ErrRecordNotFound if there was none
+func UnbanClubMember(clubID, userID uint) error {
+	res := db.DB.Where("club_id = ? AND user_id = ?", clubID, userID).Delete(&model.ClubBan{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetClubBans returns newest-first bans of the club
+func GetClubBans(clubID uint) ([]model.ClubBan, error) {
+	var bans []model.ClubBan
+	err := db.DB.Preload("User").Where("club_id = ?", clubID).Order("created_at DESC, id DESC").Find(&bans).Error
+	return bans, err
+}
+
+// CreateClubJoinRequest files a pending request for a non-archived club the user is neither a member of nor banned from
+func CreateClubJoinRequest(req *model.ClubJoinRequest) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		var club model.Club
+		if err := tx.First(&club, req.ClubID).Error; err != nil {
+			return err
+		}
+		if club.ArchivedAt != nil {
+			return ErrClubArchived
+		}
+		if err := tx.First(&model.User{}, req.UserID).Error; err != nil {
+			return err
+		}
+		banned, err := isBannedFromClub(tx, req.ClubID, req.UserID)
+		if err != nil {
+			return err
+		}
+		if banned {
+			return ErrBannedFromClub
+		}
+		member, err := isClubSubscriber(tx, req.ClubID, req.UserID)
+		if err != nil {
+			return err
+		}
+		if member {
+			return ErrAlreadyMember
+		}
+		var pending int64
+		if err := tx.Model(&model.ClubJoinRequest{}).
+			Where("club_id = ? AND user_id = ? AND status = ?", req.ClubID, req.UserID, model.JoinRequestPending).
+			Count(&pending).Error; err != nil {
+			return err
+		}
+		if pending > 0 {
+			return ErrJoinRequestPending
+		}
+		req.Status = model.JoinRequestPending
+		if err := tx.Create(req).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, model.EventClubJoinRequested, model.EventPayload{ClubID: req.ClubID, UserID: req.UserID})
+	})
+}
+
+// GetClubJoinRequests returns oldest-first join requests of the club, optionally filtered by status
+func GetClubJoinRequests(clubID uint, status model.JoinRequestStatus) ([]model.ClubJoinRequest, error) {
+	var items []model.ClubJoinRequest
+	q := db.DB.Preload("User").Where("club_id = ?", clubID)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	err := q.Order("created_at ASC, id ASC").Find(&items).Error
+	return items, err
+}
+
+// DecideClubJoinRequest approves (subscribing the user) or rejects a pending request of the club
+func DecideClubJoinRequest(clubID, requestID, actorID uint, approve bool) (model.ClubJoinRequest, error) {
+	var req model.ClubJoinRequest
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND club_id = ?", requestID, clubID).First(&req).Error; err != nil {
+			return err
+		}
+		if req.Status != model.JoinRequestPending {
+			return ErrJoinRequestDecided
+		}
+		now := time.Now()
+		req.DecidedByID = &actorID
+		req.DecidedAt = &now
+		if approve {
+			banned, err := isBannedFromClub(tx, clubID, req.UserID)
+			if err != nil {
+				return err
+			}
+			if banned {
+				return ErrBannedFromClub
+			}
+			req.Status = model.JoinRequestApproved
+			if _, err := addClubSubscriber(tx, clubID, req.UserID); err != nil {
+				return err
+			}
+		} else {
+			req.Status = model.JoinRequestRejected
+			if err := recordEvent(tx, model.EventClubJoinRejected, model.EventPayload{ClubID: clubID, UserID: req.UserID}); err != nil {
+				return err
+			}
+		}
+		return tx.Model(&model.ClubJoinRequest{}).Where("id = ?", req.ID).
+			Updates(map[string]any{"status": req.Status, "decided_by_id": actorID, "decided_at": now}).Error
+	})
+	return req, err
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"mosprom/api/internal/db"
+	"mosprom/api/internal/model"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// CreateNotifications stores notifications, skipping ones already created for the same user and event
+func CreateNotifications(notifications []model.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return db.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&notifications).Error
+}
+
+// GetUserNotifications returns newest-first notifications of a user, optionally only unread ones
+func GetUserNotifications(userID uint, unreadOnly bool) ([]model.Notification, error) {
+	var items []model.Notification
+	q := db.DB.Where("user_id = ?", userID)
+	if unreadOnly {
+		q = q.Where("read_at IS NULL")
+	}
+	err := q.Order("created_at DESC, id DESC").Find(&items).Error
+	return items, err
+}
+
+// MarkNotificationRead sets read_at for a notification owned by the user; returns false if nothing matched
+func MarkNotificationRead(userID, notificationID uint) (bool, error) {
+	res := db.DB.Model(&model.Notification{}).
+		Where("id = ? AND user_id = ? AND read_at IS NULL", notificationID, userID).
+		Update("read_at", time.Now())
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 0 {
+		var cnt int64
+		if err := db.DB.Model(&model.Notification{}).Where("id = ? AND user_id = ?", notificationID, userID).Count(&cnt).Error; err != nil {
+			return false, err
+		}
+		return cnt > 0, nil
+	}
+	return true, nil
+}
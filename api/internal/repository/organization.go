@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"errors"
+	"mosprom/api/internal/db"
+	"mosprom/api/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrOrganizationReviewed is returned when reviewing an organization that is not pending
+	ErrOrganizationReviewed = errors.New("organization is not pending verification")
+	// ErrINNClaimed is returned when another pending or verified organization holds the INN
+	ErrINNClaimed = errors.New("inn is already claimed by another organization")
+	// ErrOrganizationReleased is returned when releasing an organization that is already rejected
+	ErrOrganizationReleased = errors.New("organization does not hold its inn")
+)
+
+// CreateOrganization stores a pending organization with ownerID as its first owner
+func CreateOrganization(org *model.Organization, ownerID uint) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		org.Status = model.OrganizationPending
+		if err := tx.Omit("Staff").Create(org).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.OrganizationStaff{OrganizationID: org.ID, UserID: ownerID, Role: model.OrgRoleOwner}).Error
+	})
+}
+
+func GetOrganizationByID(id uint) (model.Organization, error) {
+	var org model.Organization
+	err := db.DB.First(&org, id).Error
+	return org, err
+}
+
+// IsINNClaimed reports whether an organization other than exceptID holds the INN, i.e. is not rejected
+func IsINNClaimed(inn string, exceptID uint) (bool, error) {
+	var count int64
+	err := db.DB.Model(&model.Organization{}).
+		Where("inn = ? AND status <> ? AND id <> ?", inn, model.OrganizationRejected, exceptID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ListOrganizations returns organizations by name, optionally filtered by status
+func ListOrganizations(status model.OrganizationStatus) ([]model.Organization, error) {
+	var items []model.Organization
+	q := db.DB.Model(&model.Organization{})
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	err := q.Order("name ASC, id ASC").Find(&items).Error
+	return items, err
+}
+
+// GetUserOrganizations returns organizations where the user is staff
+func GetUserOrganizations(userID uint) ([]model.Organization, error) {
+	var items []model.Organization
+	err := db.DB.Joins("JOIN organization_staffs s ON s.organization_id = organizations.id").
+		Where("s.user_id = ?", userID).
+		Order("organizations.name ASC").
+		Find(&items).Error
+	return items, err
+}
+
+// UpdateOrganization saves editable fields. With reverify the organization goes back to pending
+// and its clubs lose the badge until an admin checks the new legal details.
+func UpdateOrganization(org *model.Organization, reverify bool) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]any{
+			"name":          org.Name,
+			"legal_name":    org.LegalName,
+			"inn":           org.INN,
+			"ogrn":          org.OGRN,
+			"legal_address": org.LegalAddress,
+			"website":       org.Website,
+			"contact_email": org.ContactEmail,
+		}
+		if reverify {
+			org.Status = model.OrganizationPending
+			org.RejectionReason = ""
+			updates["status"] = org.Status
+			updates["rejection_reason"] = ""
+			if err := tx.Model(&model.Club{}).Where("organization_id = ?", org.ID).Update("verified", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&model.Organization{}).Where("id = ?", org.ID).Updates(updates).Error
+	})
+}
+
+// ReviewOrganization verifies or rejects a pending organization and updates the badge of its clubs
+func ReviewOrganization(orgID, reviewerID uint, verified bool, reason string, at time.Time) (model.Organization, error) {
+	var org model.Organization
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&org, orgID).Error; err != nil {
+			return err
+		}
+		if org.Status != model.OrganizationPending {
+			return ErrOrganizationReviewed
+		}
+		org.Status = model.OrganizationRejected
+		eventType := model.EventOrganizationRejected
+		if verified {
+			org.Status = model.OrganizationVerified
+			eventType = model.EventOrganizationVerified
+			reason = ""
+		}
+		org.RejectionReason = reason
+		org.ReviewedByID = &reviewerID
+		org.ReviewedAt = &at
+		if err := tx.Model(&model.Organization{}).Where("id = ?", org.ID).Updates(map[string]any{
+			"status":           org.Status,
+			"rejection_reason": reason,
+			"reviewed_by_id":   reviewerID,
+			"reviewed_at":      at,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Club{}).Where("organization_id = ?", org.ID).Update("verified", verified).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, eventType, model.EventPayload{OrganizationID: org.ID, UserID: reviewerID, Reason: reason})
+	})
+	return org, err
+}
+
+// ReleaseOrganization rejects a pending or verified organization so its INN can be claimed by another one.
+// Its clubs lose the badge and the staff are told the reason.
+func ReleaseOrganization(orgID, reviewerID uint, reason string, at time.Time) (model.Organization, error) {
+	var org model.Organization
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&org, orgID).Error; err != nil {
+			return err
+		}
+		if org.Status == model.OrganizationRejected {
+			return ErrOrganizationReleased
+		}
+		org.Status = model.OrganizationRejected
+		org.RejectionReason = reason
+		org.ReviewedByID = &reviewerID
+		org.ReviewedAt = &at
+		if err := tx.Model(&model.Organization{}).Where("id = ?", org.ID).Updates(map[string]any{
+			"status":           org.Status,
+			"rejection_reason": reason,
+			"reviewed_by_id":   reviewerID,
+			"reviewed_at":      at,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Club{}).Where("organization_id = ?", org.ID).Update("verified", false).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, model.EventOrganizationRejected, model.EventPayload{OrganizationID: org.ID, UserID: reviewerID, Reason: reason})
+	})
+	return org, err
+}
+
+// GetOrganizationStaff returns staff accounts with users, owners first
+func GetOrganizationStaff(orgID uint) ([]model.OrganizationStaff, error) {
+	var items []model.OrganizationStaff
+	err := db.DB.Preload("User").Where("organization_id = ?", orgID).
+		Order("role = 'owner' DESC, created_at ASC").Find(&items).Error
+	return items, err
+}
+
+// GetOrganizationStaffRole returns the user's role in the organization or "" for outsiders
+func GetOrganizationStaffRole(orgID, userID uint) (string, error) {
+	var roles []string
+	err := db.DB.Model(&model.OrganizationStaff{}).Where("organization_id = ? AND user_id = ?", orgID, userID).Limit(1).Pluck("role", &roles).Error
+	if err != nil || len(roles) == 0 {
+		return "", err
+	}
+	return roles[0], nil
+}
+
+// SetOrganizationStaff adds the user to the organization or changes their role
+func SetOrganizationStaff(orgID, userID uint, role string) error {
+	return db.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role"}),
+	}).Create(&model.OrganizationStaff{OrganizationID: orgID, UserID: userID, Role: role}).Error
+}
+
+func RemoveOrganizationStaff(orgID, userID uint) error {
+	res := db.DB.Where("organization_id = ? AND user_id = ?", orgID, userID).Delete(&model.OrganizationStaff{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func CountOrganizationOwners(orgID uint) (int64, error) {
+	var n int64
+	err := db.DB.Model(&model.OrganizationStaff{}).Where("organization_id = ? AND role = ?", orgID, model.OrgRoleOwner).Count(&n).Error
+	return n, err
+}
+
+// SetClubOrganization attaches the club to the organization (nil detaches) and copies its verification badge
+func SetClubOrganization(clubID uint, orgID *uint) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		verified := false
+		if orgID != nil {
+			var org model.Organization
+			if err := tx.Clauses(clause.Locking{Strength: "SHARE"}).Select("id", "status").First(&org, *orgID).Error; err != nil {
+				return err
+			}
+			verified = org.Status == model.OrganizationVerified
+		}
+		res := tx.Model(&model.Club{}).Where("id = ?", clubID).
+			Updates(map[string]any{"organization_id": orgID, "verified": verified})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return recordEvent(tx, model.EventClubUpdated, model.EventPayload{ClubID: clubID})
+	})
+}
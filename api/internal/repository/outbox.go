@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"encoding/json"
+	"mosprom/api/internal/db"
+	"mosprom/api/internal/model"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// recordEvent writes a domain event to the outbox using the caller's transaction
+func recordEvent(tx *gorm.DB, eventType model.EventType, payload model.EventPayload) error {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	e := model.OutboxEvent{
+		Type:          eventType,
+		Payload:       raw,
+		NextAttemptAt: time.Now(),
+	}
+	if payload.ClubID != 0 {
+		clubID := payload.ClubID
+		e.ClubID = &clubID
+	}
+	return tx.Create(&e).Error
+}
+
+// DispatchOutboxEvents locks up to limit pending events (skipping rows locked by other instances) and passes
+// each to deliver. Delivered events are marked dispatched; failed ones are retried at retryAt(attempts)
+// until maxAttempts is reached, remembering the subscribers deliver reports as done so far.
+// A crash before commit leaves the events pending, so delivery is at least once.
+func DispatchOutboxEvents(now time.Time, limit, maxAttempts int, deliver func(model.OutboxEvent) ([]string, error), retryAt func(attempts int) time.Time) (int, error) {
+	dispatched := 0
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var pending []model.OutboxEvent
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("dispatched_at IS NULL AND failed_at IS NULL AND next_attempt_at <= ?", now).
+			Order("id ASC").
+			Limit(limit).
+			Find(&pending).Error; err != nil {
+			return err
+		}
+		for _, e := range pending {
+			delivered, derr := deliver(e)
+			if derr != nil {
+				attempts := e.Attempts + 1
+				updates := map[string]any{"attempts": attempts, "last_error": derr.Error(), "delivered_to": pq.StringArray(delivered)}
+				if attempts >= maxAttempts {
+					updates["failed_at"] = now
+				} else {
+					updates["next_attempt_at"] = retryAt(attempts)
+				}
+				if err := tx.Model(&model.OutboxEvent{}).Where("id = ?", e.ID).Updates(updates).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err := tx.Model(&model.OutboxEvent{}).Where("id = ?", e.ID).
+				Updates(map[string]any{"dispatched_at": now, "attempts": e.Attempts + 1, "last_error": ""}).Error; err != nil {
+				return err
+			}
+			dispatched++
+		}
+		return nil
+	})
+	return dispatched, err
+}
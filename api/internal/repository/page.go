@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"mosprom/api/internal/db"
+	"mosprom/api/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateClubPageVersion stores a new draft numbered after the club's latest version.
+// The club row is locked so concurrent saves get distinct numbers.
+func CreateClubPageVersion(v *model.ClubPageVersion) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&model.Club{}, v.ClubID).Error; err != nil {
+			return err
+		}
+		var last int
+		if err := tx.Model(&model.ClubPageVersion{}).Where("club_id = ?", v.ClubID).
+			Select("COALESCE(MAX(version), 0)").Scan(&last).Error; err != nil {
+			return err
+		}
+		v.Version = last + 1
+		v.Status = model.PageDraft
+		return tx.Create(v).Error
+	})
+}
+
+// GetClubPageVersions returns newest-first versions of the club page, without blocks
+func GetClubPageVersions(clubID uint) ([]model.ClubPageVersion, error) {
+	var items []model.ClubPageVersion
+	err := db.DB.Omit("blocks").Where("club_id = ?", clubID).Order("version DESC").Find(&items).Error
+	return items, err
+}
+
+func GetClubPageVersion(clubID uint, version int) (model.ClubPageVersion, error) {
+	var v model.ClubPageVersion
+	err := db.DB.Where("club_id = ? AND version = ?", clubID, version).First(&v).Error
+	return v, err
+}
+
+func GetPublishedClubPage(clubID uint) (model.ClubPageVersion, error) {
+	var v model.ClubPageVersion
+	err := db.DB.Where("club_id = ? AND status = ?", clubID, model.PagePublished).First(&v).Error
+	return v, err
+}
+
+// PublishClubPageVersion makes the version the club's published page, archiving the previous one.
+// Publishing an archived version rolls the page back to it.
+func PublishClubPageVersion(clubID uint, version int, at time.Time) (model.ClubPageVersion, error) {
+	var v model.ClubPageVersion
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&model.Club{}, clubID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("club_id = ? AND version = ?", clubID, version).First(&v).Error; err != nil {
+			return err
+		}
+		if v.Status == model.PagePublished {
+			return nil
+		}
+		if err := tx.Model(&model.ClubPageVersion{}).
+			Where("club_id = ? AND status = ?", clubID, model.PagePublished).
+			Update("status", model.PageArchived).Error; err != nil {
+			return err
+		}
+		v.Status = model.PagePublished
+		v.PublishedAt = &at
+		if err := tx.Model(&model.ClubPageVersion{}).Where("id = ?", v.ID).
+			Updates(map[string]any{"status": v.Status, "published_at": at}).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, model.EventClubUpdated, model.EventPayload{ClubID: clubID})
+	})
+	return v, err
+}
+
+// CountClubPosts counts how many of the given post ids belong to the club
+func CountClubPosts(clubID uint, postIDs []uint) (int64, error) {
+	var n int64
+	err := db.DB.Model(&model.Post{}).Where("club_id = ? AND id IN ?", clubID, postIDs).Count(&n).Error
+	return n, err
+}
@@ -1,86 +1,294 @@
 package repository
 
 import (
+	"errors"
 	"mosprom/api/internal/db"
 	"mosprom/api/internal/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func CreatePost(post *model.Post) error {
-	return db.DB.Create(post).Error
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(post).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, model.EventPostCreated, model.EventPayload{PostID: post.ID, ClubID: post.ClubID, PostType: post.Type})
+	})
 }
 
+// GetPostByID loads a post with its club, co-hosts, technologies and registration questions; counters come from GetPostStats
 func GetPostByID(id uint) (model.Post, error) {
 	var post model.Post
-	err := db.DB.Preload("Club").Preload("Likes").Preload("Technologies").Preload("Participants").First(&post, id).Error
+	err := preloadCoHosts(db.DB).Preload("Club").Preload("Technologies").
+		Preload("Questions", func(q *gorm.DB) *gorm.DB { return q.Order("position ASC, id ASC") }).
+		First(&post, id).Error
 	return post, err
 }
 
-func GetPostsByClubID(clubID uint) ([]model.Post, error) {
-	var posts []model.Post
-	err := db.DB.Where("club_id = ?", clubID).Preload("Club").Preload("Likes").Preload("Technologies").Preload("Participants").Find(&posts).Error
-	return posts, err
+// PostStats holds like aggregates of a post and the viewer's relation to it;
+// participants are counted by posts.participants_count
+type PostStats struct {
+	PostID         uint                     `gorm:"column:post_id"`
+	LikesCount     int                      `gorm:"column:likes_count"`
+	LikedByMe      bool                     `gorm:"column:liked_by_me"`
+	JoinedByMe     bool                     `gorm:"column:joined_by_me"`
+	Waitlist       int                      `gorm:"column:waitlist"`
+	MyPosition     int                      `gorm:"column:my_position"`     // 1-based position of the viewer on the waitlist, 0 if not on it
+	MyRegistration model.RegistrationStatus `gorm:"column:my_registration"` // status of the viewer's latest registration, empty if none
+	Attended       int                      `gorm:"column:attended"`
+	AgeEligible    bool                     `gorm:"column:age_eligible"` // the viewer is old enough (anonymous viewers are not for restricted posts)
+	CheckedInByMe  bool                     `gorm:"column:checked_in_by_me"`
 }
 
-func GetAllPosts() ([]model.Post, error) {
-	var posts []model.Post
-	err := db.DB.Preload("Club").Preload("Likes").Preload("Technologies").Preload("Participants").Find(&posts).Error
-	return posts, err
+// GetPostStats computes counters of the given posts in one aggregate query; viewerID 0 is anonymous
+func GetPostStats(postIDs []uint, viewerID uint) (map[uint]PostStats, error) {
+	out := make(map[uint]PostStats, len(postIDs))
+	if len(postIDs) == 0 {
+		return out, nil
+	}
+	q := `
+		SELECT p.id AS post_id,
+			   COUNT(l.user_id) AS likes_count,
+			   COALESCE(BOOL_OR(l.user_id = @viewer), false) AS liked_by_me,
+			   EXISTS (SELECT 1 FROM post_participants pp WHERE pp.post_id = p.id AND pp.user_id = @viewer) AS joined_by_me,
+			   (SELECT COUNT(*) FROM post_waitlist_entries w WHERE w.post_id = p.id) AS waitlist,
+			   COALESCE((SELECT COUNT(*) FROM post_waitlist_entries w
+						 WHERE w.post_id = p.id AND w.id <= (
+							SELECT id FROM post_waitlist_entries WHERE post_id = p.id AND user_id = @viewer)), 0) AS my_position,
+			   COALESCE((SELECT r.status FROM post_registrations r
+						 WHERE r.post_id = p.id AND r.user_id = @viewer ORDER BY r.id DESC LIMIT 1), '') AS my_registration,
+			   (SELECT COUNT(*) FROM post_attendances a WHERE a.post_id = p.id) AS attended,
+			   EXISTS (SELECT 1 FROM post_attendances a WHERE a.post_id = p.id AND a.user_id = @viewer) AS checked_in_by_me,
+			   ` + ageEligibleSQL("p", "viewer") + ` AS age_eligible
+		FROM posts p
+		LEFT JOIN likes l ON l.post_id = p.id
+		WHERE p.id IN @ids
+		GROUP BY p.id`
+	var rows []PostStats
+	if err := db.DB.Raw(q, map[string]any{"ids": postIDs, "viewer": viewerID}).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		out[r.PostID] = r
+	}
+	return out, nil
 }
 
+// UpdatePost saves the post's own fields and bumps its calendar sequence; participants_count is kept
+// as is and waitlisted users are promoted if the capacity grew
 func UpdatePost(post *model.Post) error {
-	return db.DB.Save(post).Error
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		locked, err := lockPost(tx, post.ID)
+		if err != nil {
+			return err
+		}
+		post.ParticipantsCount = locked.ParticipantsCount
+		post.Sequence = locked.Sequence + 1
+		if err := tx.Omit("participants_count").Save(post).Error; err != nil {
+			return err
+		}
+		if err := recordEvent(tx, model.EventPostUpdated, model.EventPayload{PostID: post.ID, ClubID: post.ClubID, PostType: post.Type}); err != nil {
+			return err
+		}
+		return promoteFromWaitlist(tx, post)
+	})
 }
 
 func DeletePost(id uint) error {
-	return db.DB.Delete(&model.Post{}, id).Error
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		return deletePost(tx, id)
+	})
 }
 
-func GetPostWithDetails(id uint) (model.Post, error) {
+// deletePost deletes the post inside the caller's transaction, keeping its cancellation for calendars
+func deletePost(tx *gorm.DB, id uint) error {
 	var post model.Post
-	err := db.DB.Preload("Club").Preload("Likes.User").Preload("Technologies").Preload("Participants").First(&post, id).Error
+	if err := tx.First(&post, id).Error; err != nil {
+		return err
+	}
+	if err := cancelPosts(tx, "p.id = ?", id); err != nil {
+		return err
+	}
+	if err := tx.Exec(`
+		UPDATE users SET events_count = GREATEST(events_count - 1, 0)
+		WHERE id IN (SELECT user_id FROM post_attendances WHERE post_id = ?)`, id).Error; err != nil {
+		return err
+	}
+	if err := tx.Delete(&model.Post{}, id).Error; err != nil {
+		return err
+	}
+	return recordEvent(tx, model.EventPostDeleted, model.EventPayload{PostID: post.ID, ClubID: post.ClubID, PostType: post.Type})
+}
+
+// JoinUserToPost adds user to post participants, or to its waitlist when the post is at capacity,
+// and updates counters atomically
+func JoinUserToPost(userID, postID uint) (model.ParticipationStatus, error) {
+	var status model.ParticipationStatus
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		status, err = joinUserToPost(tx, userID, postID)
+		return err
+	})
+	return status, err
+}
+
+// lockPost loads the post with a row lock; joins, leaves and capacity changes of a post are serialized on it
+func lockPost(tx *gorm.DB, postID uint) (model.Post, error) {
+	var post model.Post
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&post, postID).Error
 	return post, err
 }
 
-// JoinUserToPost adds user to post participants and updates counters atomically
-func JoinUserToPost(userID, postID uint) error {
-	return db.DB.Transaction(func(tx *gorm.DB) error {
-		var post model.Post
-		if err := tx.First(&post, postID).Error; err != nil {
+// joinUserToPost adds the participant or waitlist entry inside the caller's transaction
+func joinUserToPost(tx *gorm.DB, userID, postID uint) (model.ParticipationStatus, error) {
+	post, err := lockPost(tx, postID)
+	if err != nil {
+		return "", err
+	}
+	var archived int64
+	if err := tx.Model(&model.Club{}).Where("id = ? AND archived_at IS NOT NULL", post.ClubID).Count(&archived).Error; err != nil {
+		return "", err
+	}
+	if archived > 0 {
+		return "", ErrClubArchived
+	}
+	var user model.User
+	if err := tx.First(&user, userID).Error; err != nil {
+		return "", err
+	}
+
+	// check existing
+	var cnt int64
+	if err := tx.Table("post_participants").Where("post_id = ? AND user_id = ?", postID, userID).Count(&cnt).Error; err != nil {
+		return "", err
+	}
+	if cnt > 0 {
+		return model.ParticipationJoined, nil
+	}
+	if err := tx.Model(&model.PostWaitlistEntry{}).Where("post_id = ? AND user_id = ?", postID, userID).Count(&cnt).Error; err != nil {
+		return "", err
+	}
+	if cnt > 0 {
+		return model.ParticipationWaitlisted, nil
+	}
+
+	if err := checkAgeEligible(post, user); err != nil {
+		return "", err
+	}
+	if post.Capacity != nil && post.ParticipantsCount >= *post.Capacity {
+		if err := tx.Create(&model.PostWaitlistEntry{PostID: postID, UserID: userID}).Error; err != nil {
+			return "", err
+		}
+		if err := recordEvent(tx, model.EventUserWaitlisted, model.EventPayload{PostID: postID, ClubID: post.ClubID, UserID: userID, PostType: post.Type}); err != nil {
+			return "", err
+		}
+		return model.ParticipationWaitlisted, nil
+	}
+	if err := addParticipant(tx, &post, userID); err != nil {
+		return "", err
+	}
+	return model.ParticipationJoined, nil
+}
+
+// addParticipant inserts the participant of a locked post and bumps post.ParticipantsCount with the counter.
+// users.events_count counts attendances and is bumped on check-in.
+func addParticipant(tx *gorm.DB, post *model.Post, userID uint) error {
+	if err := tx.Exec("INSERT INTO post_participants (post_id, user_id) VALUES (?, ?)", post.ID, userID).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&model.Post{}).Where("id = ?", post.ID).UpdateColumn("participants_count", gorm.Expr("participants_count + 1")).Error; err != nil {
+		return err
+	}
+	post.ParticipantsCount++
+	return recordEvent(tx, model.EventUserJoinedPost, model.EventPayload{PostID: post.ID, ClubID: post.ClubID, UserID: userID, PostType: post.Type})
+}
+
+// promoteFromWaitlist moves waitlisted users of a locked post to participants, oldest first, while there is room
+func promoteFromWaitlist(tx *gorm.DB, post *model.Post) error {
+	for post.Capacity == nil || post.ParticipantsCount < *post.Capacity {
+		var entry model.PostWaitlistEntry
+		err := tx.Where("post_id = ?", post.ID).Order("id ASC").First(&entry).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
 			return err
 		}
-		var user model.User
-		if err := tx.First(&user, userID).Error; err != nil {
+		if err := tx.Delete(&entry).Error; err != nil {
 			return err
 		}
-
-		// check existing
-		var cnt int64
-		if err := tx.Table("post_participants").Where("post_id = ? AND user_id = ?", postID, userID).Count(&cnt).Error; err != nil {
+		if err := addParticipant(tx, post, entry.UserID); err != nil {
 			return err
 		}
-		if cnt == 0 {
-			if err := tx.Model(&post).Association("Participants").Append(&user); err != nil {
-				return err
-			}
-			if err := tx.Model(&model.Post{}).Where("id = ?", postID).UpdateColumn("participants_count", gorm.Expr("participants_count + 1")).Error; err != nil {
-				return err
-			}
-			if err := tx.Model(&model.User{}).Where("id = ?", userID).UpdateColumn("events_count", gorm.Expr("events_count + 1")).Error; err != nil { // reuse events_count as overall participation counter
-				return err
-			}
+		if err := recordEvent(tx, model.EventWaitlistPromoted, model.EventPayload{PostID: post.ID, ClubID: post.ClubID, UserID: entry.UserID, PostType: post.Type}); err != nil {
+			return err
 		}
-		return nil
+	}
+	return nil
+}
+
+// LeavePost removes the user from the post's participants, decrementing the counter and promoting the
+// first waitlisted user, or from its waitlist. Returns gorm.ErrRecordNotFound if the user is on neither
+// and ErrCheckedIn once the user's attendance is recorded.
+func LeavePost(userID, postID uint) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		return leavePost(tx, userID, postID)
 	})
 }
 
+// leavePost removes the participant or waitlist entry inside the caller's transaction
+func leavePost(tx *gorm.DB, userID, postID uint) error {
+	post, err := lockPost(tx, postID)
+	if err != nil {
+		return err
+	}
+	var attended int64
+	if err := tx.Model(&model.PostAttendance{}).Where("post_id = ? AND user_id = ?", postID, userID).Count(&attended).Error; err != nil {
+		return err
+	}
+	if attended > 0 {
+		return ErrCheckedIn
+	}
+	res := tx.Exec("DELETE FROM post_participants WHERE post_id = ? AND user_id = ?", postID, userID)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		res = tx.Where("post_id = ? AND user_id = ?", postID, userID).Delete(&model.PostWaitlistEntry{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	}
+	if err := tx.Model(&model.Post{}).Where("id = ?", postID).
+		UpdateColumn("participants_count", gorm.Expr("GREATEST(participants_count - 1, 0)")).Error; err != nil {
+		return err
+	}
+	post.ParticipantsCount = max(post.ParticipantsCount-1, 0)
+	if err := recordEvent(tx, model.EventUserLeftPost, model.EventPayload{PostID: postID, ClubID: post.ClubID, UserID: userID, PostType: post.Type}); err != nil {
+		return err
+	}
+	return promoteFromWaitlist(tx, &post)
+}
+
+// GetPostWaitlist returns the post's waitlist in promotion order
+func GetPostWaitlist(postID uint) ([]model.PostWaitlistEntry, error) {
+	var entries []model.PostWaitlistEntry
+	err := db.DB.Preload("User").Where("post_id = ?", postID).Order("id ASC").Find(&entries).Error
+	return entries, err
+}
+
 func GetUserJoinedPosts(userID uint) ([]model.Post, error) {
 	var posts []model.Post
-	err := db.DB.Model(&model.Post{}).
+	err := preloadCoHosts(db.DB.Model(&model.Post{})).
 		Joins("JOIN post_participants pp ON pp.post_id = posts.id").
 		Where("pp.user_id = ?", userID).
-		Preload("Club").Preload("Technologies").Preload("Participants").
+		Preload("Club").Preload("Technologies").
 		Find(&posts).Error
 	return posts, err
 }
@@ -101,12 +309,15 @@ func GetTechnologiesByPostID(postID uint) ([]model.Technology, error) {
 		Find(&techs).Error
 	return techs, err
 }
-func GetPostParticipants(postID uint) ([]model.User, error) {
+
+// GetPostParticipants returns up to limit participants with ids greater than afterID, by id
+func GetPostParticipants(postID, afterID uint, limit int) ([]model.User, error) {
 	var users []model.User
-	err := db.DB.Model(&model.Post{}).
-		Where("posts.id = ?", postID).
-		Joins("JOIN post_participants pp ON pp.post_id = posts.id").
-		Joins("JOIN users ON users.id = pp.user_id").
+	err := db.DB.Model(&model.User{}).
+		Joins("JOIN post_participants pp ON pp.user_id = users.id").
+		Where("pp.post_id = ? AND users.id > ?", postID, afterID).
+		Order("users.id ASC").
+		Limit(limit).
 		Find(&users).Error
 	return users, err
 }
@@ -134,11 +345,12 @@ func GetPostTechMatchRanking(userID uint) ([]TechMatchRow, error) {
 			SELECT pt.post_id, COUNT(*) AS cnt
 			FROM post_technologies pt
 			JOIN user_technologies ut ON ut.technology_id = pt.technology_id
-			WHERE ut.user_id = ?
+			WHERE ut.user_id = @uid
 			GROUP BY pt.post_id
 		) common ON common.post_id = p.id
+		WHERE ` + ageEligibleSQL("p", "uid") + `
 		ORDER BY tech_match DESC, p.id ASC`
-	if err := db.DB.Raw(q, userID).Scan(&rows).Error; err != nil {
+	if err := db.DB.Raw(q, map[string]any{"uid": userID}).Scan(&rows).Error; err != nil {
 		return nil, err
 	}
 	return rows, nil
@@ -150,10 +362,9 @@ func GetPostsByIDs(ids []uint) (map[uint]model.Post, error) {
 		return map[uint]model.Post{}, nil
 	}
 	var posts []model.Post
-	if err := db.DB.
+	if err := preloadCoHosts(db.DB).
 		Where("id IN ?", ids).
 		Preload("Club").
-		Preload("Likes").
 		Preload("Technologies").
 		Find(&posts).Error; err != nil {
 		return nil, err
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"mosprom/api/internal/db"
+	"mosprom/api/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PostSort string
+
+const (
+	PostSortCreated PostSort = "created" // newest first
+	PostSortStart   PostSort = "start"   // soonest start first, posts without a start date last
+	PostSortPopular PostSort = "popular" // most participants plus likes first
+)
+
+// postPopularity is the popularity sort key of a post row
+const postPopularity = "(posts.participants_count + (SELECT COUNT(*) FROM likes l WHERE l.post_id = posts.id))"
+
+// PostKey is the sort key of the last post of a page; Time is used by created and start sorts, Num by popular
+type PostKey struct {
+	Time *time.Time
+	Num  int64
+	ID   uint
+}
+
+// PostFilter narrows post listings. Empty fields do not filter.
+type PostFilter struct {
+	Types        []model.PostType
+	Format       *model.PostFormat
+	ClubID       *uint      // hosted or co-hosted by the club
+	Technologies []string   // any of the technologies, case-insensitive
+	Directions   []string   // club or one of the post's technologies in any of the directions
+	From         *time.Time // event overlaps [From, To)
+	To           *time.Time
+	UpcomingAt   *time.Time // not finished at this time
+	Age          *int       // open to users of this age
+	EligibleFor  *uint      // open to this user by birth date; 0 (anonymous) leaves out all age-restricted posts
+	Sort         PostSort
+	After        *PostKey
+	Limit        int
+}
+
+func (f PostFilter) apply(q *gorm.DB) *gorm.DB {
+	if len(f.Types) > 0 {
+		q = q.Where("posts.type IN ?", f.Types)
+	}
+	if f.Format != nil {
+		q = q.Where("posts.format = ?", *f.Format)
+	}
+	if f.ClubID != nil {
+		q = q.Where(hostedByClub("posts"), map[string]any{"club": *f.ClubID})
+	}
+	if names := lowerNames(f.Technologies); len(names) > 0 {
+		q = q.Where(`EXISTS (
+			SELECT 1 FROM post_technologies pt JOIN technologies t ON t.id = pt.technology_id
+			WHERE pt.post_id = posts.id AND LOWER(t.name) IN ?)`, names)
+	}
+	if names := lowerNames(f.Directions); len(names) > 0 {
+		q = q.Where(`(EXISTS (
+			SELECT 1 FROM club_directions cd JOIN directions d ON d.id = cd.direction_id
+			WHERE cd.club_id = posts.club_id AND LOWER(d.name) IN @dirs)
+		OR EXISTS (
+			SELECT 1 FROM post_technologies pt
+			JOIN direction_technologies dt ON dt.technology_id = pt.technology_id
+			JOIN directions d ON d.id = dt.direction_id
+			WHERE pt.post_id = posts.id AND LOWER(d.name) IN @dirs))`, map[string]any{"dirs": names})
+	}
+	if f.From != nil {
+		q = q.Where("COALESCE(posts.end_date, posts.start_date) >= ?", *f.From)
+	}
+	if f.To != nil {
+		q = q.Where("posts.start_date < ?", *f.To)
+	}
+	if f.UpcomingAt != nil {
+		q = q.Where("COALESCE(posts.end_date, posts.start_date) >= ?", *f.UpcomingAt)
+	}
+	if f.Age != nil {
+		q = q.Where("(posts.age_restriction IS NULL OR posts.age_restriction <= ?)", *f.Age)
+	}
+	if f.EligibleFor != nil {
+		q = q.Where(ageEligibleSQL("posts", "viewer"), map[string]any{"viewer": *f.EligibleFor})
+	}
+	return q
+}
+
+// ListPosts returns one page of posts matching the filter, the total number of matches and the key to
+// pass as After for the next page (nil on the last page). Pages are keyset-paginated on the sort key and id.
+func ListPosts(f PostFilter) ([]model.Post, int64, *PostKey, error) {
+	var total int64
+	if err := f.apply(db.DB.Model(&model.Post{})).Count(&total).Error; err != nil {
+		return nil, 0, nil, err
+	}
+
+	q := f.apply(db.DB.Model(&model.Post{})).Select("posts.id, posts.created_at, posts.start_date")
+	switch f.Sort {
+	case PostSortStart:
+		if a := f.After; a != nil {
+			if a.Time != nil {
+				q = q.Where("(posts.start_date > ? OR (posts.start_date = ? AND posts.id > ?) OR posts.start_date IS NULL)", *a.Time, *a.Time, a.ID)
+			} else {
+				q = q.Where("posts.start_date IS NULL AND posts.id > ?", a.ID)
+			}
+		}
+		q = q.Order("posts.start_date ASC NULLS LAST, posts.id ASC")
+	case PostSortPopular:
+		q = q.Select("posts.id, posts.created_at, posts.start_date, " + postPopularity + " AS popularity")
+		if a := f.After; a != nil {
+			q = q.Where("("+postPopularity+", posts.id) < (?, ?)", a.Num, a.ID)
+		}
+		q = q.Order("popularity DESC, posts.id DESC")
+	default:
+		if a := f.After; a != nil && a.Time != nil {
+			q = q.Where("(posts.created_at, posts.id) < (?, ?)", *a.Time, a.ID)
+		}
+		q = q.Order("posts.created_at DESC, posts.id DESC")
+	}
+
+	var rows []struct {
+		ID         uint
+		CreatedAt  time.Time
+		StartDate  *time.Time
+		Popularity int64
+	}
+	if err := q.Limit(f.Limit + 1).Scan(&rows).Error; err != nil {
+		return nil, 0, nil, err
+	}
+	var next *PostKey
+	if len(rows) > f.Limit {
+		rows = rows[:f.Limit]
+		last := rows[len(rows)-1]
+		next = &PostKey{ID: last.ID, Num: last.Popularity}
+		switch f.Sort {
+		case PostSortStart:
+			next.Time = last.StartDate
+		case PostSortPopular:
+		default:
+			created := last.CreatedAt
+			next.Time = &created
+		}
+	}
+
+	ids := make([]uint, 0, len(rows))
+	for _, r := range rows {
+		ids = append(ids, r.ID)
+	}
+	m, err := GetPostsByIDs(ids)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	posts := make([]model.Post, 0, len(rows))
+	for _, r := range rows {
+		if p, ok := m[r.ID]; ok {
+			posts = append(posts, p)
+		}
+	}
+	return posts, total, next, nil
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"fmt"
+	"mosprom/api/internal/db"
+	"mosprom/api/internal/dbtest"
+	"mosprom/api/internal/model"
+	"sync"
+	"testing"
+)
+
+// seedCapacityPost creates a post with room for capacity participants and n users who may join it
+func seedCapacityPost(t *testing.T, capacity, n int) (model.Post, []model.User) {
+	t.Helper()
+	conn := dbtest.Open(t)
+	users := make([]model.User, n)
+	for i := range users {
+		users[i] = model.User{TelegramName: fmt.Sprintf("join_user_%d", i), Name: "Join User"}
+	}
+	if err := conn.Create(&users).Error; err != nil {
+		t.Fatal(err)
+	}
+	club := model.Club{Name: "capacity club", CreatorID: users[0].ID}
+	if err := conn.Create(&club).Error; err != nil {
+		t.Fatal(err)
+	}
+	post := model.Post{Title: "Workshop", Description: "Limited seats", Type: model.Activity, ClubID: club.ID, Capacity: &capacity}
+	if err := conn.Create(&post).Error; err != nil {
+		t.Fatal(err)
+	}
+	return post, users
+}
+
+// participantState returns the stored counter, the participant rows and the waitlist in promotion order
+func participantState(t *testing.T, postID uint) (int, map[uint]bool, []uint) {
+	t.Helper()
+	var post model.Post
+	if err := db.DB.First(&post, postID).Error; err != nil {
+		t.Fatal(err)
+	}
+	var ids []uint
+	if err := db.DB.Table("post_participants").Where("post_id = ?", postID).Pluck("user_id", &ids).Error; err != nil {
+		t.Fatal(err)
+	}
+	participants := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		participants[id] = true
+	}
+	entries, err := GetPostWaitlist(postID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitlist := make([]uint, 0, len(entries))
+	for _, e := range entries {
+		waitlist = append(waitlist, e.UserID)
+	}
+	return post.ParticipantsCount, participants, waitlist
+}
+
+func TestJoinUserToPostConcurrent(t *testing.T) {
+	const capacity, n = 5, 20
+	post, users := seedCapacityPost(t, capacity, n)
+
+	// every user joins twice at once; repeats must not take a second seat or waitlist entry
+	statuses := make([]model.ParticipationStatus, 2*n)
+	errs := make([]error, 2*n)
+	var wg sync.WaitGroup
+	for i := range statuses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			statuses[i], errs[i] = JoinUserToPost(users[i%n].ID, post.ID)
+		}(i)
+	}
+	wg.Wait()
+
+	joined := map[uint]bool{}
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("join of user %d: %v", users[i%n].ID, err)
+		}
+		if statuses[i] == model.ParticipationJoined {
+			joined[users[i%n].ID] = true
+		}
+	}
+	count, participants, waitlist := participantState(t, post.ID)
+	if count != capacity || len(participants) != capacity {
+		t.Fatalf("participants_count %d, %d participant rows; want %d", count, len(participants), capacity)
+	}
+	if len(waitlist) != n-capacity {
+		t.Fatalf("waitlist has %d users, want %d", len(waitlist), n-capacity)
+	}
+	for id := range joined {
+		if !participants[id] {
+			t.Errorf("user %d was told joined but is not a participant", id)
+		}
+	}
+	for _, id := range waitlist {
+		if participants[id] {
+			t.Errorf("user %d is both a participant and waitlisted", id)
+		}
+	}
+
+	// each leave promotes the user waiting longest
+	var leaver uint
+	for id := range participants {
+		leaver = id
+		break
+	}
+	for step := 0; step < 2; step++ {
+		next := waitlist[0]
+		if err := LeavePost(leaver, post.ID); err != nil {
+			t.Fatalf("leave: %v", err)
+		}
+		count, after, rest := participantState(t, post.ID)
+		if count != capacity || len(after) != capacity {
+			t.Fatalf("after leave %d: participants_count %d, %d rows; want %d", step+1, count, len(after), capacity)
+		}
+		if after[leaver] || !after[next] {
+			t.Fatalf("after leave %d: user %d left and %d should be promoted; participants %v", step+1, leaver, next, after)
+		}
+		if len(rest) != len(waitlist)-1 || (len(rest) > 0 && rest[0] != waitlist[1]) {
+			t.Fatalf("after leave %d: waitlist %v, want %v", step+1, rest, waitlist[1:])
+		}
+		leaver, waitlist = next, rest
+	}
+}
+
+func TestLeavePostConcurrent(t *testing.T) {
+	const capacity, n = 5, 12
+	post, users := seedCapacityPost(t, capacity, n)
+	for _, u := range users {
+		if _, err := JoinUserToPost(u.ID, post.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, participants, waitlist := participantState(t, post.ID)
+
+	// all participants leave at once; the first waitlisted users fill every seat in order
+	var wg sync.WaitGroup
+	errs := make(chan error, len(participants))
+	for id := range participants {
+		wg.Add(1)
+		go func(id uint) {
+			defer wg.Done()
+			errs <- LeavePost(id, post.ID)
+		}(id)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("leave: %v", err)
+		}
+	}
+
+	count, after, rest := participantState(t, post.ID)
+	if count != capacity || len(after) != capacity {
+		t.Fatalf("participants_count %d, %d rows; want %d", count, len(after), capacity)
+	}
+	for _, id := range waitlist[:capacity] {
+		if !after[id] {
+			t.Errorf("user %d was among the first %d waitlisted but is not a participant", id, capacity)
+		}
+	}
+	if fmt.Sprint(rest) != fmt.Sprint(waitlist[capacity:]) {
+		t.Errorf("waitlist %v, want %v", rest, waitlist[capacity:])
+	}
+}
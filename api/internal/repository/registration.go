@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"errors"
+	"mosprom/api/internal/db"
+	"mosprom/api/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrRegistrationPending = errors.New("registration is already pending")
+	ErrRegistrationDecided = errors.New("registration is already decided")
+	ErrAlreadyParticipant  = errors.New("user already takes part in the post or is on its waitlist")
+)
+
+// ReplacePostRegistrationQuestions replaces the post's questions; answers of earlier registrations keep their text
+func ReplacePostRegistrationQuestions(postID uint, questions []model.PostRegistrationQuestion) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("post_id = ?", postID).Delete(&model.PostRegistrationQuestion{}).Error; err != nil {
+			return err
+		}
+		if len(questions) == 0 {
+			return nil
+		}
+		return tx.Create(&questions).Error
+	})
+}
+
+// GetPostRegistrationQuestions returns the post's questions in display order
+func GetPostRegistrationQuestions(postID uint) ([]model.PostRegistrationQuestion, error) {
+	var qs []model.PostRegistrationQuestion
+	err := db.DB.Where("post_id = ?", postID).Order("position ASC, id ASC").Find(&qs).Error
+	return qs, err
+}
+
+// CreatePostRegistration files a pending registration for a post of a non-archived club
+// the user does not take part in yet
+func CreatePostRegistration(reg *model.PostRegistration) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		post, err := lockPost(tx, reg.PostID)
+		if err != nil {
+			return err
+		}
+		var archived int64
+		if err := tx.Model(&model.Club{}).Where("id = ? AND archived_at IS NOT NULL", post.ClubID).Count(&archived).Error; err != nil {
+			return err
+		}
+		if archived > 0 {
+			return ErrClubArchived
+		}
+		var user model.User
+		if err := tx.First(&user, reg.UserID).Error; err != nil {
+			return err
+		}
+		if err := checkAgeEligible(post, user); err != nil {
+			return err
+		}
+		var cnt int64
+		if err := tx.Raw(`SELECT
+				(SELECT COUNT(*) FROM post_participants WHERE post_id = @post AND user_id = @user)
+				+ (SELECT COUNT(*) FROM post_waitlist_entries WHERE post_id = @post AND user_id = @user)`,
+			map[string]any{"post": reg.PostID, "user": reg.UserID}).Scan(&cnt).Error; err != nil {
+			return err
+		}
+		if cnt > 0 {
+			return ErrAlreadyParticipant
+		}
+		if err := tx.Model(&model.PostRegistration{}).
+			Where("post_id = ? AND user_id = ? AND status = ?", reg.PostID, reg.UserID, model.RegistrationPending).
+			Count(&cnt).Error; err != nil {
+			return err
+		}
+		if cnt > 0 {
+			return ErrRegistrationPending
+		}
+		reg.Status = model.RegistrationPending
+		if err := tx.Create(reg).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, model.EventRegistrationCreated, model.EventPayload{PostID: post.ID, ClubID: post.ClubID, UserID: reg.UserID, PostType: post.Type})
+	})
+}
+
+// GetPostRegistrations returns oldest-first registrations of the post, optionally filtered by status
+func GetPostRegistrations(postID uint, status model.RegistrationStatus) ([]model.PostRegistration, error) {
+	var items []model.PostRegistration
+	q := db.DB.Preload("User").Where("post_id = ?", postID)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	err := q.Order("created_at ASC, id ASC").Find(&items).Error
+	return items, err
+}
+
+// GetUserRegistrations returns newest-first registrations of the user
+func GetUserRegistrations(userID uint) ([]model.PostRegistration, error) {
+	var items []model.PostRegistration
+	err := db.DB.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Find(&items).Error
+	return items, err
+}
+
+// DecidePostRegistration approves (joining the user, or waitlisting them if the post is full) or rejects
+// a pending registration of the post
+func DecidePostRegistration(postID, registrationID, actorID uint, approve bool, reason string) (model.PostRegistration, error) {
+	var reg model.PostRegistration
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND post_id = ?", registrationID, postID).First(&reg).Error; err != nil {
+			return err
+		}
+		if reg.Status != model.RegistrationPending {
+			return ErrRegistrationDecided
+		}
+		var post model.Post
+		if err := tx.First(&post, postID).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		reg.DecidedByID = &actorID
+		reg.DecidedAt = &now
+		payload := model.EventPayload{PostID: postID, ClubID: post.ClubID, UserID: reg.UserID, PostType: post.Type}
+		eventType := model.EventRegistrationRejected
+		if approve {
+			if _, err := joinUserToPost(tx, reg.UserID, postID); err != nil {
+				return err
+			}
+			reg.Status = model.RegistrationApproved
+			eventType = model.EventRegistrationApproved
+		} else {
+			reg.Status = model.RegistrationRejected
+			reg.Reason = reason
+			payload.Reason = reason
+		}
+		if err := tx.Model(&model.PostRegistration{}).Where("id = ?", reg.ID).
+			Updates(map[string]any{"status": reg.Status, "reason": reg.Reason, "decided_by_id": actorID, "decided_at": now}).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, eventType, payload)
+	})
+	return reg, err
+}
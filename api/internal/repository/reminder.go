@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"mosprom/api/internal/db"
+	"mosprom/api/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReplacePostReminders cancels pending reminders of a post and stores the given ones in a single transaction
+func ReplacePostReminders(postID uint, reminders []model.PostReminder) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.PostReminder{}).
+			Where("post_id = ? AND status = ?", postID, model.ReminderPending).
+			Update("status", model.ReminderCancelled).Error; err != nil {
+			return err
+		}
+		if len(reminders) == 0 {
+			return nil
+		}
+		return tx.Create(&reminders).Error
+	})
+}
+
+// FireDueReminders locks up to limit pending reminders with fire_at <= now, creates a notification for every
+// participant of the reminded post and marks the reminders sent, all in one transaction.
+// Rows locked by another instance are skipped, so a reminder is never delivered twice.
+// Reminders that no longer match the post start date are cancelled instead of sent.
+func FireDueReminders(now time.Time, limit int, compose func(r model.PostReminder, post model.Post) model.Notification) (int, error) {
+	fired := 0
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var due []model.PostReminder
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND fire_at <= ?", model.ReminderPending, now).
+			Order("fire_at ASC").
+			Limit(limit).
+			Find(&due).Error; err != nil {
+			return err
+		}
+		for _, r := range due {
+			var post model.Post
+			if err := tx.First(&post, r.PostID).Error; err != nil {
+				return err
+			}
+			if post.StartDate == nil || !post.StartDate.Add(-time.Duration(r.OffsetMinutes)*time.Minute).Equal(r.FireAt) || !post.StartDate.After(now) {
+				if err := tx.Model(&model.PostReminder{}).Where("id = ?", r.ID).Update("status", model.ReminderCancelled).Error; err != nil {
+					return err
+				}
+				continue
+			}
+
+			var userIDs []uint
+			if err := tx.Table("post_participants").Where("post_id = ?", r.PostID).Pluck("user_id", &userIDs).Error; err != nil {
+				return err
+			}
+			if len(userIDs) > 0 {
+				tmpl := compose(r, post)
+				notifications := make([]model.Notification, 0, len(userIDs))
+				for _, uid := range userIDs {
+					n := tmpl
+					n.UserID = uid
+					notifications = append(notifications, n)
+				}
+				if err := tx.Create(&notifications).Error; err != nil {
+					return err
+				}
+			}
+			if err := tx.Model(&model.PostReminder{}).Where("id = ?", r.ID).
+				Updates(map[string]any{"status": model.ReminderSent, "sent_at": now}).Error; err != nil {
+				return err
+			}
+			fired++
+		}
+		return nil
+	})
+	return fired, err
+}
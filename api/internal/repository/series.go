@@ -0,0 +1,283 @@
+package repository
+
+import (
+	"errors"
+	"mosprom/api/internal/db"
+	"mosprom/api/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrSeriesEnded is returned when joining or extending a cancelled series
+var ErrSeriesEnded = errors.New("the series is cancelled")
+
+// SeriesExpander returns the starts of the series' occurrences after its MaterializedUntil and the
+// new MaterializedUntil
+type SeriesExpander func(s model.PostSeries) (starts []time.Time, until time.Time)
+
+// SeriesInfo is what a post response shows about the post's series
+type SeriesInfo struct {
+	SeriesID uint   `gorm:"column:id"`
+	RRule    string `gorm:"column:rrule"`
+	JoinedBy bool   `gorm:"column:joined_by"` // the viewer joined the whole series
+}
+
+// CreatePostSeries creates the series and its first occurrences in one transaction
+func CreatePostSeries(series *model.PostSeries, expand SeriesExpander) ([]model.Post, error) {
+	var created []model.Post
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(series).Error; err != nil {
+			return err
+		}
+		var err error
+		created, err = materializeSeries(tx, series, expand)
+		return err
+	})
+	return created, err
+}
+
+// ExtendPostSeries creates the occurrences of the series up to the expander's horizon
+func ExtendPostSeries(seriesID uint, expand SeriesExpander) ([]model.Post, error) {
+	var created []model.Post
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		series, err := lockSeries(tx, seriesID)
+		if err != nil {
+			return err
+		}
+		if series.EndedAt != nil {
+			return nil
+		}
+		var archived int64
+		if err := tx.Model(&model.Club{}).Where("id = ? AND archived_at IS NOT NULL", series.ClubID).Count(&archived).Error; err != nil {
+			return err
+		}
+		if archived > 0 {
+			return nil
+		}
+		created, err = materializeSeries(tx, &series, expand)
+		return err
+	})
+	return created, err
+}
+
+// lockSeries loads the series with a row lock; extending, joining and cancelling a series are serialized on it
+func lockSeries(tx *gorm.DB, seriesID uint) (model.PostSeries, error) {
+	var s model.PostSeries
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&s, seriesID).Error
+	return s, err
+}
+
+// materializeSeries creates the occurrences returned by expand from the series template. Technologies
+// and registration questions are copied from the latest existing occurrence, and series members join
+// each new occurrence (members too young for it are skipped).
+func materializeSeries(tx *gorm.DB, series *model.PostSeries, expand SeriesExpander) ([]model.Post, error) {
+	starts, until := expand(*series)
+	var created []model.Post
+	if len(starts) > 0 {
+		var latest model.Post
+		err := tx.Where("series_id = ?", series.ID).Order("start_date DESC, id DESC").Limit(1).Find(&latest).Error
+		if err != nil {
+			return nil, err
+		}
+		var members []uint
+		if err := tx.Model(&model.PostSeriesMember{}).Where("series_id = ?", series.ID).Order("created_at ASC").Pluck("user_id", &members).Error; err != nil {
+			return nil, err
+		}
+		for _, start := range starts {
+			post := seriesOccurrence(series, start)
+			if err := tx.Omit(clause.Associations).Create(&post).Error; err != nil {
+				return nil, err
+			}
+			if latest.ID != 0 {
+				if err := tx.Exec(`
+					INSERT INTO post_technologies (post_id, technology_id)
+					SELECT ?, technology_id FROM post_technologies WHERE post_id = ?
+					ON CONFLICT DO NOTHING`, post.ID, latest.ID).Error; err != nil {
+					return nil, err
+				}
+				if err := tx.Exec(`
+					INSERT INTO post_registration_questions (post_id, position, text, required)
+					SELECT ?, position, text, required FROM post_registration_questions WHERE post_id = ?`, post.ID, latest.ID).Error; err != nil {
+					return nil, err
+				}
+			}
+			if err := recordEvent(tx, model.EventPostCreated, model.EventPayload{PostID: post.ID, ClubID: post.ClubID, PostType: post.Type}); err != nil {
+				return nil, err
+			}
+			for _, userID := range members {
+				var ageErr *AgeRestrictionError
+				if _, err := joinUserToPost(tx, userID, post.ID); err != nil && !errors.As(err, &ageErr) {
+					return nil, err
+				}
+			}
+			created = append(created, post)
+		}
+	}
+	series.MaterializedUntil = until
+	if err := tx.Model(series).UpdateColumn("materialized_until", until).Error; err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func seriesOccurrence(s *model.PostSeries, start time.Time) model.Post {
+	post := model.Post{
+		Title:            s.Title,
+		Description:      s.Description,
+		Type:             s.Type,
+		StartDate:        &start,
+		AgeRestriction:   s.AgeRestriction,
+		Format:           s.Format,
+		Address:          s.Address,
+		ClubID:           s.ClubID,
+		SeriesID:         &s.ID,
+		Capacity:         s.Capacity,
+		RegistrationMode: s.RegistrationMode,
+	}
+	if s.DurationSeconds != nil {
+		end := start.Add(time.Duration(*s.DurationSeconds) * time.Second)
+		post.EndDate = &end
+	}
+	return post
+}
+
+// GetSeriesToExtend returns active series materialized only up to before horizon
+func GetSeriesToExtend(horizon time.Time) ([]uint, error) {
+	var ids []uint
+	err := db.DB.Model(&model.PostSeries{}).
+		Where("ended_at IS NULL AND materialized_until < ?", horizon).
+		Order("id ASC").Pluck("id", &ids).Error
+	return ids, err
+}
+
+func GetPostSeries(id uint) (model.PostSeries, error) {
+	var s model.PostSeries
+	err := db.DB.First(&s, id).Error
+	return s, err
+}
+
+// GetSeriesOccurrences returns the series' occurrences ending after since, by start
+func GetSeriesOccurrences(seriesID uint, since time.Time) ([]model.Post, error) {
+	var posts []model.Post
+	err := preloadCoHosts(db.DB).Preload("Club").Preload("Technologies").
+		Where("series_id = ? AND COALESCE(end_date, start_date) >= ?", seriesID, since).
+		Order("start_date ASC, id ASC").
+		Find(&posts).Error
+	return posts, err
+}
+
+// GetPostSeriesInfo returns the rules of the series and whether viewerID joined them
+func GetPostSeriesInfo(seriesIDs []uint, viewerID uint) (map[uint]SeriesInfo, error) {
+	out := map[uint]SeriesInfo{}
+	if len(seriesIDs) == 0 {
+		return out, nil
+	}
+	var rows []SeriesInfo
+	err := db.DB.Raw(`
+		SELECT s.id, s.rrule,
+			   EXISTS (SELECT 1 FROM post_series_members m WHERE m.series_id = s.id AND m.user_id = @viewer) AS joined_by
+		FROM post_series s
+		WHERE s.id IN @ids`, map[string]any{"ids": seriesIDs, "viewer": viewerID}).Scan(&rows).Error
+	for _, r := range rows {
+		out[r.SeriesID] = r
+	}
+	return out, err
+}
+
+// JoinPostSeries makes the user a member of the series and joins them to its occurrences starting after now,
+// each on its own terms (waitlisted when full). Joining again is a no-op.
+func JoinPostSeries(userID, seriesID uint, now time.Time) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		series, err := lockSeries(tx, seriesID)
+		if err != nil {
+			return err
+		}
+		if series.EndedAt != nil {
+			return ErrSeriesEnded
+		}
+		member := model.PostSeriesMember{SeriesID: seriesID, UserID: userID}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&member).Error; err != nil {
+			return err
+		}
+		ids, err := upcomingOccurrences(tx, seriesID, now)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if _, err := joinUserToPost(tx, userID, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// LeavePostSeries ends the user's series membership and leaves its occurrences starting after now;
+// occurrences the user already checked in to are kept
+func LeavePostSeries(userID, seriesID uint, now time.Time) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockSeries(tx, seriesID); err != nil {
+			return err
+		}
+		res := tx.Where("series_id = ? AND user_id = ?", seriesID, userID).Delete(&model.PostSeriesMember{})
+		if res.Error != nil {
+			return res.Error
+		}
+		ids, err := upcomingOccurrences(tx, seriesID, now)
+		if err != nil {
+			return err
+		}
+		left := res.RowsAffected > 0
+		for _, id := range ids {
+			err := leavePost(tx, userID, id)
+			switch {
+			case err == nil:
+				left = true
+			case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, ErrCheckedIn):
+			default:
+				return err
+			}
+		}
+		if !left {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// EndPostSeries cancels the series: no further occurrences are created and those starting after now are
+// deleted, with their cancellations kept for calendars. Returns the deleted occurrences.
+func EndPostSeries(seriesID uint, now time.Time) ([]uint, error) {
+	var ids []uint
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		series, err := lockSeries(tx, seriesID)
+		if err != nil {
+			return err
+		}
+		if series.EndedAt != nil {
+			return ErrSeriesEnded
+		}
+		if err := tx.Model(&series).UpdateColumn("ended_at", now).Error; err != nil {
+			return err
+		}
+		if ids, err = upcomingOccurrences(tx, seriesID, now); err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if err := deletePost(tx, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return ids, err
+}
+
+func upcomingOccurrences(tx *gorm.DB, seriesID uint, now time.Time) ([]uint, error) {
+	var ids []uint
+	err := tx.Model(&model.Post{}).Where("series_id = ? AND start_date > ?", seriesID, now).
+		Order("start_date ASC, id ASC").Pluck("id", &ids).Error
+	return ids, err
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"mosprom/api/internal/db"
+	"mosprom/api/internal/model"
+	"time"
+)
+
+// DemandPostTypes are the post types whose technologies count as industry demand
+var DemandPostTypes = []model.PostType{model.Vacancy, model.Internship, model.Project}
+
+// SkillsGapRow is a technology within a direction with its demand and supply.
+// Technologies without a direction have an empty Direction.
+type SkillsGapRow struct {
+	Direction    string `gorm:"column:direction"`
+	TechnologyID uint   `gorm:"column:technology_id"`
+	Technology   string `gorm:"column:technology"`
+	Demand       int    `gorm:"column:demand"` // demand posts created in the range requiring it
+	Supply       int    `gorm:"column:supply"` // users having it now
+}
+
+// GetSkillsGap returns demand and supply for every technology that has either, optionally within one direction
+func GetSkillsGap(from, to time.Time, direction string) ([]SkillsGapRow, error) {
+	q := `
+		WITH demand AS (
+			SELECT pt.technology_id, COUNT(DISTINCT p.id) AS cnt
+			FROM post_technologies pt
+			JOIN posts p ON p.id = pt.post_id
+			WHERE p.type IN @types AND p.created_at >= @from AND p.created_at < @to
+			GROUP BY pt.technology_id
+		),
+		supply AS (
+			SELECT technology_id, COUNT(*) AS cnt FROM user_technologies GROUP BY technology_id
+		)
+		SELECT COALESCE(d.name, '') AS direction,
+			   t.id AS technology_id,
+			   t.name AS technology,
+			   COALESCE(dm.cnt, 0) AS demand,
+			   COALESCE(sp.cnt, 0) AS supply
+		FROM technologies t
+		LEFT JOIN direction_technologies dt ON dt.technology_id = t.id
+		LEFT JOIN directions d ON d.id = dt.direction_id
+		LEFT JOIN demand dm ON dm.technology_id = t.id
+		LEFT JOIN supply sp ON sp.technology_id = t.id
+		WHERE (dm.cnt IS NOT NULL OR sp.cnt IS NOT NULL)
+		  AND (@direction = '' OR LOWER(d.name) = LOWER(@direction))
+		ORDER BY direction ASC, demand DESC, t.name ASC`
+	var rows []SkillsGapRow
+	err := db.DB.Raw(q, map[string]any{
+		"types":     DemandPostTypes,
+		"from":      from,
+		"to":        to,
+		"direction": direction,
+	}).Scan(&rows).Error
+	return rows, err
+}
+
+// SkillsTrendRow is the demand and new supply of a technology in one period
+type SkillsTrendRow struct {
+	TechnologyID uint      `gorm:"column:technology_id"`
+	Period       time.Time `gorm:"column:period"`
+	Demand       int       `gorm:"column:demand"`
+	NewSupply    int       `gorm:"column:new_supply"` // users who added it in the period
+}
+
+// GetSkillsTrend buckets demand posts and newly added user technologies by unit (day, week or month).
+// Periods without activity are omitted.
+func GetSkillsTrend(from, to time.Time, unit string, technologyIDs []uint) ([]SkillsTrendRow, error) {
+	if len(technologyIDs) == 0 {
+		return nil, nil
+	}
+	q := `
+		WITH demand AS (
+			SELECT pt.technology_id, date_trunc(@unit, p.created_at) AS period, COUNT(DISTINCT p.id) AS cnt
+			FROM post_technologies pt
+			JOIN posts p ON p.id = pt.post_id
+			WHERE p.type IN @types AND p.created_at >= @from AND p.created_at < @to AND pt.technology_id IN @techs
+			GROUP BY 1, 2
+		),
+		supply AS (
+			SELECT technology_id, date_trunc(@unit, created_at) AS period, COUNT(*) AS cnt
+			FROM user_technologies
+			WHERE created_at >= @from AND created_at < @to AND technology_id IN @techs
+			GROUP BY 1, 2
+		)
+		SELECT COALESCE(dm.technology_id, sp.technology_id) AS technology_id,
+			   COALESCE(dm.period, sp.period) AS period,
+			   COALESCE(dm.cnt, 0) AS demand,
+			   COALESCE(sp.cnt, 0) AS new_supply
+		FROM demand dm
+		FULL OUTER JOIN supply sp ON sp.technology_id = dm.technology_id AND sp.period = dm.period
+		ORDER BY technology_id, period`
+	var rows []SkillsTrendRow
+	err := db.DB.Raw(q, map[string]any{
+		"types": DemandPostTypes,
+		"from":  from,
+		"to":    to,
+		"unit":  unit,
+		"techs": technologyIDs,
+	}).Scan(&rows).Error
+	return rows, err
+}
+
+// CountUsersWithTechnologies returns how many users list at least one technology
+func CountUsersWithTechnologies() (int64, error) {
+	var n int64
+	err := db.DB.Table("user_technologies").Distinct("user_id").Count(&n).Error
+	return n, err
+}
+
+// CountDemandPosts counts demand posts created in [from, to)
+func CountDemandPosts(from, to time.Time) (int64, error) {
+	var n int64
+	err := db.DB.Model(&model.Post{}).
+		Where("type IN ? AND created_at >= ? AND created_at < ?", DemandPostTypes, from, to).
+		Count(&n).Error
+	return n, err
+}
@@ -42,6 +42,11 @@ func DeleteUser(id uint) error {
 }
 
 func FindOrCreateTechnologiesByNames(names []string) ([]model.Technology, error) {
+	return findOrCreateTechnologiesByNames(db.DB, names)
+}
+
+// findOrCreateTechnologiesByNames resolves technology names inside the caller's transaction
+func findOrCreateTechnologiesByNames(tx *gorm.DB, names []string) ([]model.Technology, error) {
 	if len(names) == 0 {
 		return nil, nil
 	}
@@ -51,10 +56,10 @@ func FindOrCreateTechnologiesByNames(names []string) ([]model.Technology, error)
 			continue
 		}
 		var t model.Technology
-		if err := db.DB.Where("name = ?", n).First(&t).Error; err != nil {
+		if err := tx.Where("name = ?", n).First(&t).Error; err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				t = model.Technology{Name: n}
-				if err := db.DB.Create(&t).Error; err != nil {
+				if err := tx.Create(&t).Error; err != nil {
 					return nil, err
 				}
 			} else {
@@ -148,14 +153,14 @@ func GetUsersByIDsLight(ids []uint) ([]model.User, error) {
 	return users, err
 }
 
-// GetParticipationCounts returns map of user_id -> events count from post_participants and the maximum events among all users
+// GetParticipationCounts returns map of user_id -> attended events (check-ins in post_attendances) and the maximum events among all users
 func GetParticipationCounts() (map[uint]int64, int64, error) {
 	type row struct {
 		UserID uint  `gorm:"column:user_id"`
 		Cnt    int64 `gorm:"column:cnt"`
 	}
 	var rows []row
-	err := db.DB.Table("post_participants").
+	err := db.DB.Table("post_attendances").
 		Select("user_id, COUNT(*) as cnt").
 		Group("user_id").
 		Find(&rows).Error
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"mosprom/api/internal/db"
+	"mosprom/api/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func CreateWebhook(w *model.Webhook) error {
+	return db.DB.Create(w).Error
+}
+
+func GetClubWebhooks(clubID uint) ([]model.Webhook, error) {
+	var items []model.Webhook
+	err := db.DB.Where("club_id = ?", clubID).Order("id ASC").Find(&items).Error
+	return items, err
+}
+
+// GetClubWebhook returns a webhook only if it belongs to the club
+func GetClubWebhook(clubID, webhookID uint) (model.Webhook, error) {
+	var w model.Webhook
+	err := db.DB.Where("club_id = ?", clubID).First(&w, webhookID).Error
+	return w, err
+}
+
+func GetWebhookByID(id uint) (model.Webhook, error) {
+	var w model.Webhook
+	err := db.DB.First(&w, id).Error
+	return w, err
+}
+
+func GetActiveWebhooksByClubID(clubID uint) ([]model.Webhook, error) {
+	var items []model.Webhook
+	err := db.DB.Where("club_id = ? AND active", clubID).Find(&items).Error
+	return items, err
+}
+
+func UpdateWebhook(w *model.Webhook) error {
+	return db.DB.Save(w).Error
+}
+
+func DeleteWebhook(id uint) error {
+	return db.DB.Delete(&model.Webhook{}, id).Error
+}
+
+// CreateWebhookDelivery stores a delivery together with the job that sends it.
+// An original delivery already recorded for the same webhook and event is left untouched and false is returned.
+func CreateWebhookDelivery(d *model.WebhookDelivery, newJob func(deliveryID uint) (model.Job, error)) (bool, error) {
+	created := false
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		q := tx
+		if d.ReplayOfID == nil {
+			q = q.Clauses(clause.OnConflict{DoNothing: true})
+		}
+		res := q.Create(d)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		job, err := newJob(d.ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(&job).Error; err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	return created, err
+}
+
+func GetWebhookDeliveryByID(id uint) (model.WebhookDelivery, error) {
+	var d model.WebhookDelivery
+	err := db.DB.First(&d, id).Error
+	return d, err
+}
+
+// GetWebhookDeliveries returns the newest deliveries of a webhook
+func GetWebhookDeliveries(webhookID uint, limit int) ([]model.WebhookDelivery, error) {
+	var items []model.WebhookDelivery
+	err := db.DB.Where("webhook_id = ?", webhookID).Order("id DESC").Limit(limit).Find(&items).Error
+	return items, err
+}
+
+// RecordWebhookAttempt saves the outcome of one delivery attempt
+func RecordWebhookAttempt(d *model.WebhookDelivery) error {
+	return db.DB.Model(&model.WebhookDelivery{}).Where("id = ?", d.ID).Updates(map[string]any{
+		"status":          d.Status,
+		"attempts":        gorm.Expr("attempts + 1"),
+		"response_status": d.ResponseStatus,
+		"last_error":      d.LastError,
+		"delivered_at":    d.DeliveredAt,
+	}).Error
+}
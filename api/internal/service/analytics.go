@@ -0,0 +1,124 @@
+package service
+
+import (
+	"errors"
+	"mosprom/api/internal/repository"
+	"time"
+)
+
+const (
+	defaultAnalyticsRange = 90 * 24 * time.Hour
+	maxAnalyticsBuckets   = 400
+)
+
+type ClubAnalyticsQuery struct {
+	From     *time.Time
+	To       *time.Time
+	Interval string // day, week or month
+}
+
+type SubscriberStats struct {
+	Total  int                              `json:"total"`
+	New    int                              `json:"new"`
+	Growth []repository.SubscriberGrowthRow `json:"growth"`
+}
+
+type ConversionStats struct {
+	Views int     `json:"views"`
+	Joins int     `json:"joins"`
+	Rate  float64 `json:"rate"` // joins / views, 0 without views
+}
+
+type PostEngagement struct {
+	repository.PostEngagementRow
+	Conversion float64 `json:"conversion"`
+}
+
+type ClubAnalytics struct {
+	ClubID       uint                            `json:"club_id"`
+	From         time.Time                       `json:"from"`
+	To           time.Time                       `json:"to"`
+	Interval     string                          `json:"interval"`
+	Subscribers  SubscriberStats                 `json:"subscribers"`
+	PostsByType  []repository.PostsByTypeRow     `json:"posts_by_type"`
+	Posts        []PostEngagement                `json:"posts"`
+	Conversion   ConversionStats                 `json:"conversion"`
+	TopMembers   []repository.ActiveMemberRow    `json:"top_members"`
+	Technologies []repository.TechnologyShareRow `json:"technologies"` // of current members, not range-filtered
+}
+
+// Analytics builds the engagement dashboard of a club for [from, to); defaults to the last 90 days by week
+func (s *ClubService) Analytics(actorID uint, role string, clubID uint, q ClubAnalyticsQuery) (ClubAnalytics, error) {
+	if err := s.authorize(actorID, role, clubID); err != nil {
+		return ClubAnalytics{}, err
+	}
+	club, err := repository.GetClubByID(clubID)
+	if err != nil {
+		return ClubAnalytics{}, err
+	}
+	to := time.Now()
+	if q.To != nil {
+		to = *q.To
+	}
+	from := to.Add(-defaultAnalyticsRange)
+	if q.From != nil {
+		from = *q.From
+	}
+	if !from.Before(to) {
+		return ClubAnalytics{}, errors.New("from must be before to")
+	}
+	interval := q.Interval
+	var bucket time.Duration
+	switch interval {
+	case "":
+		interval, bucket = "week", 7*24*time.Hour
+	case "day":
+		bucket = 24 * time.Hour
+	case "week":
+		bucket = 7 * 24 * time.Hour
+	case "month":
+		bucket = 30 * 24 * time.Hour
+	default:
+		return ClubAnalytics{}, errors.New("interval must be day, week or month")
+	}
+	if to.Sub(from)/bucket > maxAnalyticsBuckets {
+		return ClubAnalytics{}, errors.New("range is too long for the interval")
+	}
+
+	res := ClubAnalytics{ClubID: clubID, From: from, To: to, Interval: interval}
+	if res.Subscribers.Growth, err = repository.GetClubSubscriberGrowth(clubID, from, to, interval); err != nil {
+		return ClubAnalytics{}, err
+	}
+	res.Subscribers.Total = club.SubscribersCount
+	for _, g := range res.Subscribers.Growth {
+		res.Subscribers.New += g.NewSubscribers
+	}
+	if res.PostsByType, err = repository.GetClubPostsByType(clubID, from, to); err != nil {
+		return ClubAnalytics{}, err
+	}
+	rows, err := repository.GetClubPostEngagement(clubID, from, to)
+	if err != nil {
+		return ClubAnalytics{}, err
+	}
+	res.Posts = make([]PostEngagement, 0, len(rows))
+	for _, r := range rows {
+		res.Posts = append(res.Posts, PostEngagement{PostEngagementRow: r, Conversion: ratio(r.Joins, r.Views)})
+		res.Conversion.Views += r.Views
+		res.Conversion.Joins += r.Joins
+	}
+	res.Conversion.Rate = ratio(res.Conversion.Joins, res.Conversion.Views)
+	if res.TopMembers, err = repository.GetClubActiveMembers(clubID, from, to, 10); err != nil {
+		return ClubAnalytics{}, err
+	}
+	if res.Technologies, err = repository.GetClubMemberTechnologies(clubID, 20); err != nil {
+		return ClubAnalytics{}, err
+	}
+	return res, nil
+}
+
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"fmt"
+	"mosprom/api/internal/ical"
+	"mosprom/api/internal/model"
+	"mosprom/api/internal/repository"
+	"strings"
+	"time"
+)
+
+const (
+	calendarPastWindow     = 30 * 24 * time.Hour // feeds keep events that ended within this window
+	calendarRefresh        = time.Hour           // suggested polling interval of subscribed feeds
+	calendarOnlineLocation = "Online"
+)
+
+// CalendarFeed is the current user's subscribable calendar
+type CalendarFeed struct {
+	Token string `json:"token"`
+	URL   string `json:"url"` // secret feed URL to add to a calendar app
+}
+
+type CalendarService struct {
+	webURL string // public web app URL used for links back to posts
+}
+
+// NewCalendarService creates the service; webURL (e.g. PUBLIC_WEB_URL) may be empty,
+// in which case callers pass a base URL per request
+func NewCalendarService(webURL string) *CalendarService {
+	return &CalendarService{webURL: strings.TrimRight(webURL, "/")}
+}
+
+// calendarUID is the stable identifier of a post's event across updates and cancellation
+func calendarUID(postID uint) string {
+	return fmt.Sprintf("post-%d@mosprom", postID)
+}
+
+func (s *CalendarService) postURL(base string, postID uint) string {
+	if s.webURL != "" {
+		base = s.webURL
+	}
+	if base == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/posts/%d", strings.TrimRight(base, "/"), postID)
+}
+
+func (s *CalendarService) postEvent(p model.Post, base string) ical.Event {
+	location := p.Address
+	if p.Format != nil && *p.Format == model.Online {
+		location = calendarOnlineLocation
+	}
+	summary := p.Title
+	if p.Club != nil {
+		summary = p.Club.Name + ": " + p.Title
+	}
+	return ical.Event{
+		UID:          calendarUID(p.ID),
+		Sequence:     p.Sequence,
+		Status:       ical.StatusConfirmed,
+		Summary:      summary,
+		Description:  p.Description,
+		Location:     location,
+		URL:          s.postURL(base, p.ID),
+		Start:        *p.StartDate,
+		End:          p.EndDate,
+		Created:      p.CreatedAt,
+		LastModified: p.UpdatedAt,
+	}
+}
+
+func cancelledEvent(c model.CancelledPost) ical.Event {
+	return ical.Event{
+		UID:          calendarUID(c.PostID),
+		Sequence:     c.Sequence,
+		Status:       ical.StatusCancelled,
+		Summary:      c.Title,
+		Start:        c.StartDate,
+		End:          c.EndDate,
+		Created:      c.CreatedAt,
+		LastModified: c.CancelledAt,
+	}
+}
+
+// PostCalendar returns a single post as a calendar; deleted posts come back cancelled
+func (s *CalendarService) PostCalendar(postID uint, base string) (ical.Calendar, error) {
+	p, err := repository.GetPostByID(postID)
+	if err == nil {
+		if p.StartDate == nil {
+			return ical.Calendar{}, fmt.Errorf("post has no start date")
+		}
+		return ical.Calendar{Name: p.Title, Events: []ical.Event{s.postEvent(p, base)}}, nil
+	}
+	c, cerr := repository.GetCancelledPost(postID)
+	if cerr != nil {
+		return ical.Calendar{}, err
+	}
+	return ical.Calendar{Name: c.Title, Events: []ical.Event{cancelledEvent(c)}}, nil
+}
+
+// UserCalendar returns the feed of the token's owner: joined posts and cancellations of posts they had joined
+func (s *CalendarService) UserCalendar(token, base string) (ical.Calendar, error) {
+	userID, err := repository.GetUserIDByCalendarToken(strings.TrimSpace(token))
+	if err != nil {
+		return ical.Calendar{}, err
+	}
+	since := time.Now().Add(-calendarPastWindow)
+	posts, err := repository.GetUserCalendarPosts(userID, since)
+	if err != nil {
+		return ical.Calendar{}, err
+	}
+	cancelled, err := repository.GetUserCancelledPosts(userID, since)
+	if err != nil {
+		return ical.Calendar{}, err
+	}
+	return s.feed("My events", posts, cancelled, base), nil
+}
+
+// ClubCalendar returns the feed of posts the club hosts or co-hosts
+func (s *CalendarService) ClubCalendar(clubID uint, base string) (ical.Calendar, error) {
+	club, err := repository.GetClubByID(clubID)
+	if err != nil {
+		return ical.Calendar{}, err
+	}
+	since := time.Now().Add(-calendarPastWindow)
+	posts, err := repository.GetClubCalendarPosts(clubID, since)
+	if err != nil {
+		return ical.Calendar{}, err
+	}
+	cancelled, err := repository.GetClubCancelledPosts(clubID, since)
+	if err != nil {
+		return ical.Calendar{}, err
+	}
+	return s.feed(club.Name, posts, cancelled, base), nil
+}
+
+func (s *CalendarService) feed(name string, posts []model.Post, cancelled []model.CancelledPost, base string) ical.Calendar {
+	cal := ical.Calendar{Name: name, Refresh: calendarRefresh, Events: make([]ical.Event, 0, len(posts)+len(cancelled))}
+	for _, p := range posts {
+		cal.Events = append(cal.Events, s.postEvent(p, base))
+	}
+	for _, c := range cancelled {
+		cal.Events = append(cal.Events, cancelledEvent(c))
+	}
+	return cal
+}
+
+// MyCalendar returns the user's feed token and URL, creating the token on first use
+func (s *CalendarService) MyCalendar(userID uint, apiBase string) (CalendarFeed, error) {
+	token, err := newInviteToken()
+	if err != nil {
+		return CalendarFeed{}, err
+	}
+	t, err := repository.GetCalendarToken(userID, token)
+	if err != nil {
+		return CalendarFeed{}, err
+	}
+	return calendarFeed(t, apiBase), nil
+}
+
+// ResetMyCalendar issues a new feed token; subscriptions using the old URL stop updating
+func (s *CalendarService) ResetMyCalendar(userID uint, apiBase string) (CalendarFeed, error) {
+	token, err := newInviteToken()
+	if err != nil {
+		return CalendarFeed{}, err
+	}
+	t, err := repository.RotateCalendarToken(userID, token)
+	if err != nil {
+		return CalendarFeed{}, err
+	}
+	return calendarFeed(t, apiBase), nil
+}
+
+func calendarFeed(t model.CalendarToken, apiBase string) CalendarFeed {
+	return CalendarFeed{Token: t.Token, URL: strings.TrimRight(apiBase, "/") + "/me/calendar.ics?token=" + t.Token}
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mosprom/api/internal/jobs"
+	"mosprom/api/internal/model"
+	"mosprom/api/internal/repository"
+	"strings"
+	"time"
+)
+
+// RunCampaignsJob is the background job type that sends invitations for due campaigns
+const RunCampaignsJob = "campaigns.run_due"
+
+// CampaignTickSpec is how often due campaigns are looked up
+const CampaignTickSpec = "* * * * *"
+
+const defaultCampaignBatch = 50
+
+type CampaignService struct {
+	clubs *ClubService
+}
+
+func NewCampaignService() *CampaignService { return &CampaignService{clubs: NewClubService()} }
+
+type CreateCampaignInput struct {
+	Name           string     `json:"name" binding:"required"`
+	PostID         *uint      `json:"post_id"` // invite to this club post instead of the club
+	Message        string     `json:"message"`
+	Technologies   []string   `json:"technologies"`
+	Directions     []string   `json:"directions"`
+	University     string     `json:"university"`
+	MinRating      float64    `json:"min_rating"`
+	MemberOfClubID *uint      `json:"member_of_club_id"`
+	Quota          int        `json:"quota" binding:"required"`
+	BatchSize      int        `json:"batch_size"` // default 50, capped by quota
+	Schedule       string     `json:"schedule"`   // cron spec, default @daily
+	StartAt        *time.Time `json:"start_at"`   // first run, default now
+}
+
+func (s *CampaignService) Create(actorID uint, role string, clubID uint, input CreateCampaignInput) (model.Campaign, error) {
+	if err := s.clubs.authorize(actorID, role, clubID); err != nil {
+		return model.Campaign{}, err
+	}
+	archived, err := repository.IsClubArchived(clubID)
+	if err != nil {
+		return model.Campaign{}, err
+	}
+	if archived {
+		return model.Campaign{}, ErrClubArchived
+	}
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return model.Campaign{}, errors.New("name must not be empty")
+	}
+	if input.Quota < 1 {
+		return model.Campaign{}, errors.New("quota must be positive")
+	}
+	if input.MinRating < 0 || input.MinRating > 10 {
+		return model.Campaign{}, errors.New("min_rating must be between 0 and 10")
+	}
+	batch := input.BatchSize
+	if batch < 0 {
+		return model.Campaign{}, errors.New("batch_size must be positive")
+	}
+	if batch == 0 {
+		batch = defaultCampaignBatch
+	}
+	batch = min(batch, input.Quota, maxInviteesPerRequest)
+	spec := strings.TrimSpace(input.Schedule)
+	if spec == "" {
+		spec = "@daily"
+	}
+	sched, err := jobs.ParseCron(spec)
+	if err != nil {
+		return model.Campaign{}, fmt.Errorf("invalid schedule: %w", err)
+	}
+	if sched.Next(time.Now()).IsZero() {
+		return model.Campaign{}, fmt.Errorf("invalid schedule: %q never fires", spec)
+	}
+	if input.PostID != nil {
+		post, err := repository.GetPostByID(*input.PostID)
+		if err != nil {
+			return model.Campaign{}, err
+		}
+		if post.ClubID != clubID {
+			return model.Campaign{}, errors.New("post does not belong to the club")
+		}
+	}
+	if input.MemberOfClubID != nil {
+		if _, err := repository.GetClubByID(*input.MemberOfClubID); err != nil {
+			return model.Campaign{}, err
+		}
+	}
+	next := time.Now()
+	if input.StartAt != nil && input.StartAt.After(next) {
+		next = *input.StartAt
+	}
+	c := model.Campaign{
+		ClubID:         clubID,
+		PostID:         input.PostID,
+		Name:           name,
+		Message:        strings.TrimSpace(input.Message),
+		Technologies:   input.Technologies,
+		Directions:     input.Directions,
+		University:     strings.TrimSpace(input.University),
+		MinRating:      input.MinRating,
+		MemberOfClubID: input.MemberOfClubID,
+		Quota:          input.Quota,
+		BatchSize:      batch,
+		Schedule:       spec,
+		Status:         model.CampaignActive,
+		NextRunAt:      &next,
+		CreatedByID:    actorID,
+	}
+	if err := repository.CreateCampaign(&c); err != nil {
+		return model.Campaign{}, err
+	}
+	c.Stats = &model.CampaignStats{}
+	return c, nil
+}
+
+// List returns the club's campaigns with sent/accepted/declined counts
+func (s *CampaignService) List(actorID uint, role string, clubID uint) ([]model.Campaign, error) {
+	if err := s.clubs.authorize(actorID, role, clubID); err != nil {
+		return nil, err
+	}
+	items, err := repository.GetClubCampaigns(clubID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(items))
+	for _, c := range items {
+		ids = append(ids, c.ID)
+	}
+	stats, err := repository.GetCampaignStats(ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		st := stats[items[i].ID]
+		items[i].Stats = &st
+	}
+	return items, nil
+}
+
+func (s *CampaignService) Get(actorID uint, role string, clubID, campaignID uint) (model.Campaign, error) {
+	if err := s.clubs.authorize(actorID, role, clubID); err != nil {
+		return model.Campaign{}, err
+	}
+	return s.withStats(repository.GetClubCampaign(clubID, campaignID))
+}
+
+func (s *CampaignService) Pause(actorID uint, role string, clubID, campaignID uint) (model.Campaign, error) {
+	return s.setStatus(actorID, role, clubID, campaignID, model.CampaignPaused)
+}
+
+// Resume reactivates a paused campaign; it runs on the next tick
+func (s *CampaignService) Resume(actorID uint, role string, clubID, campaignID uint) (model.Campaign, error) {
+	return s.setStatus(actorID, role, clubID, campaignID, model.CampaignActive)
+}
+
+func (s *CampaignService) setStatus(actorID uint, role string, clubID, campaignID uint, status model.CampaignStatus) (model.Campaign, error) {
+	if err := s.clubs.authorize(actorID, role, clubID); err != nil {
+		return model.Campaign{}, err
+	}
+	c, err := repository.GetClubCampaign(clubID, campaignID)
+	if err != nil {
+		return model.Campaign{}, err
+	}
+	if c.Status == model.CampaignCompleted {
+		return model.Campaign{}, errors.New("campaign is completed")
+	}
+	var next *time.Time
+	if status == model.CampaignActive {
+		archived, err := repository.IsClubArchived(clubID)
+		if err != nil {
+			return model.Campaign{}, err
+		}
+		if archived {
+			return model.Campaign{}, ErrClubArchived
+		}
+		now := time.Now()
+		next = &now
+	}
+	if err := repository.SetCampaignStatus(c.ID, status, next); err != nil {
+		return model.Campaign{}, err
+	}
+	return s.withStats(repository.GetClubCampaign(clubID, campaignID))
+}
+
+func (s *CampaignService) withStats(c model.Campaign, err error) (model.Campaign, error) {
+	if err != nil {
+		return model.Campaign{}, err
+	}
+	stats, err := repository.GetCampaignStats([]uint{c.ID})
+	if err != nil {
+		return model.Campaign{}, err
+	}
+	st := stats[c.ID]
+	c.Stats = &st
+	return c, nil
+}
+
+// RunDue sends the next batch of every due campaign; it is the RunCampaignsJob handler
+func (s *CampaignService) RunDue(ctx context.Context, _ struct{}) error {
+	now := time.Now()
+	for ctx.Err() == nil {
+		found, err := repository.RunNextDueCampaign(now, nextCampaignRun)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
+
+func nextCampaignRun(c model.Campaign) time.Time {
+	sched, err := jobs.ParseCron(c.Schedule)
+	if err != nil {
+		// validated on create; fall back to daily rather than stalling the campaign
+		log.Printf("campaign %d: invalid schedule %q: %v", c.ID, c.Schedule, err)
+		return time.Now().Add(24 * time.Hour)
+	}
+	next := sched.Next(time.Now())
+	if next.IsZero() {
+		// a zero time would make the campaign due on every poll
+		log.Printf("campaign %d: schedule %q never fires", c.ID, c.Schedule)
+		return time.Now().Add(24 * time.Hour)
+	}
+	return next
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mosprom/api/internal/events"
+	"mosprom/api/internal/model"
+	"mosprom/api/internal/repository"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	checkInCodePeriod  = time.Minute   // codes rotate every period; the previous code is still accepted
+	checkInOpensBefore = time.Hour     // check-in opens before the start
+	checkInClosesAfter = 2 * time.Hour // and closes after the end, or after the start without an end
+	checkInQRSize      = 512           // QR image side in pixels
+)
+
+var (
+	ErrNotParticipant     = repository.ErrNotParticipant
+	ErrCheckedIn          = repository.ErrCheckedIn
+	ErrCheckInClosed      = errors.New("check-in is not open for this post")
+	ErrInvalidCheckInCode = errors.New("invalid or expired check-in code")
+)
+
+// Achievements awarded for the number of attended events, in ascending order
+var attendanceAchievements = []struct {
+	Events int64
+	Name   string
+}{
+	{1, "First event attended"},
+	{5, "Attended 5 events"},
+	{10, "Attended 10 events"},
+	{25, "Attended 25 events"},
+	{50, "Attended 50 events"},
+}
+
+// CheckInCode is the current code of a post; organizers show it (as a QR code) and participants scan it
+type CheckInCode struct {
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expires_at"` // when the next code is issued; this one is accepted for another period
+}
+
+func checkInSecret() ([]byte, error) {
+	secret := os.Getenv("CHECKIN_SECRET")
+	if secret == "" {
+		secret = os.Getenv("JWT_SECRET")
+	}
+	if secret == "" {
+		return nil, errors.New("check-in secret is not configured")
+	}
+	return []byte(secret), nil
+}
+
+func signCheckIn(secret []byte, postID uint, window int64) string {
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "checkin:%d:%d", postID, window)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:12])
+}
+
+// issueCheckInCode returns the code "<post>.<window>.<signature>" of the period containing now
+func issueCheckInCode(postID uint, now time.Time) (CheckInCode, error) {
+	secret, err := checkInSecret()
+	if err != nil {
+		return CheckInCode{}, err
+	}
+	window := now.Unix() / int64(checkInCodePeriod/time.Second)
+	return CheckInCode{
+		Code:      fmt.Sprintf("%d.%d.%s", postID, window, signCheckIn(secret, postID, window)),
+		ExpiresAt: time.Unix((window+1)*int64(checkInCodePeriod/time.Second), 0),
+	}, nil
+}
+
+// verifyCheckInCode accepts codes of the post issued in the current or the previous period
+func verifyCheckInCode(postID uint, code string, now time.Time) bool {
+	secret, err := checkInSecret()
+	if err != nil {
+		return false
+	}
+	parts := strings.Split(strings.TrimSpace(code), ".")
+	if len(parts) != 3 || parts[0] != strconv.FormatUint(uint64(postID), 10) {
+		return false
+	}
+	window, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return false
+	}
+	current := now.Unix() / int64(checkInCodePeriod/time.Second)
+	if window != current && window != current-1 {
+		return false
+	}
+	return hmac.Equal([]byte(parts[2]), []byte(signCheckIn(secret, postID, window)))
+}
+
+// checkInOpen reports whether participants can scan in at now: from an hour before the start
+// until two hours after the end. Posts without a start date have no check-in window.
+func checkInOpen(post model.Post, now time.Time) bool {
+	if post.StartDate == nil {
+		return false
+	}
+	end := *post.StartDate
+	if post.EndDate != nil && post.EndDate.After(end) {
+		end = *post.EndDate
+	}
+	return !now.Before(post.StartDate.Add(-checkInOpensBefore)) && !now.After(end.Add(checkInClosesAfter))
+}
+
+// CheckIn records the participant's attendance from a scanned code within the event window
+func (s *PostService) CheckIn(userID, postID uint, code string) (model.PostAttendance, error) {
+	post, err := repository.GetPostByID(postID)
+	if err != nil {
+		return model.PostAttendance{}, err
+	}
+	now := time.Now()
+	if !checkInOpen(post, now) {
+		return model.PostAttendance{}, ErrCheckInClosed
+	}
+	if !verifyCheckInCode(postID, code, now) {
+		return model.PostAttendance{}, ErrInvalidCheckInCode
+	}
+	att := model.PostAttendance{PostID: postID, UserID: userID, Method: model.CheckInQR}
+	if err := repository.CheckInUser(&att); err != nil {
+		return model.PostAttendance{}, err
+	}
+	return att, nil
+}
+
+// CheckInCode issues the post's current code; host club managers only
+func (s *ClubService) CheckInCode(actorID uint, role string, postID uint) (CheckInCode, error) {
+	post, err := repository.GetPostByID(postID)
+	if err != nil {
+		return CheckInCode{}, err
+	}
+	if err := s.authorize(actorID, role, post.ClubID); err != nil {
+		return CheckInCode{}, err
+	}
+	return issueCheckInCode(postID, time.Now())
+}
+
+// CheckInQR renders the post's current code as a PNG QR code; host club managers only
+func (s *ClubService) CheckInQR(actorID uint, role string, postID uint) ([]byte, CheckInCode, error) {
+	code, err := s.CheckInCode(actorID, role, postID)
+	if err != nil {
+		return nil, CheckInCode{}, err
+	}
+	png, err := qrcode.Encode(code.Code, qrcode.Medium, checkInQRSize)
+	if err != nil {
+		return nil, CheckInCode{}, err
+	}
+	return png, code, nil
+}
+
+// ManualCheckIn records a participant's attendance on an organizer's behalf, at any time
+func (s *ClubService) ManualCheckIn(actorID uint, role string, postID, userID uint) (model.PostAttendance, error) {
+	post, err := repository.GetPostByID(postID)
+	if err != nil {
+		return model.PostAttendance{}, err
+	}
+	if err := s.authorize(actorID, role, post.ClubID); err != nil {
+		return model.PostAttendance{}, err
+	}
+	att := model.PostAttendance{PostID: postID, UserID: userID, Method: model.CheckInManual, CheckedInByID: &actorID}
+	if err := repository.CheckInUser(&att); err != nil {
+		return model.PostAttendance{}, err
+	}
+	return att, nil
+}
+
+// Attendance lists the post's check-ins; host club managers only
+func (s *ClubService) Attendance(actorID uint, role string, postID uint) ([]model.PostAttendance, error) {
+	post, err := repository.GetPostByID(postID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(actorID, role, post.ClubID); err != nil {
+		return nil, err
+	}
+	items, err := repository.GetPostAttendance(postID)
+	for i := range items {
+		if items[i].User != nil {
+			items[i].User.Password = ""
+		}
+	}
+	return items, err
+}
+
+// OnUserCheckedIn awards the attendance milestone achievements the user has reached and not got yet
+func (s *UserService) OnUserCheckedIn(ctx context.Context, e events.Event) error {
+	cnt, err := repository.CountUserAttendance(e.UserID)
+	if err != nil {
+		return err
+	}
+	for _, a := range attendanceAchievements {
+		if cnt < a.Events {
+			break
+		}
+		if err := repository.AddAchievementOnce(e.UserID, a.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,12 +1,21 @@
 package service
 
 import (
+	"errors"
 	"mosprom/api/internal/model"
 	"mosprom/api/internal/repository"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// ErrForbidden is returned when the acting user may not manage the club
+var ErrForbidden = errors.New("forbidden")
+
+// ErrClubArchived is returned by writes to an archived club
+var ErrClubArchived = repository.ErrClubArchived
+
 type ClubService struct{}
 
 func NewClubService() *ClubService { return &ClubService{} }
@@ -56,6 +65,13 @@ func (s *ClubService) ListByDirections(names []string) ([]model.Club, error) {
 }
 
 func (s *ClubService) SetLogo(clubID uint, filename string) error {
+	archived, err := repository.IsClubArchived(clubID)
+	if err != nil {
+		return err
+	}
+	if archived {
+		return ErrClubArchived
+	}
 	return repository.SetClubLogo(clubID, filename)
 }
 
@@ -63,10 +79,6 @@ func (s *ClubService) ListFiltered(name *string, directions []string) ([]model.C
 	return repository.ListClubsFiltered(name, directions)
 }
 
-func (s *ClubService) Subscribe(userID, clubID uint) error {
-	return repository.SubscribeUserToClub(userID, clubID)
-}
-
 func (s *ClubService) Subscribers(clubID uint) ([]model.User, error) {
 	return repository.GetClubSubscribers(clubID)
 }
@@ -75,6 +87,128 @@ func (s *ClubService) ClubsOfUser(userID uint) ([]model.Club, error) {
 	return repository.GetUserClubs(userID)
 }
 
+func (s *ClubService) GetByID(id uint) (model.Club, error) {
+	return repository.GetClubByID(id)
+}
+
 func (s *ClubService) GetByChatID(chatID string) (model.Club, error) {
 	return repository.GetClubByChatID(chatID)
 }
+
+// CanManage reports whether the user (with the given platform role) may administer the club:
+// its creator, a club admin or a platform admin
+func (s *ClubService) CanManage(userID uint, role string, clubID uint) (bool, error) {
+	club, err := repository.GetClubByID(clubID)
+	if err != nil {
+		return false, err
+	}
+	if role == model.RoleAdmin || club.CreatorID == userID {
+		return true, nil
+	}
+	memberRole, err := repository.GetClubMemberRole(clubID, userID)
+	if err != nil {
+		return false, err
+	}
+	return memberRole == model.ClubRoleAdmin, nil
+}
+
+func (s *ClubService) authorize(actorID uint, role string, clubID uint) error {
+	ok, err := s.CanManage(actorID, role, clubID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrForbidden
+	}
+	return nil
+}
+
+type UpdateClubInput struct {
+	Name        *string   `json:"name"`
+	Description *string   `json:"description"`
+	Directions  *[]string `json:"directions"` // replaces all directions when present
+	Visibility  *string   `json:"visibility" enums:"open,request"`
+}
+
+// UpdateClub edits a non-archived club on behalf of its owner or an admin
+func (s *ClubService) UpdateClub(actorID uint, role string, clubID uint, input UpdateClubInput) (model.Club, error) {
+	if err := s.authorize(actorID, role, clubID); err != nil {
+		return model.Club{}, err
+	}
+	club, err := repository.GetClubByID(clubID)
+	if err != nil {
+		return model.Club{}, err
+	}
+	if club.ArchivedAt != nil {
+		return model.Club{}, ErrClubArchived
+	}
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return model.Club{}, errors.New("name must not be empty")
+		}
+		club.Name = name
+	}
+	if input.Description != nil {
+		club.Description = *input.Description
+	}
+	if input.Visibility != nil {
+		switch *input.Visibility {
+		case model.ClubVisibilityOpen, model.ClubVisibilityRequest:
+			club.Visibility = *input.Visibility
+		default:
+			return model.Club{}, errors.New("visibility must be open or request")
+		}
+	}
+	var dirs *[]model.Direction
+	if input.Directions != nil {
+		found, err := repository.FindOrCreateDirectionsByNames(*input.Directions)
+		if err != nil {
+			return model.Club{}, err
+		}
+		if found == nil {
+			found = []model.Direction{}
+		}
+		dirs = &found
+	}
+	if err := repository.UpdateClub(&club, dirs); err != nil {
+		return model.Club{}, err
+	}
+	return repository.GetClubByID(clubID)
+}
+
+// Archive makes the club read-only, hides it from listings and freezes its chat
+func (s *ClubService) Archive(actorID uint, role string, clubID uint) (model.Club, error) {
+	if err := s.authorize(actorID, role, clubID); err != nil {
+		return model.Club{}, err
+	}
+	now := time.Now()
+	if err := repository.SetClubArchived(clubID, &now); err != nil {
+		return model.Club{}, err
+	}
+	return repository.GetClubByID(clubID)
+}
+
+func (s *ClubService) Unarchive(actorID uint, role string, clubID uint) (model.Club, error) {
+	if err := s.authorize(actorID, role, clubID); err != nil {
+		return model.Club{}, err
+	}
+	if err := repository.SetClubArchived(clubID, nil); err != nil {
+		return model.Club{}, err
+	}
+	return repository.GetClubByID(clubID)
+}
+
+// Delete removes the club with its posts and subscriptions and returns it so the caller can drop the logo file
+func (s *ClubService) Delete(actorID uint, role string, clubID uint) (model.Club, error) {
+	if err := s.authorize(actorID, role, clubID); err != nil {
+		return model.Club{}, err
+	}
+	return repository.DeleteClub(clubID)
+}
+
+// IsChatFrozen reports whether the chat belongs to an archived club
+func (s *ClubService) IsChatFrozen(chatID string) bool {
+	club, err := repository.GetClubByChatID(chatID)
+	return err == nil && club.ArchivedAt != nil
+}
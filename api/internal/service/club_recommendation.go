@@ -0,0 +1,96 @@
+package service
+
+import (
+	"fmt"
+	"mosprom/api/internal/model"
+	"mosprom/api/internal/repository"
+	"strings"
+	"time"
+)
+
+// Weights of the club recommendation score components
+var clubRecommendationWeights = repository.ClubRecommendationWeights{
+	Direction: 0.35,
+	Tech:      0.25,
+	Overlap:   0.25,
+	Activity:  0.15,
+}
+
+// clubActivityWindow is how far back posts count towards a club's activity
+const clubActivityWindow = 30 * 24 * time.Hour
+
+type RecommendedClub struct {
+	Club           model.Club `json:"club"`
+	Score          float64    `json:"score"`
+	DirectionMatch float64    `json:"direction_match"`
+	TechMatch      float64    `json:"tech_match"`
+	MemberOverlap  float64    `json:"member_overlap"`
+	Activity       float64    `json:"activity"`
+	Reasons        []string   `json:"reasons"` // human-readable explanation of the score
+}
+
+// RecommendedForUser ranks clubs the user has not joined by direction and technology overlap,
+// shared members with the user's clubs and recent activity
+func (s *ClubService) RecommendedForUser(userID uint, limit int) ([]RecommendedClub, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	rows, err := repository.GetClubRecommendationsForUser(userID, clubRecommendationWeights, time.Now().Add(-clubActivityWindow), limit)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(rows))
+	for _, r := range rows {
+		ids = append(ids, r.ClubID)
+	}
+	m, err := repository.GetClubsByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]RecommendedClub, 0, len(rows))
+	for _, r := range rows {
+		club, ok := m[r.ClubID]
+		if !ok {
+			continue
+		}
+		out = append(out, RecommendedClub{
+			Club:           club,
+			Score:          r.Score,
+			DirectionMatch: r.DirectionMatch,
+			TechMatch:      r.TechMatch,
+			MemberOverlap:  r.MemberOverlap,
+			Activity:       r.Activity,
+			Reasons:        clubRecommendationReasons(r),
+		})
+	}
+	return out, nil
+}
+
+func clubRecommendationReasons(r repository.ClubRecommendationRow) []string {
+	var reasons []string
+	if r.CommonDirections != "" {
+		reasons = append(reasons, "Matches your directions: "+r.CommonDirections)
+	}
+	if r.CommonTechnologies != "" {
+		reasons = append(reasons, "Works with your technologies: "+firstNames(r.CommonTechnologies, 5))
+	}
+	if r.SharedMembers > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d members of your clubs are subscribed", r.SharedMembers))
+	}
+	if r.RecentPosts > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d new posts in the last 30 days", r.RecentPosts))
+	}
+	if len(reasons) == 0 {
+		reasons = append(reasons, "A club you have not explored yet")
+	}
+	return reasons
+}
+
+// firstNames shortens a comma-separated list to n names
+func firstNames(list string, n int) string {
+	names := strings.Split(list, ", ")
+	if len(names) <= n {
+		return list
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(names[:n], ", "), len(names)-n)
+}
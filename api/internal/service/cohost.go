@@ -0,0 +1,109 @@
+package service
+
+import (
+	"errors"
+	"mosprom/api/internal/model"
+	"mosprom/api/internal/repository"
+	"time"
+)
+
+var (
+	ErrAlreadyCoHost   = repository.ErrAlreadyCoHost
+	ErrCoHostResponded = repository.ErrCoHostResponded
+)
+
+// InviteCoHost asks another club to co-host a post of a club managed by the actor
+func (s *ClubService) InviteCoHost(actorID uint, role string, postID, clubID uint) (model.PostCoHost, error) {
+	post, err := repository.GetPostByID(postID)
+	if err != nil {
+		return model.PostCoHost{}, err
+	}
+	if err := s.authorize(actorID, role, post.ClubID); err != nil {
+		return model.PostCoHost{}, err
+	}
+	archived, err := repository.IsClubArchived(post.ClubID)
+	if err != nil {
+		return model.PostCoHost{}, err
+	}
+	if archived {
+		return model.PostCoHost{}, ErrClubArchived
+	}
+	return repository.InvitePostCoHost(postID, clubID, actorID)
+}
+
+// PostCoHosts lists co-host invitations of a post in any status for managers of the host club
+func (s *ClubService) PostCoHosts(actorID uint, role string, postID uint) ([]model.PostCoHost, error) {
+	post, err := repository.GetPostByID(postID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(actorID, role, post.ClubID); err != nil {
+		return nil, err
+	}
+	return repository.GetPostCoHosts(postID)
+}
+
+// PostWaitlist lists the post's waitlist in promotion order; managers of the host club only
+func (s *ClubService) PostWaitlist(actorID uint, role string, postID uint) ([]model.PostWaitlistEntry, error) {
+	post, err := repository.GetPostByID(postID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(actorID, role, post.ClubID); err != nil {
+		return nil, err
+	}
+	entries, err := repository.GetPostWaitlist(postID)
+	for i := range entries {
+		if entries[i].User != nil {
+			entries[i].User.Password = ""
+		}
+	}
+	return entries, err
+}
+
+// CoHostings lists co-host invitations addressed to the club
+func (s *ClubService) CoHostings(actorID uint, role string, clubID uint, status model.CoHostStatus) ([]model.PostCoHost, error) {
+	if err := s.authorize(actorID, role, clubID); err != nil {
+		return nil, err
+	}
+	switch status {
+	case "", model.CoHostPending, model.CoHostAccepted, model.CoHostDeclined:
+	default:
+		return nil, errors.New("status must be pending, accepted or declined")
+	}
+	return repository.GetClubCoHostings(clubID, status)
+}
+
+// RespondToCoHost accepts or declines on behalf of the invited club
+func (s *ClubService) RespondToCoHost(actorID uint, role string, clubID, postID uint, accept bool) (model.PostCoHost, error) {
+	if err := s.authorize(actorID, role, clubID); err != nil {
+		return model.PostCoHost{}, err
+	}
+	if accept {
+		archived, err := repository.IsClubArchived(clubID)
+		if err != nil {
+			return model.PostCoHost{}, err
+		}
+		if archived {
+			return model.PostCoHost{}, ErrClubArchived
+		}
+	}
+	return repository.RespondToPostCoHost(clubID, postID, accept, time.Now())
+}
+
+// RemoveCoHost withdraws an invitation or ends co-hosting; allowed to managers of the host and of the co-host club
+func (s *ClubService) RemoveCoHost(actorID uint, role string, postID, clubID uint) error {
+	post, err := repository.GetPostByID(postID)
+	if err != nil {
+		return err
+	}
+	if err := s.authorize(actorID, role, clubID); err != nil {
+		if !errors.Is(err, ErrForbidden) {
+			return err
+		}
+		if err := s.authorize(actorID, role, post.ClubID); err != nil {
+			return err
+		}
+	}
+	return repository.RemovePostCoHost(postID, clubID)
+}
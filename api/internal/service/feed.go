@@ -0,0 +1,145 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"mosprom/api/internal/repository"
+	"time"
+)
+
+// Weights of the feed score components
+var feedWeights = repository.FeedWeights{
+	Recency:    0.35,
+	Tech:       0.30,
+	Engagement: 0.20,
+	Subscribed: 0.15,
+}
+
+const (
+	feedFreshWindow    = 30 * 24 * time.Hour // subscribed clubs' posts shown
+	feedTrendingWindow = 7 * 24 * time.Hour  // likes and joins counted as engagement
+	feedRecencyHours   = 72.0
+	defaultFeedLimit   = 20
+	maxFeedLimit       = 50
+)
+
+// ErrInvalidCursor is returned for a feed cursor that was not issued by the server
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Feed item sources, reported in FeedItem.Reasons
+const (
+	FeedReasonSubscribed  = "subscribed"
+	FeedReasonRecommended = "recommended"
+	FeedReasonTrending    = "trending"
+)
+
+type FeedItem struct {
+	Post       PostDTO  `json:"post"`
+	Score      float64  `json:"score"`
+	Recency    float64  `json:"recency"`
+	TechMatch  float64  `json:"tech_match"`
+	Engagement int      `json:"engagement"` // likes + 2 * joins in the last 7 days
+	Reasons    []string `json:"reasons"`
+}
+
+type FeedPage struct {
+	Items      []FeedItem `json:"items"`
+	NextCursor string     `json:"next_cursor,omitempty"` // empty on the last page
+}
+
+// feedCursor pins the scroll session (AsOf) and the last item returned
+type feedCursor struct {
+	AsOf   int64   `json:"t"` // unix microseconds
+	Score  float64 `json:"s"`
+	PostID uint    `json:"p"`
+}
+
+func (c feedCursor) encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeFeedCursor(s string) (feedCursor, error) {
+	var c feedCursor
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || json.Unmarshal(raw, &c) != nil || c.AsOf <= 0 || c.PostID == 0 {
+		return feedCursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// Feed returns a page of the user's feed: new posts of subscribed and co-hosting clubs, posts matching the
+// user's technologies and trending posts, ranked by recency, technology match and engagement. The first page
+// fixes the ranking time; following pages (by cursor) are ranked as of that time so scrolling stays stable.
+func (s *PostService) Feed(userID uint, cursor string, limit int) (FeedPage, error) {
+	if limit <= 0 {
+		limit = defaultFeedLimit
+	}
+	limit = min(limit, maxFeedLimit)
+	cur := feedCursor{AsOf: time.Now().UnixMicro()}
+	if cursor != "" {
+		var err error
+		if cur, err = decodeFeedCursor(cursor); err != nil {
+			return FeedPage{}, err
+		}
+	}
+	asOf := time.UnixMicro(cur.AsOf)
+	rows, err := repository.GetFeedPage(repository.FeedQuery{
+		UserID:        userID,
+		AsOf:          asOf,
+		FreshSince:    asOf.Add(-feedFreshWindow),
+		TrendingSince: asOf.Add(-feedTrendingWindow),
+		RecencyHours:  feedRecencyHours,
+		Weights:       feedWeights,
+		AfterScore:    cur.Score,
+		AfterPostID:   cur.PostID,
+		Limit:         limit + 1,
+	})
+	if err != nil {
+		return FeedPage{}, err
+	}
+	page := FeedPage{Items: make([]FeedItem, 0, min(len(rows), limit))}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[len(rows)-1]
+		page.NextCursor = feedCursor{AsOf: cur.AsOf, Score: last.Score, PostID: last.PostID}.encode()
+	}
+	ids := make([]uint, 0, len(rows))
+	for _, r := range rows {
+		ids = append(ids, r.PostID)
+	}
+	byID, err := postDTOsByID(ids, userID)
+	if err != nil {
+		return FeedPage{}, err
+	}
+	for _, r := range rows {
+		p, ok := byID[r.PostID]
+		if !ok {
+			continue // deleted since the page was ranked
+		}
+		page.Items = append(page.Items, FeedItem{
+			Post:       p,
+			Score:      r.Score,
+			Recency:    r.Recency,
+			TechMatch:  r.TechMatch,
+			Engagement: r.Engagement,
+			Reasons:    feedReasons(r),
+		})
+	}
+	return page, nil
+}
+
+func feedReasons(r repository.FeedRow) []string {
+	var reasons []string
+	if r.Subscribed {
+		reasons = append(reasons, FeedReasonSubscribed)
+	}
+	if r.TechMatch > 0 {
+		reasons = append(reasons, FeedReasonRecommended)
+	}
+	if r.Engagement > 0 {
+		reasons = append(reasons, FeedReasonTrending)
+	}
+	return reasons
+}
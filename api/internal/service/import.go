@@ -0,0 +1,498 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mosprom/api/internal/ical"
+	"mosprom/api/internal/model"
+	"mosprom/api/internal/repository"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxImportRows      = 500
+	maxImportKeyLength = 300
+)
+
+// ErrImportInvalid is returned with the result of an import that has invalid rows; nothing is created
+var ErrImportInvalid = errors.New("import has invalid rows; nothing was imported")
+
+// Row statuses of an import
+const (
+	ImportRowValid     = "valid"     // dry run: would be created
+	ImportRowCreated   = "created"   // created by this import
+	ImportRowDuplicate = "duplicate" // imported before, or repeated in the file; left as is
+	ImportRowSkipped   = "skipped"   // cancelled in the source calendar
+	ImportRowInvalid   = "invalid"
+)
+
+// Fields of CreatePostInput a file column or calendar property can be mapped to. external_id
+// identifies a row across re-imports; without it the title and start date are used.
+var importFields = []string{
+	"title", "description", "type", "start_date", "end_date", "age_restriction", "format",
+	"address", "capacity", "registration_mode", "technologies", "external_id",
+}
+
+// Properties read from .ics files when the mapping does not name others
+var icsDefaultMapping = map[string]string{
+	"title":        "SUMMARY",
+	"description":  "DESCRIPTION",
+	"start_date":   "DTSTART",
+	"end_date":     "DTEND",
+	"address":      "LOCATION",
+	"technologies": "CATEGORIES",
+	"external_id":  "UID",
+}
+
+// Date layouts accepted in CSV cells, tried in order; values without a zone are read in the import's time zone
+var importTimeLayouts = []string{
+	time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02",
+	"02.01.2006 15:04", "02.01.2006", "01/02/2006 15:04", "01/02/2006",
+}
+
+// ImportPostsInput is an uploaded file and how to read it
+type ImportPostsInput struct {
+	Filename string
+	Data     []byte
+	// Mapping maps post fields (see importFields) to CSV column headers or iCalendar property names.
+	// Unmapped CSV fields are read from a column named like the field; .ics files default to icsDefaultMapping.
+	Mapping     map[string]string
+	DefaultType model.PostType // type of rows without one; activity by default
+	TimeZone    string         // IANA zone of times without an offset; UTC by default
+	DryRun      bool
+}
+
+// ImportRow is the outcome of one CSV row or calendar event
+type ImportRow struct {
+	Row          int            `json:"row"` // 1-based, header excluded
+	Status       string         `json:"status" enums:"valid,created,duplicate,skipped,invalid"`
+	Title        string         `json:"title"`
+	Type         model.PostType `json:"type,omitempty"`
+	StartDate    *time.Time     `json:"start_date,omitempty"`
+	EndDate      *time.Time     `json:"end_date,omitempty"`
+	Technologies []string       `json:"technologies,omitempty"`
+	PostID       uint           `json:"post_id,omitempty"` // created post, or the earlier import of a duplicate
+	Errors       []string       `json:"errors,omitempty"`
+}
+
+// ImportResult summarizes an import or its dry-run preview
+type ImportResult struct {
+	DryRun     bool        `json:"dry_run"`
+	Total      int         `json:"total"`
+	Valid      int         `json:"valid"` // created, or would be created by a dry run
+	Duplicates int         `json:"duplicates"`
+	Skipped    int         `json:"skipped"`
+	Invalid    int         `json:"invalid"`
+	Rows       []ImportRow `json:"rows"`
+}
+
+// importRecord is a CSV row or calendar event keyed by lower-case column or property name
+type importRecord map[string]string
+
+// ImportPosts creates posts of a club from an .ics or CSV file on behalf of one of its managers.
+// All valid rows are created in one transaction, and only when no row is invalid; rows imported
+// before are recognized by their import key and left unchanged.
+func (s *PostService) ImportPosts(actorID uint, role string, clubID uint, input ImportPostsInput) (ImportResult, error) {
+	if err := s.clubs.authorize(actorID, role, clubID); err != nil {
+		return ImportResult{}, err
+	}
+	archived, err := repository.IsClubArchived(clubID)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	if archived {
+		return ImportResult{}, ErrClubArchived
+	}
+	if input.DefaultType == "" {
+		input.DefaultType = model.Activity
+	}
+	if !isPostType(input.DefaultType) {
+		return ImportResult{}, errors.New("invalid default post type")
+	}
+	loc := time.UTC
+	if input.TimeZone != "" {
+		if loc, err = time.LoadLocation(input.TimeZone); err != nil {
+			return ImportResult{}, fmt.Errorf("unknown time zone %q", input.TimeZone)
+		}
+	}
+
+	for field := range input.Mapping {
+		if !slices.Contains(importFields, field) {
+			return ImportResult{}, fmt.Errorf("unknown field %q in mapping", field)
+		}
+	}
+
+	var (
+		records []importRecord
+		mapping map[string]string
+		source  string
+	)
+	if isICalendar(input.Filename, input.Data) {
+		source = "ics"
+		if records, err = icsRecords(input.Data, loc); err != nil {
+			return ImportResult{}, err
+		}
+		mapping = importMapping(icsDefaultMapping, input.Mapping)
+	} else {
+		source = "csv"
+		var header []string
+		if header, records, err = csvRecords(input.Data); err != nil {
+			return ImportResult{}, err
+		}
+		defaults := map[string]string{}
+		for _, f := range importFields {
+			defaults[f] = f
+		}
+		mapping = importMapping(defaults, input.Mapping)
+		for field, col := range input.Mapping {
+			if !slices.Contains(header, normalizeImportName(col)) {
+				return ImportResult{}, fmt.Errorf("mapping of %s: column %q not found", field, col)
+			}
+		}
+	}
+	if len(records) == 0 {
+		return ImportResult{}, errors.New("file has no rows")
+	}
+	if len(records) > maxImportRows {
+		return ImportResult{}, fmt.Errorf("at most %d rows per import", maxImportRows)
+	}
+
+	result := ImportResult{DryRun: input.DryRun, Total: len(records), Rows: make([]ImportRow, len(records))}
+	items := make([]repository.ImportedPost, len(records))
+	keys := make([]string, 0, len(records))
+	for i, rec := range records {
+		row, post, techs := importRow(rec, mapping, input.DefaultType, loc)
+		row.Row = i + 1
+		if row.Status != ImportRowInvalid && row.Status != ImportRowSkipped {
+			key := importKey(source, rec, mapping, post)
+			post.ImportKey = &key
+			post.ClubID = clubID
+			keys = append(keys, key)
+		}
+		result.Rows[i] = row
+		items[i] = repository.ImportedPost{Post: post, Technologies: techs}
+	}
+
+	existing, err := repository.GetImportedPostIDs(clubID, keys)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	seen := map[string]bool{}
+	var toCreate []int
+	for i := range result.Rows {
+		row := &result.Rows[i]
+		if row.Status == ImportRowValid {
+			key := *items[i].Post.ImportKey
+			if id, ok := existing[key]; ok {
+				row.Status, row.PostID = ImportRowDuplicate, id
+			} else if seen[key] {
+				row.Status = ImportRowDuplicate
+			} else {
+				seen[key] = true
+				toCreate = append(toCreate, i)
+			}
+		}
+		switch row.Status {
+		case ImportRowValid:
+			result.Valid++
+		case ImportRowDuplicate:
+			result.Duplicates++
+		case ImportRowSkipped:
+			result.Skipped++
+		case ImportRowInvalid:
+			result.Invalid++
+		}
+	}
+	if input.DryRun {
+		return result, nil
+	}
+	if result.Invalid > 0 {
+		return result, ErrImportInvalid
+	}
+
+	batch := make([]repository.ImportedPost, 0, len(toCreate))
+	for _, i := range toCreate {
+		batch = append(batch, items[i])
+	}
+	if err := repository.ImportPosts(batch); err != nil {
+		return ImportResult{}, err
+	}
+	for n, i := range toCreate {
+		row := &result.Rows[i]
+		post := batch[n].Post
+		if post.ID == 0 { // imported concurrently by another request
+			row.Status = ImportRowDuplicate
+			result.Valid--
+			result.Duplicates++
+			continue
+		}
+		row.Status, row.PostID = ImportRowCreated, post.ID
+		if err := s.reminders.Schedule(post); err != nil {
+			log.Printf("schedule reminders for post %d: %v", post.ID, err)
+		}
+	}
+	return result, nil
+}
+
+// importRow validates a record and converts it to a post without club and import key
+func importRow(rec importRecord, mapping map[string]string, defaultType model.PostType, loc *time.Location) (ImportRow, model.Post, []string) {
+	get := func(field string) string {
+		return strings.TrimSpace(rec[mapping[field]])
+	}
+	row := ImportRow{Status: ImportRowValid, Title: get("title")}
+	post := model.Post{Title: row.Title, Description: get("description"), Address: get("address"), RegistrationMode: model.RegistrationOpen}
+	fail := func(format string, args ...any) {
+		row.Status = ImportRowInvalid
+		row.Errors = append(row.Errors, fmt.Sprintf(format, args...))
+	}
+
+	if rec[icsCancelledKey] != "" {
+		row.Status = ImportRowSkipped
+		row.Errors = []string{"event is cancelled"}
+		return row, post, nil
+	}
+	if row.Title == "" {
+		fail("title is required")
+	}
+	post.Type = defaultType
+	if v := get("type"); v != "" {
+		post.Type = model.PostType(strings.ToLower(v))
+		if !isPostType(post.Type) {
+			fail("invalid type %q", v)
+		}
+	}
+	row.Type = post.Type
+	if v := get("start_date"); v != "" {
+		if t, err := parseImportTime(v, loc); err != nil {
+			fail("invalid start_date %q", v)
+		} else {
+			post.StartDate = &t
+		}
+	}
+	if v := get("end_date"); v != "" {
+		if t, err := parseImportTime(v, loc); err != nil {
+			fail("invalid end_date %q", v)
+		} else {
+			post.EndDate = &t
+		}
+	}
+	if post.EndDate != nil && (post.StartDate == nil || post.EndDate.Before(*post.StartDate)) {
+		fail("end_date must not be before start_date")
+	}
+	row.StartDate, row.EndDate = post.StartDate, post.EndDate
+	if v := get("age_restriction"); v != "" {
+		if n, err := strconv.Atoi(v); err != nil || n < 0 || n > 100 {
+			fail("invalid age_restriction %q", v)
+		} else if n > 0 {
+			post.AgeRestriction = &n
+		}
+	}
+	if v := get("format"); v != "" {
+		f := model.PostFormat(strings.ToLower(v))
+		if f != model.InPerson && f != model.Online {
+			fail("invalid format %q", v)
+		} else {
+			post.Format = &f
+		}
+	}
+	if v := get("capacity"); v != "" {
+		if n, err := strconv.Atoi(v); err != nil || n <= 0 {
+			fail("invalid capacity %q", v)
+		} else {
+			post.Capacity = &n
+		}
+	}
+	if v := get("registration_mode"); v != "" {
+		post.RegistrationMode = model.RegistrationMode(strings.ToLower(v))
+		if !isRegistrationMode(post.RegistrationMode) {
+			fail("invalid registration_mode %q", v)
+		}
+	}
+	techs := splitImportList(get("technologies"))
+	row.Technologies = techs
+	return row, post, techs
+}
+
+// importKey identifies a row across imports: its external id, or its title and start date
+func importKey(source string, rec importRecord, mapping map[string]string, post model.Post) string {
+	key := strings.TrimSpace(rec[mapping["external_id"]])
+	if key == "" {
+		start := ""
+		if post.StartDate != nil {
+			start = post.StartDate.UTC().Format(time.RFC3339)
+		}
+		sum := sha256.Sum256([]byte(strings.ToLower(post.Title) + "\x00" + start))
+		return source + ":sum:" + hex.EncodeToString(sum[:16])
+	}
+	key = source + ":id:" + key
+	if len(key) > maxImportKeyLength {
+		sum := sha256.Sum256([]byte(key))
+		key = source + ":idsum:" + hex.EncodeToString(sum[:16])
+	}
+	return key
+}
+
+// importMapping overlays the requested mapping on the defaults, normalizing source names
+func importMapping(defaults, requested map[string]string) map[string]string {
+	out := make(map[string]string, len(importFields))
+	for f, src := range defaults {
+		out[f] = normalizeImportName(src)
+	}
+	for f, src := range requested {
+		out[f] = normalizeImportName(src)
+	}
+	return out
+}
+
+func normalizeImportName(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// icsCancelledKey marks records of cancelled events; it cannot collide with a property name
+const icsCancelledKey = " cancelled"
+
+// icsRecords reads the events of a calendar. Dates become RFC 3339 (YYYY-MM-DD for all-day ones),
+// DURATION fills a missing DTEND, and RECURRENCE-ID is appended to UID so that changed
+// occurrences of a series are imported separately.
+func icsRecords(data []byte, loc *time.Location) ([]importRecord, error) {
+	events, err := ical.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	records := make([]importRecord, 0, len(events))
+	for _, e := range events {
+		rec := importRecord{}
+		for _, p := range e.Props {
+			name := strings.ToLower(p.Name)
+			if _, ok := rec[name]; ok {
+				continue
+			}
+			rec[name] = e.Text(p.Name)
+		}
+		for _, name := range []string{"DTSTART", "DTEND", "RECURRENCE-ID"} {
+			p := e.Get(name)
+			if p == nil {
+				continue
+			}
+			t, allDay, err := p.Time(loc)
+			if err != nil {
+				rec[strings.ToLower(name)] = p.Value // reported as invalid by importRow
+				continue
+			}
+			if allDay && name != "RECURRENCE-ID" {
+				rec[strings.ToLower(name)] = t.Format("2006-01-02")
+			} else {
+				rec[strings.ToLower(name)] = t.Format(time.RFC3339)
+			}
+		}
+		if rec["dtend"] == "" && rec["dtstart"] != "" && rec["duration"] != "" {
+			if start, err := parseImportTime(rec["dtstart"], loc); err == nil {
+				if d, err := ical.ParseDuration(rec["duration"]); err == nil {
+					rec["dtend"] = start.Add(d).Format(time.RFC3339)
+				}
+			}
+		}
+		if rid := rec["recurrence-id"]; rid != "" && rec["uid"] != "" {
+			rec["uid"] += "#" + rid
+		}
+		if strings.EqualFold(rec["status"], ical.StatusCancelled) {
+			rec[icsCancelledKey] = "1"
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// csvRecords reads a CSV file with a header row; the delimiter may be a comma, a semicolon
+// (spreadsheet exports in many locales) or a tab
+func csvRecords(data []byte) ([]string, []importRecord, error) {
+	data = bytes.TrimPrefix(data, []byte("\uFEFF"))
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = ','
+	for _, d := range []rune{';', '\t'} {
+		if bytes.Count(firstLine, []byte(string(d))) > bytes.Count(firstLine, []byte(string(r.Comma))) {
+			r.Comma = d
+		}
+	}
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, nil, errors.New("file is empty")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	for i := range header {
+		header[i] = normalizeImportName(header[i])
+	}
+	var records []importRecord
+	for {
+		cells, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		if len(cells) == 1 && strings.TrimSpace(cells[0]) == "" {
+			continue // blank line
+		}
+		rec := importRecord{}
+		for i, v := range cells {
+			if i < len(header) && header[i] != "" {
+				rec[header[i]] = v
+			}
+		}
+		records = append(records, rec)
+		if len(records) > maxImportRows {
+			break
+		}
+	}
+	return header, records, nil
+}
+
+func isICalendar(filename string, data []byte) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".ics", ".ical", ".ifb":
+		return true
+	case ".csv", ".tsv", ".txt":
+		return false
+	}
+	head := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\uFEFF")))
+	return bytes.HasPrefix(bytes.ToUpper(head[:min(len(head), 15)]), []byte("BEGIN:VCALENDAR"))
+}
+
+func parseImportTime(v string, loc *time.Location) (time.Time, error) {
+	for _, layout := range importTimeLayouts {
+		if t, err := time.ParseInLocation(layout, v, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", v)
+}
+
+// splitImportList splits a technologies cell on commas, semicolons or pipes, dropping blanks and repeats
+func splitImportList(v string) []string {
+	var out []string
+	for _, s := range strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ';' || r == '|' }) {
+		s = strings.TrimSpace(s)
+		if s != "" && !slices.Contains(out, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"mosprom/api/internal/model"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCSVRecords(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		data    string
+		header  []string
+		records []importRecord
+	}{
+		{
+			name:    "comma",
+			data:    "Title,Start_Date\nMeetup,2026-01-05 18:00\n",
+			header:  []string{"title", "start_date"},
+			records: []importRecord{{"title": "Meetup", "start_date": "2026-01-05 18:00"}},
+		},
+		{
+			name:    "semicolon with a byte order mark and CRLF",
+			data:    "\uFEFF Title ;Technologies\r\nMeetup;\"Go, Docker\"\r\n",
+			header:  []string{"title", "technologies"},
+			records: []importRecord{{"title": "Meetup", "technologies": "Go, Docker"}},
+		},
+		{
+			name:    "tab",
+			data:    "title\tdescription\nMeetup\tTalks, pizza; drinks\n",
+			header:  []string{"title", "description"},
+			records: []importRecord{{"title": "Meetup", "description": "Talks, pizza; drinks"}},
+		},
+		{
+			name:   "blank lines, short rows and cells without a header",
+			data:   "title,,type\nFirst,x,activity,extra\n\nSecond\n",
+			header: []string{"title", "", "type"},
+			records: []importRecord{
+				{"title": "First", "type": "activity"},
+				{"title": "Second"},
+			},
+		},
+		{
+			name:   "header only",
+			data:   "title,type\n",
+			header: []string{"title", "type"},
+		},
+	} {
+		header, records, err := csvRecords([]byte(tc.data))
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(header, tc.header) {
+			t.Errorf("%s: header = %q, want %q", tc.name, header, tc.header)
+		}
+		if !reflect.DeepEqual(records, tc.records) {
+			t.Errorf("%s: records = %v, want %v", tc.name, records, tc.records)
+		}
+	}
+}
+
+func TestCSVRecordsEmpty(t *testing.T) {
+	for _, data := range []string{"", "\uFEFF"} {
+		if _, _, err := csvRecords([]byte(data)); err == nil {
+			t.Errorf("csvRecords(%q) succeeded, want an error", data)
+		}
+	}
+}
+
+func TestCSVRecordsStopsAfterMaxRows(t *testing.T) {
+	data := "title\n" + strings.Repeat("Meetup\n", maxImportRows+10)
+	_, records, err := csvRecords([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != maxImportRows+1 {
+		t.Errorf("read %d records, want %d to report the limit", len(records), maxImportRows+1)
+	}
+}
+
+// csvMapping maps every field to the column named like it
+func csvMapping() map[string]string {
+	defaults := map[string]string{}
+	for _, f := range importFields {
+		defaults[f] = f
+	}
+	return importMapping(defaults, nil)
+}
+
+func TestImportRow(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	start := time.Date(2026, 1, 5, 18, 0, 0, 0, msk)
+	end := time.Date(2026, 1, 5, 20, 0, 0, 0, msk)
+	for _, tc := range []struct {
+		name   string
+		rec    importRecord
+		status string
+		errors int
+		check  func(ImportRow, model.Post) bool
+	}{
+		{
+			name: "full row",
+			rec: importRecord{
+				"title": " Go meetup ", "description": "Talks", "type": "Educational",
+				"start_date": "2026-01-05 18:00", "end_date": "05.01.2026 20:00", "age_restriction": "16",
+				"format": "IN_PERSON", "address": "Room 1", "capacity": "30", "registration_mode": "approval",
+				"technologies": "Go; Docker|Go",
+			},
+			status: ImportRowValid,
+			check: func(row ImportRow, p model.Post) bool {
+				return row.Title == "Go meetup" && p.Type == model.Educational && row.Type == model.Educational &&
+					p.StartDate.Equal(start) && p.EndDate.Equal(end) && *p.AgeRestriction == 16 &&
+					*p.Format == model.InPerson && p.Address == "Room 1" && *p.Capacity == 30 &&
+					p.RegistrationMode == model.RegistrationApproval &&
+					reflect.DeepEqual(row.Technologies, []string{"Go", "Docker"})
+			},
+		},
+		{
+			name:   "defaults",
+			rec:    importRecord{"title": "Meetup"},
+			status: ImportRowValid,
+			check: func(row ImportRow, p model.Post) bool {
+				return p.Type == model.Activity && p.RegistrationMode == model.RegistrationOpen &&
+					p.StartDate == nil && p.AgeRestriction == nil && p.Capacity == nil && row.Technologies == nil
+			},
+		},
+		{
+			name:   "zero age restriction means none",
+			rec:    importRecord{"title": "Meetup", "age_restriction": "0"},
+			status: ImportRowValid,
+			check:  func(_ ImportRow, p model.Post) bool { return p.AgeRestriction == nil },
+		},
+		{
+			name:   "time with an offset keeps it",
+			rec:    importRecord{"title": "Meetup", "start_date": "2026-01-05T15:00:00Z"},
+			status: ImportRowValid,
+			check:  func(_ ImportRow, p model.Post) bool { return p.StartDate.Equal(start) },
+		},
+		{
+			name:   "cancelled",
+			rec:    importRecord{"title": "Meetup", icsCancelledKey: "1"},
+			status: ImportRowSkipped,
+			errors: 1,
+		},
+		{name: "missing title", rec: importRecord{"title": "  "}, status: ImportRowInvalid, errors: 1},
+		{name: "invalid type", rec: importRecord{"title": "x", "type": "party"}, status: ImportRowInvalid, errors: 1},
+		{name: "invalid start", rec: importRecord{"title": "x", "start_date": "tomorrow"}, status: ImportRowInvalid, errors: 1},
+		{name: "end without start", rec: importRecord{"title": "x", "end_date": "2026-01-05"}, status: ImportRowInvalid, errors: 1},
+		{
+			name:   "end before start",
+			rec:    importRecord{"title": "x", "start_date": "2026-01-05 18:00", "end_date": "2026-01-05 17:00"},
+			status: ImportRowInvalid,
+			errors: 1,
+		},
+		{name: "invalid age", rec: importRecord{"title": "x", "age_restriction": "101"}, status: ImportRowInvalid, errors: 1},
+		{name: "invalid format", rec: importRecord{"title": "x", "format": "hybrid"}, status: ImportRowInvalid, errors: 1},
+		{name: "invalid capacity", rec: importRecord{"title": "x", "capacity": "0"}, status: ImportRowInvalid, errors: 1},
+		{name: "invalid registration mode", rec: importRecord{"title": "x", "registration_mode": "invite"}, status: ImportRowInvalid, errors: 1},
+		{
+			name:   "every error is reported",
+			rec:    importRecord{"type": "party", "start_date": "x", "capacity": "-1"},
+			status: ImportRowInvalid,
+			errors: 4,
+		},
+	} {
+		row, post, _ := importRow(tc.rec, csvMapping(), model.Activity, msk)
+		if row.Status != tc.status || len(row.Errors) != tc.errors {
+			t.Errorf("%s: status %s, errors %q; want %s with %d errors", tc.name, row.Status, row.Errors, tc.status, tc.errors)
+			continue
+		}
+		if tc.check != nil && !tc.check(row, post) {
+			t.Errorf("%s: unexpected row %+v, post %+v", tc.name, row, post)
+		}
+	}
+}
+
+func TestImportKey(t *testing.T) {
+	mapping := csvMapping()
+	start := time.Date(2026, 1, 5, 18, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+	sameInstant := start.UTC()
+	later := start.Add(time.Hour)
+	post := func(title string, start *time.Time) model.Post {
+		return model.Post{Title: title, StartDate: start}
+	}
+
+	if got := importKey("csv", importRecord{"external_id": " 42 "}, mapping, post("Meetup", &start)); got != "csv:id:42" {
+		t.Errorf("external id key = %q, want csv:id:42", got)
+	}
+	if a, b := importKey("csv", importRecord{"external_id": "42"}, mapping, post("Meetup", &start)),
+		importKey("ics", importRecord{"external_id": "42"}, mapping, post("Meetup", &start)); a == b {
+		t.Errorf("keys of different sources are equal: %q", a)
+	}
+
+	long := strings.Repeat("x", maxImportKeyLength)
+	longKey := importKey("ics", importRecord{"external_id": long}, mapping, post("Meetup", nil))
+	if len(longKey) > maxImportKeyLength || !strings.HasPrefix(longKey, "ics:idsum:") {
+		t.Errorf("long external id key = %q, want a hashed key of at most %d bytes", longKey, maxImportKeyLength)
+	}
+	if other := importKey("ics", importRecord{"external_id": long + "y"}, mapping, post("Meetup", nil)); other == longKey {
+		t.Error("different long external ids share a key")
+	}
+
+	sum := importKey("csv", importRecord{}, mapping, post("Go Meetup", &start))
+	if !strings.HasPrefix(sum, "csv:sum:") {
+		t.Errorf("key without external id = %q, want a csv:sum: key", sum)
+	}
+	for _, tc := range []struct {
+		name  string
+		post  model.Post
+		equal bool
+	}{
+		{"title case and zone do not matter", post("go meetup", &sameInstant), true},
+		{"another start", post("Go Meetup", &later), false},
+		{"no start", post("Go Meetup", nil), false},
+		{"another title", post("Go Meetup 2", &start), false},
+	} {
+		if got := importKey("csv", importRecord{"external_id": " "}, mapping, tc.post); (got == sum) != tc.equal {
+			t.Errorf("%s: key %q, first key %q, want equal %v", tc.name, got, sum, tc.equal)
+		}
+	}
+}
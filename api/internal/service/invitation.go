@@ -0,0 +1,109 @@
+package service
+
+import (
+	"errors"
+	"mosprom/api/internal/model"
+	"mosprom/api/internal/repository"
+	"strings"
+)
+
+// ErrInvitationResponded is returned when answering an invitation twice
+var ErrInvitationResponded = repository.ErrInvitationResponded
+
+const maxInviteesPerRequest = 100
+
+type InviteUsersInput struct {
+	UserIDs []uint `json:"user_ids" binding:"required"`
+	PostID  *uint  `json:"post_id"` // invite to this club post instead of the club
+	Message string `json:"message"`
+}
+
+type SkippedInvitee struct {
+	UserID uint   `json:"user_id"`
+	Reason string `json:"reason" enums:"unknown_user,already_invited,already_member,banned"`
+}
+
+type InviteUsersResult struct {
+	Invited []model.Invitation `json:"invited"`
+	Skipped []SkippedInvitee   `json:"skipped"`
+}
+
+// InviteUsers invites users (e.g. picked from recommended_users) to the club or to one of its posts
+func (s *ClubService) InviteUsers(actorID uint, role string, clubID uint, input InviteUsersInput) (InviteUsersResult, error) {
+	if err := s.authorize(actorID, role, clubID); err != nil {
+		return InviteUsersResult{}, err
+	}
+	if len(input.UserIDs) == 0 {
+		return InviteUsersResult{}, errors.New("user_ids must not be empty")
+	}
+	if len(input.UserIDs) > maxInviteesPerRequest {
+		return InviteUsersResult{}, errors.New("too many user_ids")
+	}
+	archived, err := repository.IsClubArchived(clubID)
+	if err != nil {
+		return InviteUsersResult{}, err
+	}
+	if archived {
+		return InviteUsersResult{}, ErrClubArchived
+	}
+	if input.PostID != nil {
+		post, err := repository.GetPostByID(*input.PostID)
+		if err != nil {
+			return InviteUsersResult{}, err
+		}
+		if post.ClubID != clubID {
+			return InviteUsersResult{}, errors.New("post does not belong to the club")
+		}
+	}
+	tmpl := model.Invitation{
+		ClubID:      clubID,
+		PostID:      input.PostID,
+		InvitedByID: actorID,
+		Message:     strings.TrimSpace(input.Message),
+	}
+	created, skipped, err := repository.CreateInvitations(tmpl, input.UserIDs)
+	if err != nil {
+		return InviteUsersResult{}, err
+	}
+	res := InviteUsersResult{Invited: created, Skipped: make([]SkippedInvitee, 0, len(skipped))}
+	for _, uid := range input.UserIDs {
+		if reason, ok := skipped[uid]; ok {
+			res.Skipped = append(res.Skipped, SkippedInvitee{UserID: uid, Reason: reason})
+			delete(skipped, uid)
+		}
+	}
+	return res, nil
+}
+
+func (s *ClubService) Invitations(actorID uint, role string, clubID uint, postID *uint, status string) ([]model.Invitation, error) {
+	if err := s.authorize(actorID, role, clubID); err != nil {
+		return nil, err
+	}
+	st, err := parseInvitationStatus(status)
+	if err != nil {
+		return nil, err
+	}
+	return repository.GetClubInvitations(clubID, postID, st)
+}
+
+func (s *ClubService) MyInvitations(userID uint, status string) ([]model.Invitation, error) {
+	st, err := parseInvitationStatus(status)
+	if err != nil {
+		return nil, err
+	}
+	return repository.GetUserInvitations(userID, st)
+}
+
+// RespondToInvitation accepts (joining the post or club) or declines the user's invitation
+func (s *ClubService) RespondToInvitation(userID, invitationID uint, accept bool) (model.Invitation, error) {
+	return repository.RespondToInvitation(userID, invitationID, accept)
+}
+
+func parseInvitationStatus(status string) (model.InvitationStatus, error) {
+	switch st := model.InvitationStatus(status); st {
+	case "", model.InvitationPending, model.InvitationAccepted, model.InvitationDeclined:
+		return st, nil
+	default:
+		return "", errors.New("invalid status")
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"mosprom/api/internal/model"
+	"mosprom/api/internal/repository"
+	"strings"
+	"time"
+)
+
+// ErrInviteUnusable is returned when redeeming a revoked, expired or used-up invite
+var ErrInviteUnusable = repository.ErrInviteUnusable
+
+type CreateInviteInput struct {
+	Role      string     `json:"role" enums:"member,admin"` // default member
+	MaxUses   *int       `json:"max_uses"`                  // omit for unlimited
+	ExpiresAt *time.Time `json:"expires_at"`                // omit for no expiry
+}
+
+// CreateInvite issues an invite token for the club. Only the owner or a platform admin may hand out the admin role.
+func (s *ClubService) CreateInvite(actorID uint, role string, clubID uint, input CreateInviteInput) (model.ClubInvite, error) {
+	if err := s.authorize(actorID, role, clubID); err != nil {
+		return model.ClubInvite{}, err
+	}
+	club, err := repository.GetClubByID(clubID)
+	if err != nil {
+		return model.ClubInvite{}, err
+	}
+	if club.ArchivedAt != nil {
+		return model.ClubInvite{}, ErrClubArchived
+	}
+	inviteRole := strings.TrimSpace(input.Role)
+	switch inviteRole {
+	case "", model.ClubRoleMember:
+		inviteRole = model.ClubRoleMember
+	case model.ClubRoleAdmin:
+		if role != model.RoleAdmin && club.CreatorID != actorID {
+			return model.ClubInvite{}, ErrForbidden
+		}
+	default:
+		return model.ClubInvite{}, errors.New("role must be member or admin")
+	}
+	if input.MaxUses != nil && *input.MaxUses < 1 {
+		return model.ClubInvite{}, errors.New("max_uses must be positive")
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return model.ClubInvite{}, errors.New("expires_at must be in the future")
+	}
+	token, err := newInviteToken()
+	if err != nil {
+		return model.ClubInvite{}, err
+	}
+	invite := model.ClubInvite{
+		ClubID:      clubID,
+		Token:       token,
+		Role:        inviteRole,
+		MaxUses:     input.MaxUses,
+		ExpiresAt:   input.ExpiresAt,
+		CreatedByID: actorID,
+	}
+	if err := repository.CreateClubInvite(&invite); err != nil {
+		return model.ClubInvite{}, err
+	}
+	return invite, nil
+}
+
+func (s *ClubService) Invites(actorID uint, role string, clubID uint) ([]model.ClubInvite, error) {
+	if err := s.authorize(actorID, role, clubID); err != nil {
+		return nil, err
+	}
+	return repository.GetClubInvites(clubID)
+}
+
+func (s *ClubService) RevokeInvite(actorID uint, role string, clubID, inviteID uint) error {
+	if err := s.authorize(actorID, role, clubID); err != nil {
+		return err
+	}
+	return repository.RevokeClubInvite(clubID, inviteID)
+}
+
+// RedeemInvite subscribes the user to the invite's club, also for request-to-join clubs
+func (s *ClubService) RedeemInvite(userID uint, token string) (model.ClubInvite, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return model.ClubInvite{}, ErrInviteUnusable
+	}
+	return repository.RedeemClubInvite(token, userID, time.Now())
+}
+
+// newInviteToken returns a URL-safe random token suitable for share links
+func newInviteToken() (string, error) {
+	b := make([]byte, 18)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"mosprom/api/internal/model"
+	"mosprom/api/internal/repository"
+)
+
+// ErrJobRunning is returned by Requeue for a job a worker currently holds
+var ErrJobRunning = repository.ErrJobRunning
+
+// JobService exposes the background job queue to admins
+type JobService struct{}
+
+func NewJobService() *JobService { return &JobService{} }
+
+func (s *JobService) List(status, jobType string, limit int) ([]model.Job, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return repository.ListJobs(status, jobType, limit)
+}
+
+func (s *JobService) Get(id uint) (model.Job, error) {
+	return repository.GetJobByID(id)
+}
+
+func (s *JobService) Requeue(id uint) (model.Job, error) {
+	return repository.RequeueJob(id)
+}
+
+func (s *JobService) Recurring() ([]model.RecurringJob, error) {
+	return repository.ListRecurringJobs()
+}
@@ -12,6 +12,9 @@ func NewLikeService() *LikeService {
 }
 
 func (s *LikeService) LikePost(userID, postID uint) error {
+	if err := checkPostClubActive(postID); err != nil {
+		return err
+	}
 	_, err := repository.GetLikeByUserAndPost(userID, postID)
 	if err == nil {
 		return nil
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"errors"
+	"mosprom/api/internal/model"
+	"mosprom/api/internal/repository"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrBannedFromClub     = repository.ErrBannedFromClub
+	ErrJoinRequestPending = repository.ErrJoinRequestPending
+	ErrJoinRequestDecided = repository.ErrJoinRequestDecided
+	ErrAlreadyMember      = repository.ErrAlreadyMember
+)
+
+// Subscribe joins an open club directly; for request-to-join clubs it files a join request and returns it.
+// A nil request means the user is subscribed.
+func (s *ClubService) Subscribe(userID, clubID uint, message string) (*model.ClubJoinRequest, error) {
+	err := repository.SubscribeUserToClub(userID, clubID)
+	if !errors.Is(err, repository.ErrJoinRequestRequired) {
+		return nil, err
+	}
+	req := model.ClubJoinRequest{ClubID: clubID, UserID: userID, Message: strings.TrimSpace(message)}
+	if err := repository.CreateClubJoinRequest(&req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+func (s *ClubService) Unsubscribe(userID, clubID uint) error {
+	return repository.UnsubscribeUserFromClub(userID, clubID)
+}
+
+// checkModerationTarget prevents moderators from removing themselves or the club owner.
+// Club admins may only be removed by the owner or a platform admin.
+func (s *ClubService) checkModerationTarget(actorID uint, role string, clubID, userID uint) error {
+	if actorID == userID {
+		return errors.New("cannot apply to yourself")
+	}
+	club, err := repository.GetClubByID(clubID)
+	if err != nil {
+		return err
+	}
+	if club.CreatorID == userID {
+		return ErrForbidden
+	}
+	if role == model.RoleAdmin || club.CreatorID == actorID {
+		return nil
+	}
+	targetRole, err := repository.GetClubMemberRole(clubID, userID)
+	if err != nil {
+		return err
+	}
+	if targetRole == model.ClubRoleAdmin {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// Kick removes a member from the club; the user may subscribe again (or request to) later
+func (s *ClubService) Kick(actorID uint, role string, clubID, userID uint, reason string) error {
+	if err := s.authorize(actorID, role, clubID); err != nil {
+		return err
+	}
+	if err := s.checkModerationTarget(actorID, role, clubID, userID); err != nil {
+		return err
+	}
+	return repository.KickClubMember(clubID, userID, strings.TrimSpace(reason))
+}
+
+// Ban removes the user from the club and keeps them out of subscriptions, join requests and the chat
+func (s *ClubService) Ban(actorID uint, role string, clubID, userID uint, reason string) (model.ClubBan, error) {
+	if err := s.authorize(actorID, role, clubID); err != nil {
+		return model.ClubBan{}, err
+	}
+	if err := s.checkModerationTarget(actorID, role, clubID, userID); err != nil {
+		return model.ClubBan{}, err
+	}
+	ban := model.ClubBan{ClubID: clubID, UserID: userID, Reason: strings.TrimSpace(reason), BannedByID: actorID}
+	if err := repository.BanClubMember(&ban); err != nil {
+		return model.ClubBan{}, err
+	}
+	return ban, nil
+}
+
+func (s *ClubService) Unban(actorID uint, role string, clubID, userID uint) error {
+	if err := s.authorize(actorID, role, clubID); err != nil {
+		return err
+	}
+	return repository.UnbanClubMember(clubID, userID)
+}
+
+func (s *ClubService) Bans(actorID uint, role string, clubID uint) ([]model.ClubBan, error) {
+	if err := s.authorize(actorID, role, clubID); err != nil {
+		return nil, err
+	}
+	return repository.GetClubBans(clubID)
+}
+
+func (s *ClubService) JoinRequests(actorID uint, role string, clubID uint, status string) ([]model.ClubJoinRequest, error) {
+	if err := s.authorize(actorID, role, clubID); err != nil {
+		return nil, err
+	}
+	switch st := model.JoinRequestStatus(status); st {
+	case "", model.JoinRequestPending, model.JoinRequestApproved, model.JoinRequestRejected:
+		return repository.GetClubJoinRequests(clubID, st)
+	default:
+		return nil, errors.New("invalid status")
+	}
+}
+
+// DecideJoinRequest approves or rejects a pending join request; approving subscribes the user
+func (s *ClubService) DecideJoinRequest(actorID uint, role string, clubID, requestID uint, approve bool) (model.ClubJoinRequest, error) {
+	if err := s.authorize(actorID, role, clubID); err != nil {
+		return model.ClubJoinRequest{}, err
+	}
+	if approve {
+		archived, err := repository.IsClubArchived(clubID)
+		if err != nil {
+			return model.ClubJoinRequest{}, err
+		}
+		if archived {
+			return model.ClubJoinRequest{}, ErrClubArchived
+		}
+	}
+	return repository.DecideClubJoinRequest(clubID, requestID, actorID, approve)
+}
+
+// IsBannedFromChat reports whether the user (websocket string id) is banned from the club owning the chat
+func (s *ClubService) IsBannedFromChat(chatID, userID string) bool {
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return false
+	}
+	club, err := repository.GetClubByChatID(chatID)
+	if err != nil {
+		return false
+	}
+	banned, err := repository.IsUserBannedFromClub(club.ID, uint(uid))
+	return err == nil && banned
+}
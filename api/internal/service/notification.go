@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"mosprom/api/internal/events"
+	"mosprom/api/internal/model"
+	"mosprom/api/internal/repository"
+)
+
+type NotificationService struct{}
+
+func NewNotificationService() *NotificationService { return &NotificationService{} }
+
+// Notify sends a copy of the notification to every given user
+func (s *NotificationService) Notify(userIDs []uint, n model.Notification) error {
+	items := make([]model.Notification, 0, len(userIDs))
+	for _, uid := range userIDs {
+		item := n
+		item.UserID = uid
+		items = append(items, item)
+	}
+	return repository.CreateNotifications(items)
+}
+
+func (s *NotificationService) List(userID uint, unreadOnly bool) ([]model.Notification, error) {
+	return repository.GetUserNotifications(userID, unreadOnly)
+}
+
+func (s *NotificationService) MarkRead(userID, notificationID uint) (bool, error) {
+	return repository.MarkNotificationRead(userID, notificationID)
+}
+
+// OnUserJoinedPost tells the club creator that someone joined one of the club's posts
+func (s *NotificationService) OnUserJoinedPost(ctx context.Context, e events.Event) error {
+	club, err := repository.GetClubByID(e.ClubID)
+	if err != nil {
+		return err
+	}
+	if club.CreatorID == 0 || club.CreatorID == e.UserID {
+		return nil
+	}
+	post, err := repository.GetPostByID(e.PostID)
+	if err != nil {
+		return err
+	}
+	user, err := repository.GetUserByID(e.UserID)
+	if err != nil {
+		return err
+	}
+	name := user.Name
+	if name == "" {
+		name = user.TelegramName
+	}
+	eventID := e.ID
+	return s.Notify([]uint{club.CreatorID}, model.Notification{
+		Type:    model.NewParticipantNotification,
+		Title:   post.Title,
+		Body:    fmt.Sprintf("%s joined %q", name, post.Title),
+		PostID:  &e.PostID,
+		ClubID:  &e.ClubID,
+		EventID: &eventID,
+	})
+}
+
+// OnWaitlistPromoted tells a waitlisted user that a place opened up and they now take part in the post
+func (s *NotificationService) OnWaitlistPromoted(ctx context.Context, e events.Event) error {
+	post, err := repository.GetPostByID(e.PostID)
+	if err != nil {
+		return err
+	}
+	eventID := e.ID
+	return s.Notify([]uint{e.UserID}, model.Notification{
+		Type:    model.WaitlistNotification,
+		Title:   post.Title,
+		Body:    fmt.Sprintf("A place opened up: you are now taking part in %q", post.Title),
+		PostID:  &e.PostID,
+		ClubID:  &e.ClubID,
+		EventID: &eventID,
+	})
+}
+
+// OnRegistrationCreated tells the club creator that someone registered for one of the club's approval posts
+func (s *NotificationService) OnRegistrationCreated(ctx context.Context, e events.Event) error {
+	club, err := repository.GetClubByID(e.ClubID)
+	if err != nil {
+		return err
+	}
+	if club.CreatorID == 0 || club.CreatorID == e.UserID {
+		return nil
+	}
+	post, err := repository.GetPostByID(e.PostID)
+	if err != nil {
+		return err
+	}
+	user, err := repository.GetUserByID(e.UserID)
+	if err != nil {
+		return err
+	}
+	name := user.Name
+	if name == "" {
+		name = user.TelegramName
+	}
+	eventID := e.ID
+	return s.Notify([]uint{club.CreatorID}, model.Notification{
+		Type:    model.RegistrationNotification,
+		Title:   post.Title,
+		Body:    fmt.Sprintf("%s registered for %q and awaits approval", name, post.Title),
+		PostID:  &e.PostID,
+		ClubID:  &e.ClubID,
+		EventID: &eventID,
+	})
+}
+
+// OnRegistrationDecided tells the applicant whether their registration was approved or rejected
+func (s *NotificationService) OnRegistrationDecided(ctx context.Context, e events.Event) error {
+	post, err := repository.GetPostByID(e.PostID)
+	if err != nil {
+		return err
+	}
+	var body string
+	switch e.Type {
+	case model.EventRegistrationApproved:
+		body = fmt.Sprintf("Your registration for %q was approved", post.Title)
+	case model.EventRegistrationRejected:
+		body = fmt.Sprintf("Your registration for %q was rejected", post.Title)
+		if e.Reason != "" {
+			body += ": " + e.Reason
+		}
+	default:
+		return nil
+	}
+	eventID := e.ID
+	return s.Notify([]uint{e.UserID}, model.Notification{
+		Type:    model.RegistrationNotification,
+		Title:   post.Title,
+		Body:    body,
+		PostID:  &e.PostID,
+		ClubID:  &e.ClubID,
+		EventID: &eventID,
+	})
+}
+
+// OnClubMembershipChanged tells a user they were kicked or banned from a club, or that their join request was rejected
+func (s *NotificationService) OnClubMembershipChanged(ctx context.Context, e events.Event) error {
+	club, err := repository.GetClubByID(e.ClubID)
+	if err != nil {
+		return err
+	}
+	var body string
+	switch e.Type {
+	case model.EventClubMemberKicked:
+		body = fmt.Sprintf("You were removed from %q", club.Name)
+	case model.EventClubMemberBanned:
+		body = fmt.Sprintf("You were banned from %q", club.Name)
+	case model.EventClubJoinRejected:
+		body = fmt.Sprintf("Your request to join %q was rejected", club.Name)
+	default:
+		return nil
+	}
+	if e.Reason != "" {
+		body += ": " + e.Reason
+	}
+	eventID := e.ID
+	return s.Notify([]uint{e.UserID}, model.Notification{
+		Type:    model.ClubMembershipNotification,
+		Title:   club.Name,
+		Body:    body,
+		ClubID:  &e.ClubID,
+		EventID: &eventID,
+	})
+}
+
+// OnInvitationCreated tells the invitee about a new invitation to a club or post
+func (s *NotificationService) OnInvitationCreated(ctx context.Context, e events.Event) error {
+	club, err := repository.GetClubByID(e.ClubID)
+	if err != nil {
+		return err
+	}
+	n := model.Notification{
+		Type:   model.InvitationNotification,
+		Title:  club.Name,
+		Body:   fmt.Sprintf("You are invited to join %q", club.Name),
+		ClubID: &e.ClubID,
+	}
+	if e.PostID != 0 {
+		post, err := repository.GetPostByID(e.PostID)
+		if err != nil {
+			return err
+		}
+		n.Title = post.Title
+		n.Body = fmt.Sprintf("%s invites you to %q", club.Name, post.Title)
+		n.PostID = &e.PostID
+	}
+	eventID := e.ID
+	n.EventID = &eventID
+	return s.Notify([]uint{e.UserID}, n)
+}
+
+// OnOrganizationReviewed tells the organization's staff about the verification decision
+func (s *NotificationService) OnOrganizationReviewed(ctx context.Context, e events.Event) error {
+	org, err := repository.GetOrganizationByID(e.OrganizationID)
+	if err != nil {
+		return err
+	}
+	body := fmt.Sprintf("%q is verified", org.Name)
+	if e.Type == model.EventOrganizationRejected {
+		body = fmt.Sprintf("Verification of %q was rejected: %s", org.Name, e.Reason)
+	}
+	staff, err := repository.GetOrganizationStaff(org.ID)
+	if err != nil {
+		return err
+	}
+	ids := make([]uint, 0, len(staff))
+	for _, st := range staff {
+		ids = append(ids, st.UserID)
+	}
+	eventID := e.ID
+	return s.Notify(ids, model.Notification{
+		Type:    model.OrganizationNotification,
+		Title:   org.Name,
+		Body:    body,
+		EventID: &eventID,
+	})
+}
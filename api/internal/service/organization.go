@@ -0,0 +1,276 @@
+package service
+
+import (
+	"errors"
+	"mosprom/api/internal/model"
+	"mosprom/api/internal/repository"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrOrganizationReviewed is returned when verifying or rejecting an already reviewed organization
+	ErrOrganizationReviewed = repository.ErrOrganizationReviewed
+	// ErrINNClaimed is returned when another organization that is not rejected holds the INN
+	ErrINNClaimed = repository.ErrINNClaimed
+	// ErrOrganizationReleased is returned when releasing an already rejected organization
+	ErrOrganizationReleased = repository.ErrOrganizationReleased
+)
+
+var (
+	innPattern  = regexp.MustCompile(`^(\d{10}|\d{12})$`)
+	ogrnPattern = regexp.MustCompile(`^(\d{13}|\d{15})$`)
+)
+
+type OrganizationService struct {
+	clubs *ClubService
+}
+
+func NewOrganizationService() *OrganizationService {
+	return &OrganizationService{clubs: NewClubService()}
+}
+
+type OrganizationInput struct {
+	Name         string `json:"name" binding:"required"`
+	LegalName    string `json:"legal_name" binding:"required"`
+	INN          string `json:"inn" binding:"required"`
+	OGRN         string `json:"ogrn"`
+	LegalAddress string `json:"legal_address"`
+	Website      string `json:"website"`
+	ContactEmail string `json:"contact_email"`
+}
+
+func (in OrganizationInput) apply(org *model.Organization) error {
+	org.Name = strings.TrimSpace(in.Name)
+	org.LegalName = strings.TrimSpace(in.LegalName)
+	org.INN = strings.TrimSpace(in.INN)
+	org.OGRN = strings.TrimSpace(in.OGRN)
+	org.LegalAddress = strings.TrimSpace(in.LegalAddress)
+	org.Website = strings.TrimSpace(in.Website)
+	org.ContactEmail = strings.TrimSpace(in.ContactEmail)
+	if org.Name == "" || org.LegalName == "" {
+		return errors.New("name and legal_name must not be empty")
+	}
+	if !innPattern.MatchString(org.INN) {
+		return errors.New("inn must be 10 or 12 digits")
+	}
+	if org.OGRN != "" && !ogrnPattern.MatchString(org.OGRN) {
+		return errors.New("ogrn must be 13 or 15 digits")
+	}
+	return nil
+}
+
+// Create registers a pending organization owned by the actor
+func (s *OrganizationService) Create(actorID uint, input OrganizationInput) (model.Organization, error) {
+	var org model.Organization
+	if err := input.apply(&org); err != nil {
+		return model.Organization{}, err
+	}
+	if err := checkINNFree(org.INN, 0); err != nil {
+		return model.Organization{}, err
+	}
+	org.CreatedByID = actorID
+	if err := repository.CreateOrganization(&org, actorID); err != nil {
+		return model.Organization{}, err
+	}
+	return org, nil
+}
+
+func (s *OrganizationService) Get(orgID uint) (model.Organization, error) {
+	return repository.GetOrganizationByID(orgID)
+}
+
+// List returns organizations with the given status; empty lists all
+func (s *OrganizationService) List(status model.OrganizationStatus) ([]model.Organization, error) {
+	switch status {
+	case "", model.OrganizationPending, model.OrganizationVerified, model.OrganizationRejected:
+	default:
+		return nil, errors.New("status must be pending, verified or rejected")
+	}
+	return repository.ListOrganizations(status)
+}
+
+func (s *OrganizationService) Mine(userID uint) ([]model.Organization, error) {
+	return repository.GetUserOrganizations(userID)
+}
+
+// Update edits the organization on behalf of an owner. Changing legal details of a verified organization,
+// or any edit of a rejected one, sends it back to verification.
+func (s *OrganizationService) Update(actorID uint, role string, orgID uint, input OrganizationInput) (model.Organization, error) {
+	if err := s.authorize(actorID, role, orgID, true); err != nil {
+		return model.Organization{}, err
+	}
+	org, err := repository.GetOrganizationByID(orgID)
+	if err != nil {
+		return model.Organization{}, err
+	}
+	before := org
+	if err := input.apply(&org); err != nil {
+		return model.Organization{}, err
+	}
+	legalChanged := org.LegalName != before.LegalName || org.INN != before.INN ||
+		org.OGRN != before.OGRN || org.LegalAddress != before.LegalAddress
+	// a rejected organization is re-reviewed after any fix
+	reverify := org.Status == model.OrganizationRejected || org.Status == model.OrganizationVerified && legalChanged
+	// a rejected organization takes its INN back when it returns to review
+	if org.INN != before.INN || org.Status == model.OrganizationRejected {
+		if err := checkINNFree(org.INN, org.ID); err != nil {
+			return model.Organization{}, err
+		}
+	}
+	if err := repository.UpdateOrganization(&org, reverify); err != nil {
+		return model.Organization{}, err
+	}
+	return org, nil
+}
+
+// Staff lists staff accounts; visible to staff and platform admins
+func (s *OrganizationService) Staff(actorID uint, role string, orgID uint) ([]model.OrganizationStaff, error) {
+	if err := s.authorize(actorID, role, orgID, false); err != nil {
+		return nil, err
+	}
+	return repository.GetOrganizationStaff(orgID)
+}
+
+// SetStaff adds a user account to the organization or changes its role; owners only
+func (s *OrganizationService) SetStaff(actorID uint, role string, orgID, userID uint, staffRole string) error {
+	if err := s.authorize(actorID, role, orgID, true); err != nil {
+		return err
+	}
+	switch staffRole {
+	case "":
+		staffRole = model.OrgRoleStaff
+	case model.OrgRoleOwner, model.OrgRoleStaff:
+	default:
+		return errors.New("role must be owner or staff")
+	}
+	if _, err := repository.GetUserByID(userID); err != nil {
+		return err
+	}
+	if staffRole == model.OrgRoleStaff {
+		if err := s.keepOwner(orgID, userID); err != nil {
+			return err
+		}
+	}
+	return repository.SetOrganizationStaff(orgID, userID, staffRole)
+}
+
+// RemoveStaff takes a user out of the organization; owners may remove anyone, staff only themselves
+func (s *OrganizationService) RemoveStaff(actorID uint, role string, orgID, userID uint) error {
+	if err := s.authorize(actorID, role, orgID, actorID != userID); err != nil {
+		return err
+	}
+	if err := s.keepOwner(orgID, userID); err != nil {
+		return err
+	}
+	return repository.RemoveOrganizationStaff(orgID, userID)
+}
+
+// keepOwner fails when userID is the organization's last owner
+func (s *OrganizationService) keepOwner(orgID, userID uint) error {
+	current, err := repository.GetOrganizationStaffRole(orgID, userID)
+	if err != nil || current != model.OrgRoleOwner {
+		return err
+	}
+	owners, err := repository.CountOrganizationOwners(orgID)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return errors.New("organization must keep at least one owner")
+	}
+	return nil
+}
+
+// Verify approves a pending organization; its clubs get the verified badge
+func (s *OrganizationService) Verify(adminID, orgID uint) (model.Organization, error) {
+	return repository.ReviewOrganization(orgID, adminID, true, "", time.Now())
+}
+
+// Reject declines a pending organization with a reason shown to its staff
+func (s *OrganizationService) Reject(adminID, orgID uint, reason string) (model.Organization, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return model.Organization{}, errors.New("reason must not be empty")
+	}
+	return repository.ReviewOrganization(orgID, adminID, false, reason, time.Now())
+}
+
+// Release rejects a pending or verified organization so that another one can claim its INN,
+// e.g. when the INN was registered by someone unrelated to the enterprise
+func (s *OrganizationService) Release(adminID, orgID uint, reason string) (model.Organization, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return model.Organization{}, errors.New("reason must not be empty")
+	}
+	return repository.ReleaseOrganization(orgID, adminID, reason, time.Now())
+}
+
+// checkINNFree fails with ErrINNClaimed when an organization other than exceptID holds the INN
+func checkINNFree(inn string, exceptID uint) error {
+	claimed, err := repository.IsINNClaimed(inn, exceptID)
+	if err != nil {
+		return err
+	}
+	if claimed {
+		return ErrINNClaimed
+	}
+	return nil
+}
+
+// AttachClub makes the organization the owner of the club. The actor must manage the club and be
+// staff of the organization.
+func (s *OrganizationService) AttachClub(actorID uint, role string, clubID, orgID uint) (model.Club, error) {
+	if err := s.clubs.authorize(actorID, role, clubID); err != nil {
+		return model.Club{}, err
+	}
+	if err := s.authorize(actorID, role, orgID, false); err != nil {
+		return model.Club{}, err
+	}
+	if err := repository.SetClubOrganization(clubID, &orgID); err != nil {
+		return model.Club{}, err
+	}
+	return repository.GetClubByID(clubID)
+}
+
+// DetachClub removes the club from its organization; allowed to club managers and organization staff
+func (s *OrganizationService) DetachClub(actorID uint, role string, clubID uint) (model.Club, error) {
+	club, err := repository.GetClubByID(clubID)
+	if err != nil {
+		return model.Club{}, err
+	}
+	if club.OrganizationID == nil {
+		return club, nil
+	}
+	if err := s.clubs.authorize(actorID, role, clubID); err != nil {
+		if !errors.Is(err, ErrForbidden) {
+			return model.Club{}, err
+		}
+		if err := s.authorize(actorID, role, *club.OrganizationID, false); err != nil {
+			return model.Club{}, err
+		}
+	}
+	if err := repository.SetClubOrganization(clubID, nil); err != nil {
+		return model.Club{}, err
+	}
+	return repository.GetClubByID(clubID)
+}
+
+// authorize lets platform admins and organization staff through; ownerOnly requires the owner role
+func (s *OrganizationService) authorize(actorID uint, role string, orgID uint, ownerOnly bool) error {
+	if _, err := repository.GetOrganizationByID(orgID); err != nil {
+		return err
+	}
+	if role == model.RoleAdmin {
+		return nil
+	}
+	staffRole, err := repository.GetOrganizationStaffRole(orgID, actorID)
+	if err != nil {
+		return err
+	}
+	if staffRole == "" || ownerOnly && staffRole != model.OrgRoleOwner {
+		return ErrForbidden
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"mosprom/api/internal/model"
+	"mosprom/api/internal/pages"
+	"mosprom/api/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SavePageInput struct {
+	Blocks []pages.Block `json:"blocks" binding:"required"`
+}
+
+// SavePageDraft validates the layout and stores it as a new draft version
+func (s *ClubService) SavePageDraft(actorID uint, role string, clubID uint, input SavePageInput) (model.ClubPageVersion, error) {
+	if err := s.authorize(actorID, role, clubID); err != nil {
+		return model.ClubPageVersion{}, err
+	}
+	archived, err := repository.IsClubArchived(clubID)
+	if err != nil {
+		return model.ClubPageVersion{}, err
+	}
+	if archived {
+		return model.ClubPageVersion{}, ErrClubArchived
+	}
+	for i := range input.Blocks {
+		if input.Blocks[i].ID == "" {
+			input.Blocks[i].ID = uuid.NewString()[:8]
+		}
+	}
+	if err := pages.Validate(input.Blocks); err != nil {
+		return model.ClubPageVersion{}, err
+	}
+	if ids := uniqueUints(pages.PinnedPostIDs(input.Blocks)); len(ids) > 0 {
+		n, err := repository.CountClubPosts(clubID, ids)
+		if err != nil {
+			return model.ClubPageVersion{}, err
+		}
+		if int(n) != len(ids) {
+			return model.ClubPageVersion{}, errors.New("pinned posts must belong to the club")
+		}
+	}
+	raw, err := json.Marshal(input.Blocks)
+	if err != nil {
+		return model.ClubPageVersion{}, err
+	}
+	v := model.ClubPageVersion{ClubID: clubID, Blocks: raw, CreatedByID: actorID}
+	if err := repository.CreateClubPageVersion(&v); err != nil {
+		return model.ClubPageVersion{}, err
+	}
+	return v, nil
+}
+
+func (s *ClubService) PageVersions(actorID uint, role string, clubID uint) ([]model.ClubPageVersion, error) {
+	if err := s.authorize(actorID, role, clubID); err != nil {
+		return nil, err
+	}
+	return repository.GetClubPageVersions(clubID)
+}
+
+func (s *ClubService) PageVersion(actorID uint, role string, clubID uint, version int) (model.ClubPageVersion, error) {
+	if err := s.authorize(actorID, role, clubID); err != nil {
+		return model.ClubPageVersion{}, err
+	}
+	return repository.GetClubPageVersion(clubID, version)
+}
+
+// PublishPage makes the version the public page; publishing an older version rolls back to it
+func (s *ClubService) PublishPage(actorID uint, role string, clubID uint, version int) (model.ClubPageVersion, error) {
+	if err := s.authorize(actorID, role, clubID); err != nil {
+		return model.ClubPageVersion{}, err
+	}
+	archived, err := repository.IsClubArchived(clubID)
+	if err != nil {
+		return model.ClubPageVersion{}, err
+	}
+	if archived {
+		return model.ClubPageVersion{}, ErrClubArchived
+	}
+	return repository.PublishClubPageVersion(clubID, version, time.Now())
+}
+
+// PublishedPage returns the layout the app renders for the club
+func (s *ClubService) PublishedPage(clubID uint) (model.ClubPageVersion, error) {
+	return repository.GetPublishedClubPage(clubID)
+}
+
+// PageBlockSchemas returns the JSON schema of every block type
+func (s *ClubService) PageBlockSchemas() map[string]*pages.Schema {
+	return pages.Schemas
+}
+
+func uniqueUints(in []uint) []uint {
+	seen := make(map[uint]bool, len(in))
+	out := make([]uint, 0, len(in))
+	for _, v := range in {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
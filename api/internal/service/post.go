@@ -1,25 +1,57 @@
 package service
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"log"
 	"mosprom/api/internal/model"
 	"mosprom/api/internal/repository"
+	"strconv"
 	"time"
 
 	"gorm.io/gorm"
 )
 
-type PostService struct{}
+type PostService struct {
+	reminders *ReminderService
+	clubs     *ClubService
+}
+
+// AgeRestrictionError rejects joins and registrations of users too young for a post (match with errors.As)
+type AgeRestrictionError = repository.AgeRestrictionError
 
-func NewPostService() *PostService {
-	return &PostService{}
+func NewPostService(reminders *ReminderService) *PostService {
+	return &PostService{reminders: reminders, clubs: NewClubService()}
 }
 
-func (s *PostService) Join(userID, postID uint) (model.Post, error) {
-	if err := repository.JoinUserToPost(userID, postID); err != nil {
+// Join adds the user to the post's participants, or to its waitlist when the post is at capacity.
+// Posts requiring approval get a pending registration instead, which is returned.
+func (s *PostService) Join(userID, postID uint, reg RegistrationInput) (model.Post, *model.PostRegistration, error) {
+	post, err := repository.GetPostByID(postID)
+	if err != nil {
+		return model.Post{}, nil, err
+	}
+	if post.RegistrationMode == model.RegistrationApproval {
+		r, err := s.register(userID, post, reg)
+		if err != nil {
+			return model.Post{}, nil, err
+		}
+		return post, &r, nil
+	}
+	if _, err := repository.JoinUserToPost(userID, postID); err != nil {
+		return model.Post{}, nil, err
+	}
+	// Return the updated post; the viewer's status is in its DTO
+	post, err = repository.GetPostByID(postID)
+	return post, nil, err
+}
+
+// Leave removes the user from the post's participants or waitlist; a freed place goes to the first waitlisted user
+func (s *PostService) Leave(userID, postID uint) (model.Post, error) {
+	if err := repository.LeavePost(userID, postID); err != nil {
 		return model.Post{}, err
 	}
-	// Return the updated post with participants
 	return repository.GetPostByID(postID)
 }
 
@@ -33,31 +65,63 @@ type CreatePostInput struct {
 	Format         *model.PostFormat `json:"format"`
 	Address        string            `json:"address"`
 	ClubID         uint              `json:"club_id" binding:"required"`
+	Capacity       *int              `json:"capacity"` // omit for unlimited
+	// open (default) or approval; questions are set by PUT /posts/{id}/registration-questions
+	RegistrationMode model.RegistrationMode `json:"registration_mode"`
+	// RRULE subset for activity and educational posts, e.g. FREQ=WEEKLY;BYDAY=TU,TH;COUNT=10.
+	// Each occurrence is created as its own post and the first one is returned.
+	Recurrence string `json:"recurrence" example:"FREQ=WEEKLY;BYDAY=TU;UNTIL=20261231"`
 }
 
 type UpdatePostInput struct {
-	ID             uint
-	Title          *string           `json:"title"`
-	Description    *string           `json:"description"`
-	Type           *model.PostType   `json:"type"`
-	StartDate      *time.Time        `json:"start_date"`
-	EndDate        *time.Time        `json:"end_date"`
-	AgeRestriction *int              `json:"age_restriction"`
-	Format         *model.PostFormat `json:"format"`
-	Address        *string           `json:"address"`
+	ID               uint
+	Title            *string                 `json:"title"`
+	Description      *string                 `json:"description"`
+	Type             *model.PostType         `json:"type"`
+	StartDate        *time.Time              `json:"start_date"`
+	EndDate          *time.Time              `json:"end_date"`
+	AgeRestriction   *int                    `json:"age_restriction"`
+	Format           *model.PostFormat       `json:"format"`
+	Address          *string                 `json:"address"`
+	Capacity         *int                    `json:"capacity"` // 0 removes the limit
+	RegistrationMode *model.RegistrationMode `json:"registration_mode"`
 }
 
 func (s *PostService) CreatePost(input CreatePostInput) (model.Post, error) {
+	archived, err := repository.IsClubArchived(input.ClubID)
+	if err != nil {
+		return model.Post{}, err
+	}
+	if archived {
+		return model.Post{}, ErrClubArchived
+	}
+
+	if input.Capacity != nil && *input.Capacity <= 0 {
+		return model.Post{}, errors.New("capacity must be positive")
+	}
+	if input.RegistrationMode == "" {
+		input.RegistrationMode = model.RegistrationOpen
+	}
+	if !isRegistrationMode(input.RegistrationMode) {
+		return model.Post{}, errors.New("registration_mode must be open or approval")
+	}
+
+	if input.Recurrence != "" {
+		return s.createSeries(input)
+	}
+
 	post := model.Post{
-		Title:          input.Title,
-		Description:    input.Description,
-		Type:           input.Type,
-		StartDate:      input.StartDate,
-		EndDate:        input.EndDate,
-		AgeRestriction: input.AgeRestriction,
-		Format:         input.Format,
-		Address:        input.Address,
-		ClubID:         input.ClubID,
+		Title:            input.Title,
+		Description:      input.Description,
+		Type:             input.Type,
+		StartDate:        input.StartDate,
+		EndDate:          input.EndDate,
+		AgeRestriction:   input.AgeRestriction,
+		Format:           input.Format,
+		Address:          input.Address,
+		ClubID:           input.ClubID,
+		Capacity:         input.Capacity,
+		RegistrationMode: input.RegistrationMode,
 	}
 
 	if err := repository.CreatePost(&post); err != nil {
@@ -69,6 +133,10 @@ func (s *PostService) CreatePost(input CreatePostInput) (model.Post, error) {
 		return model.Post{}, err
 	}
 
+	if err := s.reminders.Schedule(createdPost); err != nil {
+		log.Printf("schedule reminders for post %d: %v", createdPost.ID, err)
+	}
+
 	return createdPost, nil
 }
 
@@ -76,12 +144,21 @@ func (s *PostService) GetPostByID(id uint) (model.Post, error) {
 	return repository.GetPostByID(id)
 }
 
-func (s *PostService) GetPostsByClubID(clubID uint) ([]model.Post, error) {
-	return repository.GetPostsByClubID(clubID)
-}
-
-func (s *PostService) GetAllPosts() ([]model.Post, error) {
-	return repository.GetAllPosts()
+// RecordPostView counts a post page view, at most once a day per signed-in viewer or, for guests, per IP.
+// Failures are logged, not returned, so viewing never breaks.
+func (s *PostService) RecordPostView(postID, viewerID uint, ip string) {
+	viewer := ""
+	switch {
+	case viewerID != 0:
+		viewer = "user:" + strconv.FormatUint(uint64(viewerID), 10)
+	case ip != "":
+		// addresses of guests are not kept, only used to tell them apart
+		sum := sha256.Sum256([]byte(ip))
+		viewer = "ip:" + hex.EncodeToString(sum[:16])
+	}
+	if err := repository.RecordPostView(postID, viewer, time.Now()); err != nil {
+		log.Printf("record view of post %d: %v", postID, err)
+	}
 }
 
 func (s *PostService) UpdatePost(input UpdatePostInput) (model.Post, error) {
@@ -89,6 +166,13 @@ func (s *PostService) UpdatePost(input UpdatePostInput) (model.Post, error) {
 	if err != nil {
 		return model.Post{}, err
 	}
+	archived, err := repository.IsClubArchived(post.ClubID)
+	if err != nil {
+		return model.Post{}, err
+	}
+	if archived {
+		return model.Post{}, ErrClubArchived
+	}
 
 	if input.Title != nil {
 		post.Title = *input.Title
@@ -99,6 +183,7 @@ func (s *PostService) UpdatePost(input UpdatePostInput) (model.Post, error) {
 	if input.Type != nil {
 		post.Type = *input.Type
 	}
+	startChanged := input.StartDate != nil && (post.StartDate == nil || !post.StartDate.Equal(*input.StartDate))
 	if input.StartDate != nil {
 		post.StartDate = input.StartDate
 	}
@@ -114,6 +199,22 @@ func (s *PostService) UpdatePost(input UpdatePostInput) (model.Post, error) {
 	if input.Address != nil {
 		post.Address = *input.Address
 	}
+	if input.Capacity != nil {
+		switch {
+		case *input.Capacity < 0:
+			return model.Post{}, errors.New("capacity must not be negative")
+		case *input.Capacity == 0:
+			post.Capacity = nil
+		default:
+			post.Capacity = input.Capacity
+		}
+	}
+	if input.RegistrationMode != nil {
+		if !isRegistrationMode(*input.RegistrationMode) {
+			return model.Post{}, errors.New("registration_mode must be open or approval")
+		}
+		post.RegistrationMode = *input.RegistrationMode
+	}
 
 	if err := repository.UpdatePost(&post); err != nil {
 		return model.Post{}, err
@@ -124,10 +225,20 @@ func (s *PostService) UpdatePost(input UpdatePostInput) (model.Post, error) {
 		return model.Post{}, err
 	}
 
+	if startChanged {
+		if err := s.reminders.Schedule(updatedPost); err != nil {
+			log.Printf("reschedule reminders for post %d: %v", updatedPost.ID, err)
+		}
+	}
+
 	return updatedPost, nil
 }
 
+// DeletePost removes a post; its reminders are deleted by the foreign key cascade
 func (s *PostService) DeletePost(id uint) error {
+	if err := checkPostClubActive(id); err != nil {
+		return err
+	}
 	return repository.DeletePost(id)
 }
 
@@ -137,6 +248,9 @@ func (s *PostService) JoinedPosts(userID uint) ([]model.Post, error) {
 
 // SetPostTechnologies replaces technologies for a post, creating any missing by name
 func (s *PostService) SetPostTechnologies(postID uint, techNames []string) ([]model.Technology, error) {
+	if err := checkPostClubActive(postID); err != nil {
+		return nil, err
+	}
 	techs, err := repository.FindOrCreateTechnologiesByNames(techNames)
 	if err != nil {
 		return nil, err
@@ -152,6 +266,9 @@ func (s *PostService) TechnologiesByPostID(postID uint) ([]model.Technology, err
 }
 
 func (s *PostService) LikePost(postID, userID uint) error {
+	if err := checkPostClubActive(postID); err != nil {
+		return err
+	}
 	_, err := repository.GetLikeByUserAndPost(userID, postID)
 	if err == nil {
 		return nil
@@ -172,13 +289,27 @@ func (s *PostService) UnlikePost(postID, userID uint) error {
 	return repository.DeleteLike(userID, postID)
 }
 
-func (s *PostService) GetPostParticipants(postID uint) ([]model.User, error) {
-	return repository.GetPostParticipants(postID)
+const (
+	defaultParticipantsPage = 50
+	maxParticipantsPage     = 200
+)
+
+// ParticipantsPageSize is the page size GetPostParticipants uses for the requested limit
+func (s *PostService) ParticipantsPageSize(limit int) int {
+	if limit <= 0 {
+		return defaultParticipantsPage
+	}
+	return min(limit, maxParticipantsPage)
+}
+
+// GetPostParticipants returns a page of participants ordered by id, after the user id afterID
+func (s *PostService) GetPostParticipants(postID, afterID uint, limit int) ([]model.User, error) {
+	return repository.GetPostParticipants(postID, afterID, s.ParticipantsPageSize(limit))
 }
 
 type RecommendedPost struct {
-	Post      model.Post `json:"post"`
-	TechMatch float64    `json:"tech_match"`
+	Post      PostDTO `json:"post"`
+	TechMatch float64 `json:"tech_match"`
 }
 
 // RecommendedForUser returns posts sorted by technology match with the user
@@ -191,14 +322,14 @@ func (s *PostService) RecommendedForUser(userID uint) ([]RecommendedPost, error)
 	for _, r := range rows {
 		ids = append(ids, r.PostID)
 	}
-	m, err := repository.GetPostsByIDs(ids)
+	byID, err := postDTOsByID(ids, userID)
 	if err != nil {
 		return nil, err
 	}
 	out := make([]RecommendedPost, 0, len(rows))
 	for _, r := range rows {
-		if p, ok := m[r.PostID]; ok {
-			out = append(out, RecommendedPost{Post: p, TechMatch: r.TechMatch})
+		if d, ok := byID[r.PostID]; ok {
+			out = append(out, RecommendedPost{Post: d, TechMatch: r.TechMatch})
 		}
 	}
 	return out, nil
@@ -232,3 +363,15 @@ func (s *PostService) RecommendedUsersForPost(postID uint, alpha, beta float64)
 	}
 	return out, nil
 }
+
+// checkPostClubActive returns ErrClubArchived when the post belongs to an archived club
+func checkPostClubActive(postID uint) error {
+	archived, err := repository.IsPostClubArchived(postID)
+	if err != nil {
+		return err
+	}
+	if archived {
+		return ErrClubArchived
+	}
+	return nil
+}
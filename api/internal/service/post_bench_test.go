@@ -0,0 +1,168 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"mosprom/api/internal/db"
+	"mosprom/api/internal/dbtest"
+	"mosprom/api/internal/model"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// Every seeded post is liked and joined by every seeded user
+const (
+	benchPosts = 100
+	benchUsers = 50
+)
+
+var (
+	benchQueries  atomic.Int64
+	countCallback sync.Once
+)
+
+// countQueries registers a GORM callback counting every SELECT, preloads included, and resets the count
+func countQueries(b *testing.B, conn *gorm.DB) {
+	b.Helper()
+	countCallback.Do(func() {
+		count := func(*gorm.DB) { benchQueries.Add(1) }
+		if err := conn.Callback().Query().After("gorm:query").Register("bench:count_query", count); err != nil {
+			b.Fatal(err)
+		}
+		if err := conn.Callback().Row().After("gorm:row").Register("bench:count_row", count); err != nil {
+			b.Fatal(err)
+		}
+	})
+	benchQueries.Store(0)
+}
+
+// seedPosts creates a club with benchPosts posts and benchUsers users who like and joined all of them.
+// Returns the club and a viewer among those users.
+func seedPosts(b *testing.B) (clubID, viewerID uint) {
+	b.Helper()
+	conn := dbtest.Open(b)
+	users := make([]model.User, benchUsers)
+	for i := range users {
+		users[i] = model.User{TelegramName: fmt.Sprintf("bench_user_%d", i), Name: "Bench User"}
+	}
+	if err := conn.Create(&users).Error; err != nil {
+		b.Fatal(err)
+	}
+	club := model.Club{Name: "bench club", CreatorID: users[0].ID}
+	if err := conn.Create(&club).Error; err != nil {
+		b.Fatal(err)
+	}
+	posts := make([]model.Post, benchPosts)
+	for i := range posts {
+		posts[i] = model.Post{
+			Title:             fmt.Sprintf("Post %d", i),
+			Description:       "Benchmark post",
+			Type:              model.InfoPost,
+			ClubID:            club.ID,
+			ParticipantsCount: benchUsers,
+		}
+	}
+	if err := conn.Create(&posts).Error; err != nil {
+		b.Fatal(err)
+	}
+	if err := conn.Exec("INSERT INTO likes (user_id, post_id, created_at) SELECT u.id, p.id, now() FROM users u CROSS JOIN posts p").Error; err != nil {
+		b.Fatal(err)
+	}
+	if err := conn.Exec("INSERT INTO post_participants (post_id, user_id) SELECT p.id, u.id FROM posts p CROSS JOIN users u").Error; err != nil {
+		b.Fatal(err)
+	}
+	return club.ID, users[0].ID
+}
+
+// preloadAll is how posts were loaded before PostDTO: with every like and participant row
+func preloadAll(q *gorm.DB) *gorm.DB {
+	return q.Preload("Club").Preload("Likes").Preload("Technologies").Preload("Participants")
+}
+
+// runQueryBench reports queries/op and the JSON size of the response next to the timings
+func runQueryBench(b *testing.B, fn func() (any, error)) {
+	countQueries(b, db.DB)
+	var size int
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		out, err := fn()
+		if err != nil {
+			b.Fatal(err)
+		}
+		raw, err := json.Marshal(out)
+		if err != nil {
+			b.Fatal(err)
+		}
+		size = len(raw)
+	}
+	b.StopTimer()
+	b.ReportMetric(float64(benchQueries.Load())/float64(b.N), "queries/op")
+	b.ReportMetric(float64(size), "resp_bytes/op")
+}
+
+func BenchmarkGetAllPosts(b *testing.B) {
+	_, viewerID := seedPosts(b)
+	s := NewPostService(NewReminderService(nil))
+
+	b.Run("preload", func(b *testing.B) {
+		runQueryBench(b, func() (any, error) {
+			var posts []model.Post
+			err := preloadAll(db.DB).Order("created_at DESC, id DESC").Limit(maxPostPageSize).Find(&posts).Error
+			return posts, err
+		})
+	})
+	b.Run("dto", func(b *testing.B) {
+		runQueryBench(b, func() (any, error) {
+			page, err := s.ListPosts(PostListQuery{ViewerID: viewerID, Limit: maxPostPageSize})
+			return page.Posts, err
+		})
+	})
+}
+
+func BenchmarkListPosts(b *testing.B) {
+	clubID, viewerID := seedPosts(b)
+	s := NewPostService(NewReminderService(nil))
+
+	b.Run("preload", func(b *testing.B) {
+		runQueryBench(b, func() (any, error) {
+			var posts []model.Post
+			err := preloadAll(db.DB).Where("club_id = ?", clubID).Limit(defaultPostPageSize).Find(&posts).Error
+			return posts, err
+		})
+	})
+	b.Run("dto", func(b *testing.B) {
+		runQueryBench(b, func() (any, error) {
+			page, err := s.ListPosts(PostListQuery{ViewerID: viewerID, ClubID: &clubID})
+			return page.Posts, err
+		})
+	})
+}
+
+func BenchmarkGetPostByID(b *testing.B) {
+	_, viewerID := seedPosts(b)
+	s := NewPostService(NewReminderService(nil))
+	var postID uint
+	if err := db.DB.Model(&model.Post{}).Select("id").Order("id").Limit(1).Scan(&postID).Error; err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("preload", func(b *testing.B) {
+		runQueryBench(b, func() (any, error) {
+			var post model.Post
+			err := preloadAll(db.DB).First(&post, postID).Error
+			return post, err
+		})
+	})
+	b.Run("dto", func(b *testing.B) {
+		runQueryBench(b, func() (any, error) {
+			post, err := s.GetPostByID(postID)
+			if err != nil {
+				return nil, err
+			}
+			return s.DTO(post, viewerID)
+		})
+	})
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"mosprom/api/internal/model"
+	"mosprom/api/internal/repository"
+	"time"
+)
+
+// ClubBrief is the part of a club embedded in post responses
+type ClubBrief struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	Logo     string `json:"logo"`
+	Verified bool   `json:"verified"`
+}
+
+// PostDTO is the API representation of a post: counters instead of like and participant arrays.
+// Participants are listed by GET /posts/{id}/participants.
+type PostDTO struct {
+	ID                 uint                             `json:"id"`
+	Title              string                           `json:"title"`
+	Description        string                           `json:"description"`
+	Type               model.PostType                   `json:"type"`
+	StartDate          *time.Time                       `json:"start_date"`
+	EndDate            *time.Time                       `json:"end_date"`
+	AgeRestriction     *int                             `json:"age_restriction"`
+	Format             *model.PostFormat                `json:"format"`
+	Address            string                           `json:"address"`
+	ClubID             uint                             `json:"club_id"`
+	Club               *ClubBrief                       `json:"club"`
+	CoHosts            []ClubBrief                      `json:"co_hosts"`
+	Technologies       []model.Technology               `json:"technologies"`
+	SeriesID           *uint                            `json:"series_id"`            // set on occurrences of a recurring post
+	Recurrence         string                           `json:"recurrence,omitempty"` // RRULE of the series
+	RegistrationMode   model.RegistrationMode           `json:"registration_mode"`
+	Questions          []model.PostRegistrationQuestion `json:"registration_questions,omitempty"` // single-post responses only
+	LikesCount         int                              `json:"likes_count"`
+	ParticipantsCount  int                              `json:"participants_count"`
+	Capacity           *int                             `json:"capacity"`
+	WaitlistCount      int                              `json:"waitlist_count"`
+	AttendedCount      int                              `json:"attended_count"` // verified check-ins
+	LikedByMe          bool                             `json:"liked_by_me"`
+	JoinedByMe         bool                             `json:"joined_by_me"`
+	WaitlistPosition   int                              `json:"waitlist_position"`             // viewer's 1-based place on the waitlist, 0 if not on it
+	RegistrationStatus model.RegistrationStatus         `json:"registration_status,omitempty"` // viewer's latest registration
+	CheckedInByMe      bool                             `json:"checked_in_by_me"`
+	AgeEligible        bool                             `json:"age_eligible"`        // viewer is old enough; false for anonymous viewers of restricted posts
+	SeriesJoinedByMe   bool                             `json:"series_joined_by_me"` // viewer joined the whole series
+	CreatedAt          time.Time                        `json:"created_at"`
+	UpdatedAt          time.Time                        `json:"updated_at"`
+}
+
+func clubBrief(c *model.Club) *ClubBrief {
+	if c == nil {
+		return nil
+	}
+	return &ClubBrief{ID: c.ID, Name: c.Name, Logo: c.Logo, Verified: c.Verified}
+}
+
+// postDTOs converts loaded posts, fetching counters for viewerID (0 for anonymous) in one query
+func postDTOs(posts []model.Post, viewerID uint) ([]PostDTO, error) {
+	ids := make([]uint, 0, len(posts))
+	for _, p := range posts {
+		ids = append(ids, p.ID)
+	}
+	stats, err := repository.GetPostStats(ids, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	var seriesIDs []uint
+	for _, p := range posts {
+		if p.SeriesID != nil {
+			seriesIDs = append(seriesIDs, *p.SeriesID)
+		}
+	}
+	series, err := repository.GetPostSeriesInfo(seriesIDs, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]PostDTO, 0, len(posts))
+	for _, p := range posts {
+		st := stats[p.ID]
+		d := PostDTO{
+			ID:                 p.ID,
+			Title:              p.Title,
+			Description:        p.Description,
+			Type:               p.Type,
+			StartDate:          p.StartDate,
+			EndDate:            p.EndDate,
+			AgeRestriction:     p.AgeRestriction,
+			Format:             p.Format,
+			Address:            p.Address,
+			ClubID:             p.ClubID,
+			Club:               clubBrief(p.Club),
+			CoHosts:            make([]ClubBrief, 0, len(p.CoHosts)),
+			Technologies:       p.Technologies,
+			RegistrationMode:   p.RegistrationMode,
+			Questions:          p.Questions,
+			LikesCount:         st.LikesCount,
+			ParticipantsCount:  p.ParticipantsCount,
+			Capacity:           p.Capacity,
+			WaitlistCount:      st.Waitlist,
+			AttendedCount:      st.Attended,
+			LikedByMe:          st.LikedByMe,
+			JoinedByMe:         st.JoinedByMe,
+			WaitlistPosition:   st.MyPosition,
+			RegistrationStatus: st.MyRegistration,
+			CheckedInByMe:      st.CheckedInByMe,
+			AgeEligible:        st.AgeEligible,
+			CreatedAt:          p.CreatedAt,
+			UpdatedAt:          p.UpdatedAt,
+		}
+		if p.SeriesID != nil {
+			info := series[*p.SeriesID]
+			d.SeriesID = p.SeriesID
+			d.Recurrence = info.RRule
+			d.SeriesJoinedByMe = info.JoinedBy
+		}
+		if d.Technologies == nil {
+			d.Technologies = []model.Technology{}
+		}
+		for _, h := range p.CoHosts {
+			if b := clubBrief(h.Club); b != nil {
+				d.CoHosts = append(d.CoHosts, *b)
+			}
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+// postDTOsByID loads the posts with the given ids and converts them; missing ids are left out of the map
+func postDTOsByID(ids []uint, viewerID uint) (map[uint]PostDTO, error) {
+	m, err := repository.GetPostsByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	posts := make([]model.Post, 0, len(m))
+	for _, p := range m {
+		posts = append(posts, p)
+	}
+	dtos, err := postDTOs(posts, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[uint]PostDTO, len(dtos))
+	for _, d := range dtos {
+		out[d.ID] = d
+	}
+	return out, nil
+}
+
+// DTO converts a loaded post as seen by viewerID (0 for anonymous)
+func (s *PostService) DTO(p model.Post, viewerID uint) (PostDTO, error) {
+	out, err := postDTOs([]model.Post{p}, viewerID)
+	if err != nil {
+		return PostDTO{}, err
+	}
+	return out[0], nil
+}
+
+// DTOs converts loaded posts as seen by viewerID (0 for anonymous)
+func (s *PostService) DTOs(posts []model.Post, viewerID uint) ([]PostDTO, error) {
+	return postDTOs(posts, viewerID)
+}
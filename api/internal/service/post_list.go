@@ -0,0 +1,122 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"mosprom/api/internal/model"
+	"mosprom/api/internal/repository"
+	"time"
+)
+
+const (
+	defaultPostPageSize = 20
+	maxPostPageSize     = 100
+)
+
+type PostListQuery struct {
+	Types        []model.PostType
+	Format       *model.PostFormat
+	ClubID       *uint
+	Technologies []string
+	Directions   []string
+	From         *time.Time
+	To           *time.Time
+	Upcoming     bool
+	Age          *int
+	EligibleOnly bool   // leave out posts the viewer is too young for (or cannot prove their age for)
+	Sort         string // created (default), start or popular
+	Cursor       string
+	Limit        int
+	ViewerID     uint // for liked_by_me and joined_by_me, 0 for anonymous
+}
+
+type PostPage struct {
+	Posts      []PostDTO
+	Total      int64
+	NextCursor string // empty on the last page
+}
+
+// postCursor is the opaque form of repository.PostKey; Sort guards against reusing a cursor with another sort
+type postCursor struct {
+	Sort string     `json:"s"`
+	Time *time.Time `json:"t,omitempty"`
+	Num  int64      `json:"n,omitempty"`
+	ID   uint       `json:"i"`
+}
+
+// ListPosts returns one page of posts matching the query with the total number of matches
+func (s *PostService) ListPosts(q PostListQuery) (PostPage, error) {
+	f := repository.PostFilter{
+		Types:        q.Types,
+		Format:       q.Format,
+		ClubID:       q.ClubID,
+		Technologies: q.Technologies,
+		Directions:   q.Directions,
+		From:         q.From,
+		To:           q.To,
+		Age:          q.Age,
+		Limit:        q.Limit,
+	}
+	for _, t := range q.Types {
+		if !isPostType(t) {
+			return PostPage{}, errors.New("invalid post type " + string(t))
+		}
+	}
+	if q.Format != nil && *q.Format != model.InPerson && *q.Format != model.Online {
+		return PostPage{}, errors.New("format must be in_person or online")
+	}
+	if q.From != nil && q.To != nil && !q.From.Before(*q.To) {
+		return PostPage{}, errors.New("from must be before to")
+	}
+	if q.EligibleOnly {
+		f.EligibleFor = &q.ViewerID
+	}
+	if q.Upcoming {
+		now := time.Now()
+		f.UpcomingAt = &now
+	}
+	switch q.Sort {
+	case "", string(repository.PostSortCreated):
+		f.Sort = repository.PostSortCreated
+	case string(repository.PostSortStart), string(repository.PostSortPopular):
+		f.Sort = repository.PostSort(q.Sort)
+	default:
+		return PostPage{}, errors.New("sort must be created, start or popular")
+	}
+	if f.Limit <= 0 {
+		f.Limit = defaultPostPageSize
+	}
+	f.Limit = min(f.Limit, maxPostPageSize)
+	if q.Cursor != "" {
+		var c postCursor
+		raw, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+		if err != nil || json.Unmarshal(raw, &c) != nil || c.Sort != string(f.Sort) || c.ID == 0 {
+			return PostPage{}, ErrInvalidCursor
+		}
+		f.After = &repository.PostKey{Time: c.Time, Num: c.Num, ID: c.ID}
+	}
+
+	posts, total, next, err := repository.ListPosts(f)
+	if err != nil {
+		return PostPage{}, err
+	}
+	dtos, err := postDTOs(posts, q.ViewerID)
+	if err != nil {
+		return PostPage{}, err
+	}
+	page := PostPage{Posts: dtos, Total: total}
+	if next != nil {
+		raw, _ := json.Marshal(postCursor{Sort: string(f.Sort), Time: next.Time, Num: next.Num, ID: next.ID})
+		page.NextCursor = base64.RawURLEncoding.EncodeToString(raw)
+	}
+	return page, nil
+}
+
+func isPostType(t model.PostType) bool {
+	switch t {
+	case model.InfoPost, model.Project, model.Internship, model.Educational, model.Activity, model.Vacancy:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mosprom/api/internal/model"
+	"mosprom/api/internal/repository"
+	"strings"
+)
+
+var (
+	ErrRegistrationPending = repository.ErrRegistrationPending
+	ErrRegistrationDecided = repository.ErrRegistrationDecided
+	ErrAlreadyParticipant  = repository.ErrAlreadyParticipant
+)
+
+const (
+	maxRegistrationQuestions = 20
+	maxRegistrationText      = 2000 // motivation, question and answer length in characters
+)
+
+// RegistrationInput is what a user sends when joining an approval post; ignored for open posts
+type RegistrationInput struct {
+	Motivation string                    `json:"motivation"`
+	Answers    []RegistrationAnswerInput `json:"answers"`
+}
+
+type RegistrationAnswerInput struct {
+	QuestionID uint   `json:"question_id"`
+	Answer     string `json:"answer"`
+}
+
+type RegistrationQuestionInput struct {
+	Text     string `json:"text" binding:"required"`
+	Required bool   `json:"required"`
+}
+
+func isRegistrationMode(m model.RegistrationMode) bool {
+	return m == model.RegistrationOpen || m == model.RegistrationApproval
+}
+
+// register files a pending registration after checking the answers against the post's questions
+func (s *PostService) register(userID uint, post model.Post, input RegistrationInput) (model.PostRegistration, error) {
+	motivation := strings.TrimSpace(input.Motivation)
+	if len([]rune(motivation)) > maxRegistrationText {
+		return model.PostRegistration{}, fmt.Errorf("motivation is longer than %d characters", maxRegistrationText)
+	}
+	known := make(map[uint]bool, len(post.Questions))
+	for _, q := range post.Questions {
+		known[q.ID] = true
+	}
+	given := make(map[uint]string, len(input.Answers))
+	for _, a := range input.Answers {
+		if !known[a.QuestionID] {
+			return model.PostRegistration{}, fmt.Errorf("unknown question %d", a.QuestionID)
+		}
+		given[a.QuestionID] = strings.TrimSpace(a.Answer)
+	}
+	answers := make([]model.RegistrationAnswer, 0, len(post.Questions))
+	for _, q := range post.Questions {
+		a := given[q.ID]
+		if a == "" && q.Required {
+			return model.PostRegistration{}, fmt.Errorf("question %d requires an answer", q.ID)
+		}
+		if len([]rune(a)) > maxRegistrationText {
+			return model.PostRegistration{}, fmt.Errorf("answer to question %d is longer than %d characters", q.ID, maxRegistrationText)
+		}
+		if a != "" {
+			answers = append(answers, model.RegistrationAnswer{QuestionID: q.ID, Question: q.Text, Answer: a})
+		}
+	}
+	raw, err := json.Marshal(answers)
+	if err != nil {
+		return model.PostRegistration{}, err
+	}
+	reg := model.PostRegistration{PostID: post.ID, UserID: userID, Motivation: motivation, Answers: model.JSON(raw)}
+	if err := repository.CreatePostRegistration(&reg); err != nil {
+		return model.PostRegistration{}, err
+	}
+	return reg, nil
+}
+
+// MyRegistrations lists the user's registrations, newest first
+func (s *PostService) MyRegistrations(userID uint) ([]model.PostRegistration, error) {
+	return repository.GetUserRegistrations(userID)
+}
+
+// SetRegistrationQuestions replaces the questions asked when registering for the post; host club managers only
+func (s *ClubService) SetRegistrationQuestions(actorID uint, role string, postID uint, input []RegistrationQuestionInput) ([]model.PostRegistrationQuestion, error) {
+	post, err := repository.GetPostByID(postID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(actorID, role, post.ClubID); err != nil {
+		return nil, err
+	}
+	if len(input) > maxRegistrationQuestions {
+		return nil, fmt.Errorf("at most %d questions", maxRegistrationQuestions)
+	}
+	questions := make([]model.PostRegistrationQuestion, 0, len(input))
+	for i, q := range input {
+		text := strings.TrimSpace(q.Text)
+		if text == "" {
+			return nil, errors.New("question text is required")
+		}
+		if len([]rune(text)) > maxRegistrationText {
+			return nil, fmt.Errorf("question is longer than %d characters", maxRegistrationText)
+		}
+		questions = append(questions, model.PostRegistrationQuestion{PostID: postID, Position: i, Text: text, Required: q.Required})
+	}
+	if err := repository.ReplacePostRegistrationQuestions(postID, questions); err != nil {
+		return nil, err
+	}
+	return repository.GetPostRegistrationQuestions(postID)
+}
+
+// PostRegistrations lists the post's registrations, optionally by status; host club managers only
+func (s *ClubService) PostRegistrations(actorID uint, role string, postID uint, status string) ([]model.PostRegistration, error) {
+	post, err := repository.GetPostByID(postID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(actorID, role, post.ClubID); err != nil {
+		return nil, err
+	}
+	st := model.RegistrationStatus(status)
+	switch st {
+	case "", model.RegistrationPending, model.RegistrationApproved, model.RegistrationRejected:
+	default:
+		return nil, errors.New("status must be pending, approved or rejected")
+	}
+	items, err := repository.GetPostRegistrations(postID, st)
+	for i := range items {
+		if items[i].User != nil {
+			items[i].User.Password = ""
+		}
+	}
+	return items, err
+}
+
+// DecideRegistration approves or rejects a pending registration; approving joins the user
+// (or waitlists them when the post is full)
+func (s *ClubService) DecideRegistration(actorID uint, role string, postID, registrationID uint, approve bool, reason string) (model.PostRegistration, error) {
+	post, err := repository.GetPostByID(postID)
+	if err != nil {
+		return model.PostRegistration{}, err
+	}
+	if err := s.authorize(actorID, role, post.ClubID); err != nil {
+		return model.PostRegistration{}, err
+	}
+	return repository.DecidePostRegistration(postID, registrationID, actorID, approve, strings.TrimSpace(reason))
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"mosprom/api/internal/model"
+	"mosprom/api/internal/repository"
+	"time"
+)
+
+// reminderBatchSize limits how many reminders a single poll locks
+const reminderBatchSize = 100
+
+type ReminderService struct {
+	offsets []time.Duration
+}
+
+// NewReminderService creates a scheduler that reminds participants at each offset before a post starts
+func NewReminderService(offsets []time.Duration) *ReminderService {
+	return &ReminderService{offsets: offsets}
+}
+
+// Schedule replaces pending reminders of a post with ones computed from its current StartDate.
+// Offsets whose fire time has already passed are skipped; a post without StartDate gets no reminders.
+func (s *ReminderService) Schedule(post model.Post) error {
+	var reminders []model.PostReminder
+	if post.StartDate != nil {
+		now := time.Now()
+		for _, off := range s.offsets {
+			fireAt := post.StartDate.Add(-off)
+			if !fireAt.After(now) {
+				continue
+			}
+			reminders = append(reminders, model.PostReminder{
+				PostID:        post.ID,
+				OffsetMinutes: int(off / time.Minute),
+				FireAt:        fireAt,
+				Status:        model.ReminderPending,
+			})
+		}
+	}
+	return repository.ReplacePostReminders(post.ID, reminders)
+}
+
+// Run fires due reminders every interval until ctx is cancelled
+func (s *ReminderService) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for {
+			n, err := repository.FireDueReminders(time.Now(), reminderBatchSize, composeReminder)
+			if err != nil {
+				log.Printf("fire reminders: %v", err)
+				break
+			}
+			// a full batch means there may be more due reminders waiting
+			if n < reminderBatchSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func composeReminder(r model.PostReminder, post model.Post) model.Notification {
+	postID := post.ID
+	clubID := post.ClubID
+	return model.Notification{
+		Type:   model.PostReminderNotification,
+		Title:  post.Title,
+		Body:   fmt.Sprintf("%q starts in %s", post.Title, humanizeOffset(time.Duration(r.OffsetMinutes)*time.Minute)),
+		PostID: &postID,
+		ClubID: &clubID,
+	}
+}
+
+func humanizeOffset(d time.Duration) string {
+	switch {
+	case d%(24*time.Hour) == 0:
+		days := int(d / (24 * time.Hour))
+		if days == 1 {
+			return "1 day"
+		}
+		return fmt.Sprintf("%d days", days)
+	case d%time.Hour == 0:
+		hours := int(d / time.Hour)
+		if hours == 1 {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", hours)
+	default:
+		return fmt.Sprintf("%d minutes", int(d/time.Minute))
+	}
+}
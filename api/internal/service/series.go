@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mosprom/api/internal/ical"
+	"mosprom/api/internal/model"
+	"mosprom/api/internal/repository"
+	"time"
+)
+
+const (
+	// ExtendSeriesJob is the background job type that creates upcoming occurrences of recurring posts
+	ExtendSeriesJob = "posts.extend_series"
+	// ExtendSeriesSpec is how often series are extended
+	ExtendSeriesSpec = "17 * * * *"
+
+	seriesHorizon        = 90 * 24 * time.Hour // occurrences exist this far ahead
+	maxSeriesOccurrences = 200                 // per extension, and the largest COUNT
+)
+
+var (
+	ErrSeriesEnded    = repository.ErrSeriesEnded
+	ErrNotSeries      = errors.New("post is not part of a series")
+	ErrSeriesApproval = errors.New("posts requiring approval are joined one occurrence at a time")
+)
+
+// isRecurringType reports whether posts of the type may have a recurrence rule
+func isRecurringType(t model.PostType) bool {
+	return t == model.Activity || t == model.Educational
+}
+
+// seriesExpander expands a series' rule from its MaterializedUntil up to the horizon as seen at now
+func seriesExpander(now time.Time) repository.SeriesExpander {
+	return func(s model.PostSeries) ([]time.Time, time.Time) {
+		rule, err := ical.ParseRecurrence(s.RRule)
+		if err != nil {
+			log.Printf("series %d: %v", s.ID, err)
+			return nil, s.MaterializedUntil
+		}
+		horizon := now.Add(seriesHorizon)
+		if s.StartDate.After(now) {
+			horizon = s.StartDate.Add(seriesHorizon)
+		}
+		start := s.StartDate.In(time.FixedZone("", s.UTCOffset))
+		starts := rule.Between(start, s.MaterializedUntil, horizon, maxSeriesOccurrences)
+		if len(starts) == maxSeriesOccurrences {
+			return starts, starts[len(starts)-1]
+		}
+		return starts, horizon
+	}
+}
+
+// createSeries creates a recurring post and its occurrences up to the horizon, returning the first one
+func (s *PostService) createSeries(input CreatePostInput) (model.Post, error) {
+	if !isRecurringType(input.Type) {
+		return model.Post{}, errors.New("only activity and educational posts can recur")
+	}
+	if input.StartDate == nil {
+		return model.Post{}, errors.New("start_date is required for a recurring post")
+	}
+	rule, err := ical.ParseRecurrence(input.Recurrence)
+	if err != nil {
+		return model.Post{}, err
+	}
+	if rule.Count > maxSeriesOccurrences {
+		return model.Post{}, fmt.Errorf("COUNT must be at most %d", maxSeriesOccurrences)
+	}
+	start := *input.StartDate
+	if len(rule.Between(start, start.Add(-time.Second), start.AddDate(2, 0, 0), 1)) == 0 {
+		return model.Post{}, errors.New("recurrence has no occurrences")
+	}
+	_, offset := start.Zone()
+	series := model.PostSeries{
+		ClubID:            input.ClubID,
+		RRule:             rule.String(),
+		StartDate:         start,
+		UTCOffset:         offset,
+		Title:             input.Title,
+		Description:       input.Description,
+		Type:              input.Type,
+		AgeRestriction:    input.AgeRestriction,
+		Format:            input.Format,
+		Address:           input.Address,
+		Capacity:          input.Capacity,
+		RegistrationMode:  input.RegistrationMode,
+		MaterializedUntil: start.Add(-time.Second),
+	}
+	if input.EndDate != nil {
+		d := int64(input.EndDate.Sub(start) / time.Second)
+		if d < 0 {
+			return model.Post{}, errors.New("end_date must not be before start_date")
+		}
+		series.DurationSeconds = &d
+	}
+	created, err := repository.CreatePostSeries(&series, seriesExpander(time.Now()))
+	if err != nil {
+		return model.Post{}, err
+	}
+	s.scheduleReminders(created)
+	if len(created) == 0 {
+		return model.Post{}, errors.New("recurrence has no occurrences")
+	}
+	return repository.GetPostByID(created[0].ID)
+}
+
+func (s *PostService) scheduleReminders(posts []model.Post) {
+	for _, p := range posts {
+		if err := s.reminders.Schedule(p); err != nil {
+			log.Printf("schedule reminders for post %d: %v", p.ID, err)
+		}
+	}
+}
+
+// ExtendSeries creates the occurrences of active series that fall within the horizon; run by ExtendSeriesJob
+func (s *PostService) ExtendSeries(ctx context.Context, _ struct{}) error {
+	now := time.Now()
+	ids, err := repository.GetSeriesToExtend(now.Add(seriesHorizon))
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		created, err := repository.ExtendPostSeries(id, seriesExpander(now))
+		if err != nil {
+			log.Printf("extend series %d: %v", id, err)
+			continue
+		}
+		s.scheduleReminders(created)
+	}
+	return nil
+}
+
+// seriesOf returns the series of an occurrence
+func seriesOf(postID uint) (model.Post, model.PostSeries, error) {
+	post, err := repository.GetPostByID(postID)
+	if err != nil {
+		return model.Post{}, model.PostSeries{}, err
+	}
+	if post.SeriesID == nil {
+		return model.Post{}, model.PostSeries{}, ErrNotSeries
+	}
+	series, err := repository.GetPostSeries(*post.SeriesID)
+	return post, series, err
+}
+
+// JoinSeries joins the user to every upcoming occurrence of the post's series, including ones created later
+func (s *PostService) JoinSeries(userID, postID uint) (model.Post, error) {
+	_, series, err := seriesOf(postID)
+	if err != nil {
+		return model.Post{}, err
+	}
+	if series.RegistrationMode == model.RegistrationApproval {
+		return model.Post{}, ErrSeriesApproval
+	}
+	if err := repository.JoinPostSeries(userID, series.ID, time.Now()); err != nil {
+		return model.Post{}, err
+	}
+	return repository.GetPostByID(postID)
+}
+
+// LeaveSeries leaves the post's series and its upcoming occurrences
+func (s *PostService) LeaveSeries(userID, postID uint) (model.Post, error) {
+	_, series, err := seriesOf(postID)
+	if err != nil {
+		return model.Post{}, err
+	}
+	if err := repository.LeavePostSeries(userID, series.ID, time.Now()); err != nil {
+		return model.Post{}, err
+	}
+	return repository.GetPostByID(postID)
+}
+
+// Occurrences lists the upcoming occurrences of the post's series
+func (s *PostService) Occurrences(postID uint) ([]model.Post, error) {
+	_, series, err := seriesOf(postID)
+	if err != nil {
+		return nil, err
+	}
+	return repository.GetSeriesOccurrences(series.ID, time.Now())
+}
+
+// CancelSeries stops the post's series on behalf of a manager of its club; upcoming occurrences are
+// deleted and published as cancelled in calendars. Past occurrences are kept.
+func (s *PostService) CancelSeries(actorID uint, role string, postID uint) ([]uint, error) {
+	post, series, err := seriesOf(postID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.clubs.authorize(actorID, role, post.ClubID); err != nil {
+		return nil, err
+	}
+	return repository.EndPostSeries(series.ID, time.Now())
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"mosprom/api/internal/repository"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type SkillsService struct{}
+
+func NewSkillsService() *SkillsService { return &SkillsService{} }
+
+type SkillsGapQuery struct {
+	From      *time.Time
+	To        *time.Time
+	Interval  string // day, week or month
+	Direction string // only this direction
+}
+
+type SkillsTrendPoint struct {
+	Period    time.Time `json:"period"`
+	Demand    int       `json:"demand"`
+	NewSupply int       `json:"new_supply"`
+}
+
+type TechnologyGap struct {
+	Technology  string             `json:"technology"`
+	Demand      int                `json:"demand"`       // demand posts requiring it in the range
+	Supply      int                `json:"supply"`       // users having it
+	DemandShare float64            `json:"demand_share"` // demand / demand posts
+	SupplyShare float64            `json:"supply_share"` // supply / users with any technology
+	Gap         float64            `json:"gap"`          // demand_share - supply_share; positive = shortage
+	Trend       []SkillsTrendPoint `json:"trend"`
+}
+
+type DirectionGap struct {
+	Direction    string          `json:"direction"` // empty for technologies without a direction
+	Technologies []TechnologyGap `json:"technologies"`
+}
+
+type SkillsGapReport struct {
+	From        time.Time      `json:"from"`
+	To          time.Time      `json:"to"`
+	Interval    string         `json:"interval"`
+	DemandPosts int64          `json:"demand_posts"` // vacancy, internship and project posts in the range
+	SupplyUsers int64          `json:"supply_users"` // users with at least one technology
+	Directions  []DirectionGap `json:"directions"`
+}
+
+// SkillsGap compares technologies required by vacancy, internship and project posts with the ones users have,
+// grouped by direction, with per-period trends. Defaults to the last 90 days by month.
+func (s *SkillsService) SkillsGap(q SkillsGapQuery) (SkillsGapReport, error) {
+	to := time.Now()
+	if q.To != nil {
+		to = *q.To
+	}
+	from := to.Add(-defaultAnalyticsRange)
+	if q.From != nil {
+		from = *q.From
+	}
+	if !from.Before(to) {
+		return SkillsGapReport{}, errors.New("from must be before to")
+	}
+	interval := q.Interval
+	if interval == "" {
+		interval = "month"
+	}
+	if interval != "day" && interval != "week" && interval != "month" {
+		return SkillsGapReport{}, errors.New("interval must be day, week or month")
+	}
+
+	rows, err := repository.GetSkillsGap(from, to, q.Direction)
+	if err != nil {
+		return SkillsGapReport{}, err
+	}
+	demandPosts, err := repository.CountDemandPosts(from, to)
+	if err != nil {
+		return SkillsGapReport{}, err
+	}
+	supplyUsers, err := repository.CountUsersWithTechnologies()
+	if err != nil {
+		return SkillsGapReport{}, err
+	}
+	techIDs := make([]uint, 0, len(rows))
+	seen := make(map[uint]bool)
+	for _, r := range rows {
+		if !seen[r.TechnologyID] {
+			seen[r.TechnologyID] = true
+			techIDs = append(techIDs, r.TechnologyID)
+		}
+	}
+	trendRows, err := repository.GetSkillsTrend(from, to, interval, techIDs)
+	if err != nil {
+		return SkillsGapReport{}, err
+	}
+	trends := make(map[uint][]SkillsTrendPoint)
+	for _, t := range trendRows {
+		trends[t.TechnologyID] = append(trends[t.TechnologyID], SkillsTrendPoint{Period: t.Period, Demand: t.Demand, NewSupply: t.NewSupply})
+	}
+
+	report := SkillsGapReport{From: from, To: to, Interval: interval, DemandPosts: demandPosts, SupplyUsers: supplyUsers}
+	for _, r := range rows {
+		n := len(report.Directions)
+		if n == 0 || report.Directions[n-1].Direction != r.Direction {
+			report.Directions = append(report.Directions, DirectionGap{Direction: r.Direction})
+			n++
+		}
+		g := TechnologyGap{
+			Technology:  r.Technology,
+			Demand:      r.Demand,
+			Supply:      r.Supply,
+			DemandShare: ratio(r.Demand, int(demandPosts)),
+			SupplyShare: ratio(r.Supply, int(supplyUsers)),
+			Trend:       trends[r.TechnologyID],
+		}
+		g.Gap = g.DemandShare - g.SupplyShare
+		report.Directions[n-1].Technologies = append(report.Directions[n-1].Technologies, g)
+	}
+	return report, nil
+}
+
+// WriteSkillsGapCSV writes one row per direction and technology; trend periods become
+// "demand <date>" and "new_supply <date>" columns
+func WriteSkillsGapCSV(w io.Writer, report SkillsGapReport) error {
+	periodSet := make(map[time.Time]bool)
+	for _, d := range report.Directions {
+		for _, t := range d.Technologies {
+			for _, p := range t.Trend {
+				periodSet[p.Period] = true
+			}
+		}
+	}
+	periods := make([]time.Time, 0, len(periodSet))
+	for p := range periodSet {
+		periods = append(periods, p)
+	}
+	sort.Slice(periods, func(i, j int) bool { return periods[i].Before(periods[j]) })
+
+	cw := csv.NewWriter(w)
+	header := []string{"direction", "technology", "demand", "supply", "demand_share", "supply_share", "gap"}
+	for _, p := range periods {
+		header = append(header, "demand "+p.Format(time.DateOnly), "new_supply "+p.Format(time.DateOnly))
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, d := range report.Directions {
+		for _, t := range d.Technologies {
+			byPeriod := make(map[time.Time]SkillsTrendPoint, len(t.Trend))
+			for _, p := range t.Trend {
+				byPeriod[p.Period] = p
+			}
+			rec := []string{
+				csvText(d.Direction),
+				csvText(t.Technology),
+				strconv.Itoa(t.Demand),
+				strconv.Itoa(t.Supply),
+				strconv.FormatFloat(t.DemandShare, 'f', 4, 64),
+				strconv.FormatFloat(t.SupplyShare, 'f', 4, 64),
+				strconv.FormatFloat(t.Gap, 'f', 4, 64),
+			}
+			for _, p := range periods {
+				pt := byPeriod[p]
+				rec = append(rec, strconv.Itoa(pt.Demand), strconv.Itoa(pt.NewSupply))
+			}
+			if err := cw.Write(rec); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvText keeps a spreadsheet from evaluating a user-entered name as a formula. Numbers are written
+// by the exporter itself and left as is, so negative gaps stay numeric.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+)
+
+func TestWriteSkillsGapCSVEscapesFormulas(t *testing.T) {
+	names := []struct{ in, want string }{
+		{"Go", "Go"},
+		{"=HYPERLINK(\"http://evil\")", "'=HYPERLINK(\"http://evil\")"},
+		{"+1+1", "'+1+1"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\tcmd", "'\tcmd"},
+		{"\rcmd", "'\rcmd"},
+		{"C++", "C++"},
+		{"", ""},
+	}
+	report := SkillsGapReport{}
+	for _, n := range names {
+		report.Directions = append(report.Directions, DirectionGap{
+			Direction:    n.in,
+			Technologies: []TechnologyGap{{Technology: n.in, Gap: -0.25}},
+		})
+	}
+	var buf bytes.Buffer
+	if err := WriteSkillsGapCSV(&buf, report); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != len(names)+1 {
+		t.Fatalf("got %d rows, want %d", len(rows), len(names)+1)
+	}
+	for i, n := range names {
+		row := rows[i+1]
+		if row[0] != n.want || row[1] != n.want {
+			t.Errorf("name %q written as %q, %q; want %q", n.in, row[0], row[1], n.want)
+		}
+		if row[6] != "-0.2500" {
+			t.Errorf("gap written as %q, want -0.2500", row[6])
+		}
+	}
+}
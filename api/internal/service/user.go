@@ -1,12 +1,19 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"math"
+	"mosprom/api/internal/jobs"
 	"mosprom/api/internal/model"
 	"mosprom/api/internal/repository"
+	"strings"
+	"time"
 )
 
+// RecomputeRatingsJob is the background job type that runs RecomputeAllRatings
+const RecomputeRatingsJob = "users.recompute_ratings"
+
 type UserService struct{}
 
 func NewUserService() *UserService { return &UserService{} }
@@ -21,8 +28,6 @@ type CreateUserInput struct {
 	Password     string
 	Description  string
 	PhotoPath    string // относительный путь к файлу
-	Achievements []string
-	EventsCount  int
 	University   string
 	Technologies []string // имена
 	Directions   []string // имена
@@ -40,11 +45,10 @@ type UpdateUserInput struct {
 	Password     *string
 	Description  *string
 	PhotoPath    *string
-	Achievements *[]string
-	EventsCount  *int
 	University   *string
 	Technologies *[]string
 	Directions   *[]string
+	BirthDate    *string // YYYY-MM-DD, empty clears; only the user may set it
 }
 
 func (s *UserService) CreateUser(input CreateUserInput) (model.User, error) {
@@ -57,11 +61,8 @@ func (s *UserService) CreateUser(input CreateUserInput) (model.User, error) {
 		return model.User{}, err
 	}
 
-	achievements := input.Achievements
-	if achievements == nil {
-		achievements = []string{}
-	}
-	achievements = append(achievements, "🎉 Joined the community! First step to becoming a tech superstar!")
+	// events_count and achievements are earned by checking in to posts, never set by the client
+	achievements := []string{"🎉 Joined the community! First step to becoming a tech superstar!"}
 
 	user := model.User{
 		TelegramName: input.TelegramName,
@@ -70,7 +71,6 @@ func (s *UserService) CreateUser(input CreateUserInput) (model.User, error) {
 		Description:  input.Description,
 		Photo:        input.PhotoPath,
 		Achievements: achievements,
-		EventsCount:  input.EventsCount,
 		University:   input.University,
 		Technologies: techs,
 		Directions:   dirs,
@@ -101,15 +101,16 @@ func (s *UserService) UpdateUser(input UpdateUserInput) (model.User, error) {
 	if input.PhotoPath != nil {
 		user.Photo = *input.PhotoPath
 	}
-	if input.Achievements != nil {
-		user.Achievements = *input.Achievements
-	}
-	if input.EventsCount != nil {
-		user.EventsCount = *input.EventsCount
-	}
 	if input.University != nil {
 		user.University = *input.University
 	}
+	if input.BirthDate != nil {
+		birth, err := parseBirthDate(*input.BirthDate)
+		if err != nil {
+			return model.User{}, err
+		}
+		user.BirthDate = birth
+	}
 
 	if input.Technologies != nil || input.Directions != nil {
 		var techs []model.Technology
@@ -179,6 +180,11 @@ func (s *UserService) AddAchievementToUser(userID uint, achievement string) erro
 	return repository.AddAchievementToUser(userID, achievement)
 }
 
+// EnqueueRatingRecompute schedules RecomputeAllRatings on the background job queue
+func (s *UserService) EnqueueRatingRecompute() (model.Job, error) {
+	return jobs.Enqueue(RecomputeRatingsJob, struct{}{})
+}
+
 // RecomputeAllRatings recalculates and updates rating for all users.
 // Rating formula:
 // R = (0.4 * Anorm + 0.6 * Enorm) * 10
@@ -186,7 +192,7 @@ func (s *UserService) AddAchievementToUser(userID uint, achievement string) erro
 //
 //	Enorm = achievements / (events ^ 0.7)
 //
-// posts (events) are rows in post_participants, achievements are strings in users.achievements
+// events are verified attendances (post_attendances rows), achievements are strings in users.achievements
 func (s *UserService) RecomputeAllRatings() error {
 	// Get participation counts for all users and max
 	eventsMap, maxEvents, err := repository.GetParticipationCounts()
@@ -233,3 +239,19 @@ func (s *UserService) RecomputeAllRatings() error {
 	}
 	return nil
 }
+
+// parseBirthDate parses a YYYY-MM-DD birth date; an empty string clears it
+func parseBirthDate(v string) (*time.Time, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil, nil
+	}
+	birth, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return nil, errors.New("birth_date must be YYYY-MM-DD")
+	}
+	if now := time.Now(); !birth.Before(now) || birth.Before(now.AddDate(-120, 0, 0)) {
+		return nil, errors.New("birth_date is out of range")
+	}
+	return &birth, nil
+}
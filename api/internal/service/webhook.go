@@ -0,0 +1,452 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mosprom/api/internal/events"
+	"mosprom/api/internal/jobs"
+	"mosprom/api/internal/model"
+	"mosprom/api/internal/repository"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DeliverWebhookJob is the background job type that sends one webhook delivery
+const DeliverWebhookJob = "webhooks.deliver"
+
+const (
+	// Request headers of every webhook call
+	WebhookEventHeader     = "X-SkillBridge-Event"
+	WebhookDeliveryHeader  = "X-SkillBridge-Delivery"
+	WebhookTimestampHeader = "X-SkillBridge-Timestamp"
+	// WebhookSignatureHeader carries "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body))
+	WebhookSignatureHeader = "X-SkillBridge-Signature"
+
+	webhookTimeout         = 10 * time.Second
+	webhookDialTimeout     = 5 * time.Second
+	webhookDeliveriesLimit = 100
+)
+
+var (
+	ErrInvalidWebhook = errors.New("invalid webhook")
+	// ErrWebhookAddress rejects webhook URLs reaching the server's own network
+	ErrWebhookAddress = fmt.Errorf("%w: url must not point to a loopback, private or link-local address", ErrInvalidWebhook)
+)
+
+// Carrier-grade NAT range, not covered by net.IP.IsPrivate
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+type WebhookService struct {
+	clubs  *ClubService
+	client *http.Client
+	// allowIP decides which addresses webhooks may connect to; tests allow loopback receivers
+	allowIP func(net.IP) bool
+}
+
+func NewWebhookService() *WebhookService {
+	s := &WebhookService{clubs: NewClubService(), allowIP: isPublicIP}
+	dialer := &net.Dialer{
+		Timeout: webhookDialTimeout,
+		// checked on the resolved address of every connection, redirects included, since DNS
+		// may point elsewhere than when the webhook was created
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !s.allowIP(ip) {
+				return ErrWebhookAddress
+			}
+			return nil
+		},
+	}
+	s.client = &http.Client{
+		Timeout: webhookTimeout,
+		Transport: &http.Transport{
+			Proxy:               nil, // a proxy would connect on our behalf and bypass the address check
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: webhookDialTimeout,
+			MaxIdleConnsPerHost: 2,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+	return s
+}
+
+// isPublicIP reports whether ip is a globally routable unicast address
+func isPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() ||
+		sharedAddressSpace.Contains(ip))
+}
+
+// checkWebhookHost rejects URLs whose host is, or resolves to, an address webhooks may not reach
+func (s *WebhookService) checkWebhookHost(ctx context.Context, u *url.URL) error {
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrWebhookAddress
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if !s.allowIP(ip) {
+			return ErrWebhookAddress
+		}
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, webhookDialTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("%w: host %q does not resolve", ErrInvalidWebhook, host)
+	}
+	for _, a := range addrs {
+		if !s.allowIP(a.IP) {
+			return ErrWebhookAddress
+		}
+	}
+	return nil
+}
+
+type CreateWebhookInput struct {
+	URL        string   `json:"url" binding:"required"`
+	EventTypes []string `json:"event_types"`
+}
+
+// CreatedWebhook is returned once on creation; Secret is not exposed afterwards
+type CreatedWebhook struct {
+	model.Webhook
+	Secret string `json:"secret"`
+}
+
+// WebhookPayload is the JSON body POSTed to webhook URLs
+type WebhookPayload struct {
+	EventID    uint               `json:"event_id"`
+	Event      model.EventType    `json:"event"`
+	OccurredAt time.Time          `json:"occurred_at"`
+	ClubID     uint               `json:"club_id"`
+	Data       model.EventPayload `json:"data"`
+	Post       *WebhookPost       `json:"post,omitempty"`
+	User       *WebhookUser       `json:"user,omitempty"`
+}
+
+type WebhookPost struct {
+	ID    uint           `json:"id"`
+	Title string         `json:"title"`
+	Type  model.PostType `json:"type"`
+}
+
+type WebhookUser struct {
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	TelegramName string `json:"telegram_name"`
+	University   string `json:"university"`
+}
+
+func (s *WebhookService) authorize(actorID uint, role string, clubID uint) error {
+	return s.clubs.authorize(actorID, role, clubID)
+}
+
+func (s *WebhookService) Create(actorID uint, role string, clubID uint, input CreateWebhookInput) (CreatedWebhook, error) {
+	if err := s.authorize(actorID, role, clubID); err != nil {
+		return CreatedWebhook{}, err
+	}
+	archived, err := repository.IsClubArchived(clubID)
+	if err != nil {
+		return CreatedWebhook{}, err
+	}
+	if archived {
+		return CreatedWebhook{}, ErrClubArchived
+	}
+	u, err := url.Parse(strings.TrimSpace(input.URL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return CreatedWebhook{}, fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidWebhook)
+	}
+	if u.User != nil {
+		return CreatedWebhook{}, fmt.Errorf("%w: url must not contain credentials", ErrInvalidWebhook)
+	}
+	if err := s.checkWebhookHost(context.Background(), u); err != nil {
+		return CreatedWebhook{}, err
+	}
+	for _, t := range input.EventTypes {
+		if !isKnownEventType(model.EventType(t)) {
+			return CreatedWebhook{}, fmt.Errorf("%w: unknown event type %q", ErrInvalidWebhook, t)
+		}
+	}
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return CreatedWebhook{}, err
+	}
+	w := model.Webhook{
+		ClubID:      clubID,
+		URL:         u.String(),
+		Secret:      secret,
+		EventTypes:  input.EventTypes,
+		Active:      true,
+		CreatedByID: actorID,
+	}
+	if err := repository.CreateWebhook(&w); err != nil {
+		return CreatedWebhook{}, err
+	}
+	return CreatedWebhook{Webhook: w, Secret: secret}, nil
+}
+
+func (s *WebhookService) List(actorID uint, role string, clubID uint) ([]model.Webhook, error) {
+	if err := s.authorize(actorID, role, clubID); err != nil {
+		return nil, err
+	}
+	return repository.GetClubWebhooks(clubID)
+}
+
+func (s *WebhookService) Delete(actorID uint, role string, clubID, webhookID uint) error {
+	if err := s.authorize(actorID, role, clubID); err != nil {
+		return err
+	}
+	if _, err := repository.GetClubWebhook(clubID, webhookID); err != nil {
+		return err
+	}
+	return repository.DeleteWebhook(webhookID)
+}
+
+func (s *WebhookService) Deliveries(actorID uint, role string, clubID, webhookID uint) ([]model.WebhookDelivery, error) {
+	if err := s.authorize(actorID, role, clubID); err != nil {
+		return nil, err
+	}
+	if _, err := repository.GetClubWebhook(clubID, webhookID); err != nil {
+		return nil, err
+	}
+	return repository.GetWebhookDeliveries(webhookID, webhookDeliveriesLimit)
+}
+
+// Replay sends the payload of an earlier delivery again as a new delivery
+func (s *WebhookService) Replay(actorID uint, role string, clubID, webhookID, deliveryID uint) (model.WebhookDelivery, error) {
+	if err := s.authorize(actorID, role, clubID); err != nil {
+		return model.WebhookDelivery{}, err
+	}
+	if _, err := repository.GetClubWebhook(clubID, webhookID); err != nil {
+		return model.WebhookDelivery{}, err
+	}
+	orig, err := repository.GetWebhookDeliveryByID(deliveryID)
+	if err != nil {
+		return model.WebhookDelivery{}, err
+	}
+	if orig.WebhookID != webhookID {
+		return model.WebhookDelivery{}, fmt.Errorf("%w: delivery %d does not belong to webhook %d", ErrInvalidWebhook, deliveryID, webhookID)
+	}
+	origID := orig.ID
+	d := model.WebhookDelivery{
+		WebhookID:  webhookID,
+		EventID:    orig.EventID,
+		EventType:  orig.EventType,
+		Payload:    orig.Payload,
+		Status:     model.DeliveryPending,
+		ReplayOfID: &origID,
+	}
+	if _, err := repository.CreateWebhookDelivery(&d, newDeliverWebhookJob); err != nil {
+		return model.WebhookDelivery{}, err
+	}
+	return d, nil
+}
+
+// OnEvent fans a domain event out to the club's active webhooks subscribed to its type
+func (s *WebhookService) OnEvent(ctx context.Context, e events.Event) error {
+	if e.ClubID == 0 {
+		return nil
+	}
+	hooks, err := repository.GetActiveWebhooksByClubID(e.ClubID)
+	if err != nil {
+		return err
+	}
+	var payload *WebhookPayload
+	for _, w := range hooks {
+		if !w.Accepts(e.Type) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = buildWebhookPayload(e); err != nil {
+				return err
+			}
+		}
+		raw, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		d := model.WebhookDelivery{
+			WebhookID: w.ID,
+			EventID:   e.ID,
+			EventType: e.Type,
+			Payload:   raw,
+			Status:    model.DeliveryPending,
+		}
+		if _, err := repository.CreateWebhookDelivery(&d, newDeliverWebhookJob); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type deliverWebhookPayload struct {
+	DeliveryID uint `json:"delivery_id"`
+}
+
+func newDeliverWebhookJob(deliveryID uint) (model.Job, error) {
+	raw, err := json.Marshal(deliverWebhookPayload{DeliveryID: deliveryID})
+	if err != nil {
+		return model.Job{}, err
+	}
+	return model.Job{
+		Type:        DeliverWebhookJob,
+		Payload:     raw,
+		Status:      model.JobQueued,
+		RunAt:       time.Now(),
+		MaxAttempts: jobs.DefaultMaxAttempts,
+	}, nil
+}
+
+// DeliverJob is the job handler for DeliverWebhookJob. A non-2xx response fails the job so the queue
+// retries it with backoff; the delivery is marked failed once the job runs out of attempts.
+func (s *WebhookService) DeliverJob(ctx context.Context, job model.Job) error {
+	var p deliverWebhookPayload
+	if err := json.Unmarshal([]byte(job.Payload), &p); err != nil {
+		return err
+	}
+	d, err := repository.GetWebhookDeliveryByID(p.DeliveryID)
+	if err != nil {
+		return err
+	}
+	if d.Status == model.DeliverySucceeded {
+		return nil
+	}
+	w, err := repository.GetWebhookByID(d.WebhookID)
+	if err != nil {
+		return err
+	}
+	if !w.Active {
+		d.Status = model.DeliveryFailed
+		d.LastError = "webhook is disabled"
+		return repository.RecordWebhookAttempt(&d)
+	}
+
+	sendErr := s.send(ctx, w, &d)
+	if sendErr == nil {
+		now := time.Now()
+		d.Status = model.DeliverySucceeded
+		d.LastError = ""
+		d.DeliveredAt = &now
+	} else {
+		d.LastError = sendErr.Error()
+		d.Status = model.DeliveryRetrying
+		if job.Attempts >= job.MaxAttempts {
+			d.Status = model.DeliveryFailed
+		}
+	}
+	if err := repository.RecordWebhookAttempt(&d); err != nil {
+		return err
+	}
+	return sendErr
+}
+
+// send POSTs the signed delivery payload and stores the response status on d. The response body is
+// discarded: the delivery log is visible to club managers and must not relay what the receiver returns.
+func (s *WebhookService) send(ctx context.Context, w model.Webhook, d *model.WebhookDelivery) error {
+	body := []byte(d.Payload)
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "SkillBridge-Webhooks/1.0")
+	req.Header.Set(WebhookEventHeader, string(d.EventType))
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(uint64(d.ID), 10))
+	req.Header.Set(WebhookTimestampHeader, ts)
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(w.Secret, ts, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // lets the connection be reused
+	d.ResponseStatus = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SignWebhook computes the signature header value receivers should compare against
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+func buildWebhookPayload(e events.Event) (*WebhookPayload, error) {
+	p := &WebhookPayload{
+		EventID:    e.ID,
+		Event:      e.Type,
+		OccurredAt: e.OccurredAt,
+		ClubID:     e.ClubID,
+		Data:       e.EventPayload,
+	}
+	// rows deleted since the event was recorded are skipped; their ids are still in Data
+	if e.PostID != 0 {
+		post, err := repository.GetPostByID(e.PostID)
+		switch {
+		case err == nil:
+			p.Post = &WebhookPost{ID: post.ID, Title: post.Title, Type: post.Type}
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, err
+		}
+	}
+	if e.UserID != 0 {
+		user, err := repository.GetUserByID(e.UserID)
+		switch {
+		case err == nil:
+			p.User = &WebhookUser{ID: user.ID, Name: user.Name, TelegramName: user.TelegramName, University: user.University}
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+func isKnownEventType(t model.EventType) bool {
+	switch t {
+	case model.EventPostCreated, model.EventPostUpdated, model.EventPostDeleted, model.EventPostLiked,
+		model.EventPostUnliked, model.EventUserJoinedPost, model.EventClubCreated, model.EventClubUpdated,
+		model.EventClubArchived, model.EventClubUnarchived, model.EventClubDeleted, model.EventUserSubscribedToClub,
+		model.EventClubUnsubscribed, model.EventClubMemberKicked, model.EventClubMemberBanned, model.EventClubJoinRequested,
+		model.EventClubJoinRejected, model.EventInvitationCreated, model.EventInvitationAccepted, model.EventInvitationDeclined,
+		model.EventUserLeftPost, model.EventUserWaitlisted, model.EventWaitlistPromoted,
+		model.EventRegistrationCreated, model.EventRegistrationApproved, model.EventRegistrationRejected,
+		model.EventUserCheckedIn:
+		return true
+	default:
+		return false
+	}
+}
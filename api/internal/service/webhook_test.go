@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mosprom/api/internal/db"
+	"mosprom/api/internal/dbtest"
+	"mosprom/api/internal/jobs"
+	"mosprom/api/internal/model"
+	"mosprom/api/internal/repository"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// webhookRequest is one call received by a test receiver
+type webhookRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// webhookReceiver is an httptest server answering with the given statuses in turn, then 200
+type webhookReceiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	requests []webhookRequest
+}
+
+func newWebhookReceiver(t *testing.T, statuses ...int) *webhookReceiver {
+	t.Helper()
+	rcv := &webhookReceiver{statuses: statuses}
+	rcv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rcv.mu.Lock()
+		rcv.requests = append(rcv.requests, webhookRequest{header: r.Header.Clone(), body: body})
+		status := http.StatusOK
+		if len(rcv.statuses) > 0 {
+			status, rcv.statuses = rcv.statuses[0], rcv.statuses[1:]
+		}
+		rcv.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(rcv.Close)
+	return rcv
+}
+
+func (rcv *webhookReceiver) received() []webhookRequest {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	return append([]webhookRequest(nil), rcv.requests...)
+}
+
+// newTestWebhookService lets the service reach loopback receivers
+func newTestWebhookService() *WebhookService {
+	s := NewWebhookService()
+	s.allowIP = func(net.IP) bool { return true }
+	return s
+}
+
+func TestWebhookSignature(t *testing.T) {
+	rcv := newWebhookReceiver(t)
+	s := newTestWebhookService()
+	payload := []byte(`{"type":"post.created","data":{"post_id":1}}`)
+	d := model.WebhookDelivery{ID: 42, EventType: model.EventPostCreated, Payload: model.JSON(payload)}
+
+	if err := s.send(context.Background(), model.Webhook{URL: rcv.URL, Secret: "topsecret"}, &d); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	reqs := rcv.received()
+	if len(reqs) != 1 {
+		t.Fatalf("got %d requests, want 1", len(reqs))
+	}
+	req := reqs[0]
+	if string(req.body) != string(payload) {
+		t.Errorf("body = %s, want %s", req.body, payload)
+	}
+	ts := req.header.Get(WebhookTimestampHeader)
+	mac := hmac.New(sha256.New, []byte("topsecret"))
+	mac.Write([]byte(ts + "."))
+	mac.Write(req.body)
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); req.header.Get(WebhookSignatureHeader) != want {
+		t.Errorf("signature = %q, want %q", req.header.Get(WebhookSignatureHeader), want)
+	}
+	if got := req.header.Get(WebhookDeliveryHeader); got != "42" {
+		t.Errorf("delivery header = %q, want 42", got)
+	}
+	if got := req.header.Get(WebhookEventHeader); got != string(model.EventPostCreated) {
+		t.Errorf("event header = %q, want %s", got, model.EventPostCreated)
+	}
+	if d.ResponseStatus != http.StatusOK {
+		t.Errorf("response status = %d, want 200", d.ResponseStatus)
+	}
+}
+
+func TestWebhookInternalAddresses(t *testing.T) {
+	s := NewWebhookService()
+	for _, raw := range []string{
+		"http://localhost/hook",
+		"http://api.localhost/hook",
+		"http://127.0.0.1:8080/hook",
+		"http://10.1.2.3/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://100.64.0.1/hook",
+		"http://[::1]/hook",
+		"http://0.0.0.0/hook",
+	} {
+		u, _ := url.Parse(raw)
+		if err := s.checkWebhookHost(context.Background(), u); !errors.Is(err, ErrWebhookAddress) {
+			t.Errorf("checkWebhookHost(%s) = %v, want ErrWebhookAddress", raw, err)
+		}
+	}
+
+	// the dialer refuses internal addresses even when creation-time checks were passed
+	rcv := newWebhookReceiver(t)
+	d := model.WebhookDelivery{ID: 1, EventType: model.EventPostCreated, Payload: model.JSON(`{}`)}
+	if err := s.send(context.Background(), model.Webhook{URL: rcv.URL, Secret: "x"}, &d); !errors.Is(err, ErrWebhookAddress) {
+		t.Errorf("send to loopback = %v, want ErrWebhookAddress", err)
+	}
+	if n := len(rcv.received()); n != 0 {
+		t.Errorf("receiver got %d requests, want 0", n)
+	}
+}
+
+// seedWebhook creates a club with a webhook to hookURL and returns the club admin and the webhook
+func seedWebhook(t *testing.T, hookURL string) (model.User, model.Webhook) {
+	t.Helper()
+	dbtest.Open(t)
+	owner := model.User{TelegramName: "webhook_owner", Name: "Owner"}
+	if err := db.DB.Create(&owner).Error; err != nil {
+		t.Fatal(err)
+	}
+	club := model.Club{Name: "webhook club", CreatorID: owner.ID}
+	if err := db.DB.Create(&club).Error; err != nil {
+		t.Fatal(err)
+	}
+	w := model.Webhook{ClubID: club.ID, URL: hookURL, Secret: "topsecret", Active: true, CreatedByID: owner.ID}
+	if err := repository.CreateWebhook(&w); err != nil {
+		t.Fatal(err)
+	}
+	return owner, w
+}
+
+// startRunner processes webhook jobs in the background until the test ends
+func startRunner(t *testing.T, s *WebhookService) {
+	t.Helper()
+	r := jobs.NewRunner(10*time.Millisecond, 0)
+	r.Register(DeliverWebhookJob, s.DeliverJob)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = r.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func deliveryJob(t *testing.T, deliveryID uint) model.Job {
+	t.Helper()
+	var job model.Job
+	if err := db.DB.Where("type = ? AND (payload->>'delivery_id')::bigint = ?", DeliverWebhookJob, deliveryID).First(&job).Error; err != nil {
+		t.Fatal(err)
+	}
+	return job
+}
+
+func deliveryByID(t *testing.T, id uint) model.WebhookDelivery {
+	t.Helper()
+	d, err := repository.GetWebhookDeliveryByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestWebhookRetriesServerErrors(t *testing.T) {
+	rcv := newWebhookReceiver(t, http.StatusInternalServerError)
+	_, w := seedWebhook(t, rcv.URL)
+	s := newTestWebhookService()
+
+	d := model.WebhookDelivery{WebhookID: w.ID, EventID: 1, EventType: model.EventPostCreated, Payload: model.JSON(`{"post_id":1}`), Status: model.DeliveryPending}
+	if _, err := repository.CreateWebhookDelivery(&d, newDeliverWebhookJob); err != nil {
+		t.Fatal(err)
+	}
+	startRunner(t, s)
+
+	var job model.Job
+	waitFor(t, "the failed attempt", func() bool {
+		job = deliveryJob(t, d.ID)
+		return job.Status == model.JobQueued && job.Attempts == 1
+	})
+	got := deliveryByID(t, d.ID)
+	if got.Status != model.DeliveryRetrying || got.Attempts != 1 || got.ResponseStatus != http.StatusInternalServerError {
+		t.Fatalf("after 500: status %s, attempts %d, response %d; want retrying, 1, 500", got.Status, got.Attempts, got.ResponseStatus)
+	}
+	if delay := time.Until(job.RunAt); delay < jobs.Backoff(1)-5*time.Second || delay > jobs.Backoff(1) {
+		t.Fatalf("retry in %s, want about %s", delay, jobs.Backoff(1))
+	}
+
+	// let the backoff elapse
+	if err := db.DB.Model(&model.Job{}).Where("id = ?", job.ID).Update("run_at", time.Now()).Error; err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "the retry", func() bool { return deliveryByID(t, d.ID).Status == model.DeliverySucceeded })
+	got = deliveryByID(t, d.ID)
+	if got.Attempts != 2 || got.ResponseStatus != http.StatusOK || got.DeliveredAt == nil || got.LastError != "" {
+		t.Errorf("after retry: attempts %d, response %d, delivered_at %v, last_error %q",
+			got.Attempts, got.ResponseStatus, got.DeliveredAt, got.LastError)
+	}
+	reqs := rcv.received()
+	if len(reqs) != 2 {
+		t.Fatalf("receiver got %d requests, want 2", len(reqs))
+	}
+	if string(reqs[0].body) != string(reqs[1].body) {
+		t.Errorf("retry body %s differs from first attempt %s", reqs[1].body, reqs[0].body)
+	}
+}
+
+func TestWebhookReplay(t *testing.T) {
+	rcv := newWebhookReceiver(t)
+	owner, w := seedWebhook(t, rcv.URL)
+	s := newTestWebhookService()
+
+	orig := model.WebhookDelivery{WebhookID: w.ID, EventID: 7, EventType: model.EventPostCreated, Payload: model.JSON(`{"post_id":7}`), Status: model.DeliveryPending}
+	if _, err := repository.CreateWebhookDelivery(&orig, newDeliverWebhookJob); err != nil {
+		t.Fatal(err)
+	}
+	startRunner(t, s)
+	waitFor(t, "the delivery", func() bool { return deliveryByID(t, orig.ID).Status == model.DeliverySucceeded })
+
+	replay, err := s.Replay(owner.ID, model.RoleUser, w.ClubID, w.ID, orig.ID)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	waitFor(t, "the replay", func() bool { return deliveryByID(t, replay.ID).Status == model.DeliverySucceeded })
+
+	items, err := s.Deliveries(owner.ID, model.RoleUser, w.ClubID, w.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].ID != replay.ID || items[1].ID != orig.ID {
+		t.Fatalf("delivery log = %+v, want the replay then the original", items)
+	}
+	if items[0].ReplayOfID == nil || *items[0].ReplayOfID != orig.ID || items[0].EventID != orig.EventID {
+		t.Errorf("replay row: replay_of_id %v, event_id %d; want %d, %d", items[0].ReplayOfID, items[0].EventID, orig.ID, orig.EventID)
+	}
+	for _, d := range items {
+		if d.Attempts != 1 || d.ResponseStatus != http.StatusOK {
+			t.Errorf("delivery %d: attempts %d, response %d; want 1, 200", d.ID, d.Attempts, d.ResponseStatus)
+		}
+	}
+
+	reqs := rcv.received()
+	if len(reqs) != 2 {
+		t.Fatalf("receiver got %d requests, want 2", len(reqs))
+	}
+	if string(reqs[1].body) != string(reqs[0].body) {
+		t.Errorf("replayed body %s, want %s", reqs[1].body, reqs[0].body)
+	}
+	if reqs[0].header.Get(WebhookDeliveryHeader) == reqs[1].header.Get(WebhookDeliveryHeader) {
+		t.Errorf("replay reused delivery id %s", reqs[1].header.Get(WebhookDeliveryHeader))
+	}
+}
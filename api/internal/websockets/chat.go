@@ -3,7 +3,9 @@ package websockets
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
+	"mosprom/api/internal/events"
 	"mosprom/api/internal/service"
 	"net/http"
 	"strconv"
@@ -30,6 +32,8 @@ const (
 
 var (
 	newline = []byte{'\n'}
+
+	errBanned = errors.New("user is banned from the chat")
 )
 
 // Run our websocket server, accepting various requests
@@ -64,6 +68,7 @@ type Session struct {
 	mutex     sync.Mutex
 	history   []Message
 	title     string
+	chatID    string
 }
 
 type ClientInfo struct {
@@ -78,15 +83,23 @@ var clubService = service.NewClubService()
 type Client struct {
 	conn *websocket.Conn
 	send chan []byte
+	// userID is the identity of the connection, taken from the JWT; the userId of frames is ignored
+	userID string
 }
 
-func newClient(conn *websocket.Conn) *Client {
+func newClient(conn *websocket.Conn, userID string) *Client {
 	return &Client{
-		conn: conn,
-		send: make(chan []byte, 256),
+		conn:   conn,
+		send:   make(chan []byte, 256),
+		userID: userID,
 	}
 }
 
+func (client *Client) writeError(text string) {
+	raw, _ := json.Marshal(Message{Type: "error", Text: text})
+	client.conn.WriteMessage(websocket.TextMessage, raw)
+}
+
 func (client *Client) readPump(chatSession *Session) {
 	defer func() {
 		chatSession.mutex.Lock()
@@ -99,6 +112,11 @@ func (client *Client) readPump(chatSession *Session) {
 	client.conn.SetReadLimit(maxMessageSize)
 	client.conn.SetReadDeadline(time.Now().Add(pongWait))
 	client.conn.SetPongHandler(func(string) error {
+		// bans issued on another instance reach idle connections here at the latest
+		if clubService.IsBannedFromChat(chatSession.chatID, client.userID) {
+			client.writeError("You are banned from this club")
+			return errBanned
+		}
 		client.conn.SetReadDeadline(time.Now().Add(pongWait))
 		return nil
 	})
@@ -122,24 +140,31 @@ func (client *Client) readPump(chatSession *Session) {
 		}
 
 		if message.Type == "join" {
-			// Validate that the user exists in the database
-			if userID, err := strconv.Atoi(message.UserID); err == nil {
-				if _, err := userService.GetUserByID(uint(userID)); err != nil {
-					// User doesn't exist, close connection
-					log.Printf("User with ID %s does not exist, closing connection", message.UserID)
-					client.conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"error","text":"User does not exist"}`))
-					break
-				}
-			} else {
-				// Invalid user ID format, close connection
-				log.Printf("Invalid user ID format: %s, closing connection", message.UserID)
-				client.conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"error","text":"Invalid user ID format"}`))
+			if message.UserID != "" && message.UserID != client.userID {
+				// The identity of a connection is the token's user
+				log.Printf("Join as %s on a connection of user %s, closing connection", message.UserID, client.userID)
+				client.writeError("userId does not match the token")
+				break
+			}
+			// Validate that the user still exists in the database
+			if userID, err := strconv.Atoi(client.userID); err != nil {
+				break
+			} else if _, err := userService.GetUserByID(uint(userID)); err != nil {
+				log.Printf("User with ID %s does not exist, closing connection", client.userID)
+				client.writeError("User does not exist")
+				break
+			}
+			message.UserID = client.userID
+
+			if clubService.IsBannedFromChat(chatSession.chatID, client.userID) {
+				log.Printf("User %s is banned from chat %s, closing connection", client.userID, chatSession.chatID)
+				client.writeError("You are banned from this club")
 				break
 			}
 
 			chatSession.mutex.Lock()
 			chatSession.clients[client.conn] = ClientInfo{
-				UserID: message.UserID,
+				UserID: client.userID,
 			}
 			chatSession.mutex.Unlock()
 
@@ -147,6 +172,29 @@ func (client *Client) readPump(chatSession *Session) {
 			continue
 		}
 
+		if message.Type == "chat" {
+			chatSession.mutex.Lock()
+			info := chatSession.clients[client.conn]
+			chatSession.mutex.Unlock()
+			if info.UserID == "" {
+				client.writeError("Join the chat before sending messages")
+				continue
+			}
+			// Messages are always sent as the joined user, whatever userId the frame carries
+			message.UserID = info.UserID
+			if clubService.IsBannedFromChat(chatSession.chatID, info.UserID) {
+				// Bans issued while connected take effect on the next message
+				client.writeError("You are banned from this club")
+				break
+			}
+		}
+
+		if message.Type == "chat" && clubService.IsChatFrozen(chatSession.chatID) {
+			// Archived clubs keep their history but accept no new messages
+			client.conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"error","text":"Chat is frozen"}`))
+			continue
+		}
+
 		chatSession.broadcast <- message
 	}
 }
@@ -192,8 +240,8 @@ func (client *Client) writePump() {
 	}
 }
 
-// ServeWs handles websocket requests from clients requests.
-func ServeWs(w http.ResponseWriter, r *http.Request) {
+// ServeWs handles websocket requests from clients requests; userID is the user of the request's JWT
+func ServeWs(w http.ResponseWriter, r *http.Request, userID string) {
 	chatIDStr := r.URL.Query().Get("chat_id")
 	if chatIDStr == "" {
 		log.Println("chat_id is required")
@@ -224,6 +272,7 @@ func ServeWs(w http.ResponseWriter, r *http.Request) {
 			broadcast: make(chan Message, 100),
 			history:   []Message{},
 			title:     title,
+			chatID:    chatIDStr,
 		}
 		chatSessions[chatID] = chatSession
 
@@ -258,7 +307,7 @@ func ServeWs(w http.ResponseWriter, r *http.Request) {
 	chatSession.clients[conn] = ClientInfo{}
 	chatSession.mutex.Unlock()
 
-	client := newClient(conn)
+	client := newClient(conn, userID)
 
 	go client.writePump()
 	go client.readPump(chatSession)
@@ -377,3 +426,44 @@ func (chatSsn *Session) HandleMessages() {
 		chatSsn.mutex.Unlock()
 	}
 }
+
+// DisconnectUser closes the user's connections to the chat
+func DisconnectUser(chatID, userID string) {
+	chatSessionsMutex.Lock()
+	sessions := make([]*Session, 0, 1)
+	for _, ssn := range chatSessions {
+		if ssn.chatID == chatID {
+			sessions = append(sessions, ssn)
+		}
+	}
+	chatSessionsMutex.Unlock()
+
+	raw, _ := json.Marshal(Message{Type: "error", Text: "You are banned from this club"})
+	for _, ssn := range sessions {
+		ssn.mutex.Lock()
+		for conn, info := range ssn.clients {
+			if info.UserID == userID {
+				conn.SetWriteDeadline(time.Now().Add(writeWait))
+				conn.WriteMessage(websocket.TextMessage, raw)
+				conn.Close()
+				delete(ssn.clients, conn)
+			}
+		}
+		ssn.mutex.Unlock()
+	}
+}
+
+// OnMemberBanned disconnects a banned user from the club chat; subscribed to club.member_banned.
+// It only reaches connections of the instance dispatching the event: connections on other instances
+// are closed by the ban check on their next message or pong, within pongWait.
+func OnMemberBanned(ctx context.Context, e events.Event) error {
+	if e.ClubID == 0 || e.UserID == 0 {
+		return nil
+	}
+	club, err := clubService.GetByID(e.ClubID)
+	if err != nil || club.ChatID == "" {
+		return nil
+	}
+	DisconnectUser(club.ChatID, strconv.FormatUint(uint64(e.UserID), 10))
+	return nil
+}